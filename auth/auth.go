@@ -3,14 +3,17 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/ithena-one/Ithena/packages/cli/httpclient"
 	"github.com/zalando/go-keyring"
 )
 
@@ -40,12 +43,38 @@ type TokenErrorResponse struct {
 const keyringServiceName = "ithena-cli"
 const keyringTokenKey = "authToken"
 
-// TODO: Make backendBaseUrl configurable if needed by auth
-const backendBaseUrl = "https://ithena.one" // Production backend URL
+// --- Backend Config ---
+const defaultBackendBaseUrl = "https://ithena.one" // Production backend URL
+const backendUrlEnv = "ITHENA_BACKEND_URL"         // Overrides the backend for self-hosted/staging deployments
 
-// GetToken retrieves the stored authentication token from the system keyring.
+// backendBaseUrlOverride is set from the --backend-url flag and takes precedence over the env var.
+var backendBaseUrlOverride string
+
+// SetBackendBaseUrl overrides the backend used for authentication. An empty url restores
+// the default resolution (ITHENA_BACKEND_URL, then the production backend).
+func SetBackendBaseUrl(url string) {
+	backendBaseUrlOverride = url
+}
+
+// backendBaseUrl returns the configured backend base URL without a trailing slash.
+func backendBaseUrl() string {
+	url := backendBaseUrlOverride
+	if url == "" {
+		url = os.Getenv(backendUrlEnv)
+	}
+	if url == "" {
+		url = defaultBackendBaseUrl
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// GetToken retrieves the stored authentication token from the system keyring, or from the
+// token file when ITHENA_TOKEN_FILE_FALLBACK is enabled and the keyring is unavailable.
 func GetToken() (string, error) {
 	token, err := keyring.Get(keyringServiceName, keyringTokenKey)
+	if useTokenFile(err) {
+		token, err = readTokenFile()
+	}
 	if err != nil {
 		// Handle specific errors like "not found" if needed,
 		// but for now, just return the error.
@@ -54,12 +83,35 @@ func GetToken() (string, error) {
 	return token, nil
 }
 
+// storeToken saves the access token to the system keyring and reads it back,
+// so a successful return means later GetToken calls will find it.
+func storeToken(token string) error {
+	if err := keyring.Set(keyringServiceName, keyringTokenKey, token); err != nil {
+		if !useTokenFile(err) {
+			return fmt.Errorf("failed to store token in keychain: %w", err)
+		}
+		log.Printf("Keychain unavailable (%v); storing token in a file readable only by you.", err)
+		if err := writeTokenFile(token); err != nil {
+			return err
+		}
+	}
+	stored, err := GetToken()
+	if err != nil {
+		return fmt.Errorf("token was written but could not be read back: %w", err)
+	}
+	if stored != token {
+		return errors.New("token read back from keychain does not match the token that was stored")
+	}
+	return nil
+}
+
 // HandleAuth performs the OAuth device authorization flow.
 func HandleAuth() {
 	log.Println("Initiating device authorization flow...")
 
-	deviceAuthURL := backendBaseUrl + "/api/cli/auth/device"
-	resp, err := http.Post(deviceAuthURL, "application/json", nil)
+	deviceAuthURL := backendBaseUrl() + "/api/cli/auth/device"
+	client := httpclient.New()
+	resp, err := client.Post(deviceAuthURL, "application/json", nil)
 	if err != nil {
 		log.Fatalf("Error initiating device auth: %v", err)
 	}
@@ -84,12 +136,12 @@ func HandleAuth() {
 	codeColor := color.New(color.FgMagenta, color.Bold)
 
 	header.Printf("\n=== CLI Authorization Required ===\n")
-	fmt.Printf("1. Open the following URL in your browser:\n   %s\n", urlColor.Sprint("https://ithena.one/cli-auth/verify"))
+	fmt.Printf("1. Open the following URL in your browser:\n   %s\n", urlColor.Sprint(backendBaseUrl()+"/cli-auth/verify"))
 	fmt.Printf("2. Enter the following code when prompted:\n   %s\n\n", codeColor.Sprint(authResp.UserCode))
 	fmt.Println("Waiting for authorization...")
 
 	// Polling Logic
-	tokenURL := backendBaseUrl + "/api/cli/auth/token"
+	tokenURL := backendBaseUrl() + "/api/cli/auth/token"
 	pollInterval := time.Duration(authResp.Interval) * time.Second
 	expiryTime := time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second).Add(10 * time.Second)
 
@@ -107,7 +159,7 @@ func HandleAuth() {
 			continue
 		}
 
-		pollResp, err := http.Post(tokenURL, "application/json", bytes.NewBuffer(jsonPayload))
+		pollResp, err := client.Post(tokenURL, "application/json", bytes.NewBuffer(jsonPayload))
 		if err != nil {
 			log.Printf("Error polling for token: %v", err)
 			continue
@@ -125,16 +177,17 @@ func HandleAuth() {
 			}
 			fmt.Println("\nAuthorization successful!")
 
-			err = keyring.Set(keyringServiceName, keyringTokenKey, tokenResp.AccessToken)
+			err = storeToken(tokenResp.AccessToken)
 			if err != nil {
-				log.Printf("Warning: Failed to store token securely in keychain: %v", err)
-				fmt.Println("Failed to save token to keychain. You may need to authenticate again later.")
-			} else {
-				log.Println("Access token securely stored.")
+				log.Printf("Error: Failed to persist access token: %v", err)
+				fmt.Println("Authorization succeeded, but the token could not be saved. You are NOT logged in.")
+				fmt.Println("Check that your system keychain is available and unlocked, then run `ithena-cli auth` again.")
+				os.Exit(1)
 			}
+			log.Println("Access token securely stored.")
 
 			log.Printf("Received Access Token: [REDACTED] (Type: %s)", tokenResp.TokenType)
-			fmt.Println("Authentication complete.")
+			verifyNewToken(tokenResp.AccessToken)
 			return
 		}
 
@@ -176,6 +229,29 @@ func HandleAuth() {
 	os.Exit(1)
 }
 
+// verifyNewToken checks a freshly stored token against the backend and reports the outcome.
+// A rejected token is only a warning: it stays stored, since the cause (such as clock skew
+// or --backend-url pointing at another deployment) may be fixed without logging in again.
+func verifyNewToken(token string) {
+	account, err := fetchAccount(token)
+	switch {
+	case errors.Is(err, ErrTokenRejected):
+		color.Yellow("Warning: The token was stored, but the backend rejected it (401) when checking it.")
+		color.Yellow("  Check that your system clock is correct and that --backend-url/%s points at the deployment you logged in to, then run 'ithena-cli auth whoami'.", backendUrlEnv)
+	case errors.Is(err, errNoAccountEndpoint):
+		fmt.Println("Authentication complete.")
+	case err != nil:
+		color.Yellow("Warning: The token was stored, but could not be checked: %v", err)
+		fmt.Println("Authentication complete.")
+	default:
+		if account.Email != "" {
+			fmt.Printf("Authentication complete. Logged in as %s.\n", account.Email)
+		} else {
+			fmt.Println("Authentication complete.")
+		}
+	}
+}
+
 // HandleAuthStatusCommand checks and displays the current authentication status.
 func HandleAuthStatusCommand() {
 	token, err := GetToken()
@@ -183,7 +259,7 @@ func HandleAuthStatusCommand() {
 		// Consider specific error checking if GetToken can return different error types
 		// For now, any error or empty token means not authenticated.
 		// We can use keyring.ErrNotFound if we want to be specific about the token not existing vs other errors.
-		if err == keyring.ErrNotFound {
+		if errors.Is(err, keyring.ErrNotFound) {
 			fmt.Println("Not authenticated. No token found in keychain.")
 		} else if err != nil {
 			log.Printf("Error checking authentication status: %v", err)
@@ -194,25 +270,47 @@ func HandleAuthStatusCommand() {
 		return
 	}
 	// At this point, token is not empty and err is nil
-	fmt.Println("Authenticated.")
-	// Optionally: Decode JWT token here to show expiry or other non-sensitive info
-	// but that would require a JWT parsing library.
+	claims, err := ParseTokenClaims(token)
+	if err != nil {
+		// Opaque (non-JWT) tokens carry no details we can show.
+		fmt.Println("Authenticated.")
+		return
+	}
+
+	now := time.Now()
+	if claims.Expired(now) {
+		color.Yellow("Warning: Your token expired on %s. Run 'ithena-cli auth' to log in again.", claims.ExpiresAt.Local().Format(time.RFC1123))
+	} else {
+		fmt.Println("Authenticated.")
+	}
+	if claims.Email != "" {
+		fmt.Printf("  Email:   %s\n", claims.Email)
+	}
+	if claims.Subject != "" {
+		fmt.Printf("  Subject: %s\n", claims.Subject)
+	}
+	if !claims.ExpiresAt.IsZero() && !claims.Expired(now) {
+		fmt.Printf("  Expires: %s (in %s)\n", claims.ExpiresAt.Local().Format(time.RFC1123), claims.ExpiresAt.Sub(now).Round(time.Minute))
+	}
 }
 
 // HandleDeauthCommand removes the stored authentication token.
 func HandleDeauthCommand() {
 	// First, check if a token exists to provide better user feedback
 	_, err := GetToken()
-	if err == keyring.ErrNotFound {
+	if errors.Is(err, keyring.ErrNotFound) {
 		fmt.Println("Not authenticated. No active session to log out from.")
 		return
-	} else if err != nil && err != keyring.ErrNotFound { // some other error trying to get the token
+	} else if err != nil { // some other error trying to get the token
 		log.Printf("Error checking token before deauthentication: %v", err)
 		fmt.Println("Could not verify current session status, but will attempt to remove token.")
 		// Proceed to attempt deletion anyway
 	}
 
 	err = keyring.Delete(keyringServiceName, keyringTokenKey)
+	if useTokenFile(err) {
+		err = deleteTokenFile()
+	}
 	if err != nil {
 		if err == keyring.ErrNotFound { // Should be caught by the check above, but good to be safe
 			fmt.Println("Not authenticated. No active session to log out from.")
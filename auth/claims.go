@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// TokenClaims holds the JWT claims shown by 'auth status'. Fields are empty when the token
+// does not carry the corresponding claim.
+type TokenClaims struct {
+	Subject   string
+	Email     string
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the token has an expiry that lies before now.
+func (c *TokenClaims) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
+}
+
+// ParseTokenClaims decodes the payload segment of a JWT without verifying its signature.
+// It is only meant for displaying token details; never use the result for authorization.
+func ParseTokenClaims(token string) (*TokenClaims, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+	// JWT segments are base64url without padding, but tolerate padded encoders.
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segments[1], "="))
+	if err != nil {
+		return nil, errors.New("token payload is not valid base64url")
+	}
+
+	var raw struct {
+		Sub   string      `json:"sub"`
+		Email string      `json:"email"`
+		Exp   json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, errors.New("token payload is not a JSON object")
+	}
+
+	claims := &TokenClaims{Subject: raw.Sub, Email: raw.Email}
+	if raw.Exp != "" {
+		exp, err := raw.Exp.Float64()
+		if err != nil {
+			return nil, errors.New("token 'exp' claim is not a number")
+		}
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return claims, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// tokenFileFallbackEnv enables storing the token in a file when the system keyring is
+// unavailable (e.g. headless Linux without a Secret Service). Off by default.
+const tokenFileFallbackEnv = "ITHENA_TOKEN_FILE_FALLBACK"
+
+// tokenFileName is the fallback token file inside the ithena-cli config directory.
+const tokenFileName = "token"
+
+// tokenFileFallbackEnabled reports whether ITHENA_TOKEN_FILE_FALLBACK is set to "true".
+func tokenFileFallbackEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv(tokenFileFallbackEnv)), "true")
+}
+
+// useTokenFile reports whether a keyring error should be answered from the token file:
+// the fallback is enabled and the keyring itself failed (as opposed to holding no token).
+func useTokenFile(keyringErr error) bool {
+	return keyringErr != nil && !errors.Is(keyringErr, keyring.ErrNotFound) && tokenFileFallbackEnabled()
+}
+
+// tokenFilePath returns the path of the fallback token file. Unlike the local log store it
+// has no fallback under the shared temporary directory: without a user config directory the
+// token is not stored at all, so no other user can plant or read it.
+func tokenFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "ithena-cli", tokenFileName), nil
+}
+
+// readTokenFile returns the token from the fallback file, or keyring.ErrNotFound when there
+// is none, so callers can treat both stores alike.
+func readTokenFile() (string, error) {
+	path, err := tokenFilePath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", keyring.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file %s: %w", path, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", keyring.ErrNotFound
+	}
+	return token, nil
+}
+
+// writeTokenFile stores the token in the fallback file, readable only by the current user.
+// The file is written to a temporary name and renamed so a crash never leaves a partial token.
+func writeTokenFile(token string) error {
+	path, err := tokenFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for token file: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), tokenFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create token file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to restrict token file permissions: %w", err)
+	}
+	if _, err := tmp.WriteString(token); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+// deleteTokenFile removes the fallback file, returning keyring.ErrNotFound if there is none.
+func deleteTokenFile() error {
+	path, err := tokenFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return keyring.ErrNotFound
+		}
+		return fmt.Errorf("failed to remove token file %s: %w", path, err)
+	}
+	return nil
+}
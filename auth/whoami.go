@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ithena-one/Ithena/packages/cli/httpclient"
+	"github.com/zalando/go-keyring"
+)
+
+// WhoamiResponse is the account behind the stored token, as returned by /api/cli/me.
+type WhoamiResponse struct {
+	UserID  string   `json:"user_id,omitempty"`
+	Email   string   `json:"email,omitempty"`
+	OrgID   string   `json:"org_id,omitempty"`
+	OrgName string   `json:"org_name,omitempty"`
+	Scopes  []string `json:"scopes"`
+}
+
+// ErrTokenRejected is returned by fetchAccount when the backend answers 401 Unauthorized.
+var ErrTokenRejected = errors.New("token rejected by the backend")
+
+// errNoAccountEndpoint is returned by fetchAccount when the backend has no /api/cli/me, as
+// older or self-hosted deployments may not.
+var errNoAccountEndpoint = errors.New("the backend does not provide account details (/api/cli/me not found)")
+
+// fetchAccount asks the backend which account token belongs to.
+func fetchAccount(token string) (*WhoamiResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, backendBaseUrl()+"/api/cli/me", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpclient.New().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach %s: %w", backendBaseUrl(), err)
+	}
+	defer resp.Body.Close()
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, ErrTokenRejected
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNoAccountEndpoint
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected response from the backend (%s): %s", resp.Status, strings.TrimSpace(string(bodyBytes)))
+	}
+
+	var account WhoamiResponse
+	if err := json.Unmarshal(bodyBytes, &account); err != nil {
+		return nil, fmt.Errorf("could not decode the account response: %w", err)
+	}
+	return &account, nil
+}
+
+// HandleWhoamiCommand asks the backend which account the stored token belongs to. Unlike
+// 'auth status', which only decodes the token locally, this also tells whether the backend
+// still accepts it.
+func HandleWhoamiCommand(args []string) {
+	whoamiCmd := flag.NewFlagSet("auth whoami", flag.ExitOnError)
+	asJSON := whoamiCmd.Bool("json", false, "Print the account as JSON")
+	whoamiCmd.Parse(args)
+
+	token, err := GetToken()
+	if err != nil || token == "" {
+		if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "Error: Could not read the stored token: %v\n", err)
+		} else {
+			fmt.Fprintln(os.Stderr, "Error: Not authenticated. Run 'ithena-cli auth' to log in.")
+		}
+		os.Exit(1)
+	}
+
+	account, err := fetchAccount(token)
+	if errors.Is(err, ErrTokenRejected) {
+		fmt.Fprintln(os.Stderr, "Error: The backend rejected the stored token (401). It may have expired or been revoked; run 'ithena-cli auth' to log in again.")
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		if account.Scopes == nil {
+			account.Scopes = []string{}
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(account); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("Authenticated.")
+	if account.Email != "" {
+		fmt.Printf("  Email:  %s\n", account.Email)
+	}
+	if account.UserID != "" {
+		fmt.Printf("  User:   %s\n", account.UserID)
+	}
+	switch {
+	case account.OrgName != "" && account.OrgID != "":
+		fmt.Printf("  Org:    %s (%s)\n", account.OrgName, account.OrgID)
+	case account.OrgName != "" || account.OrgID != "":
+		fmt.Printf("  Org:    %s%s\n", account.OrgName, account.OrgID)
+	}
+	if len(account.Scopes) > 0 {
+		fmt.Printf("  Scopes: %s\n", strings.Join(account.Scopes, ", "))
+	} else {
+		fmt.Println("  Scopes: (none)")
+	}
+}
@@ -0,0 +1,116 @@
+package configcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ithena-one/Ithena/packages/cli/config"
+)
+
+// profileSummary is the JSON shape of one profile in 'config list --json'. Env values are
+// left out since they may hold secrets or placeholders for them.
+type profileSummary struct {
+	Name      string   `json:"name"`
+	Command   string   `json:"command,omitempty"`
+	Args      []string `json:"args"`
+	Alias     string   `json:"alias,omitempty"`
+	Transport string   `json:"transport,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	EnvKeys   []string `json:"env_keys"`
+	Backends  []string `json:"backends,omitempty"` // Aliases of the backends of a profile with several
+}
+
+// HandleConfigListCommand handles 'ithena-cli config list'. It prints every profile of the
+// wrapper config with its command, args, alias and env keys. defaultFile is the value of
+// --wrapper-config-file.
+func HandleConfigListCommand(verbose bool, defaultFile string, args []string) {
+	listCmd := flag.NewFlagSet("config list", flag.ExitOnError)
+	filePath := listCmd.String("file", defaultFile, "Path to the wrapper configuration file (YAML, or JSON if the name ends in .json)")
+	asJSON := listCmd.Bool("json", false, "Print the profiles as a JSON array")
+	listCmd.Parse(args)
+
+	if verbose { log.Printf("Listing profiles of wrapper config '%s'...", *filePath) }
+	if _, err := os.Stat(*filePath); errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error: Wrapper config file '%s' does not exist. Use --file or --wrapper-config-file to point to it.\n", *filePath)
+		os.Exit(1)
+	}
+	wrapperConf, err := config.LoadWrapperConfig(*filePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	summaries := summarizeProfiles(wrapperConf)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(summaries); err != nil {
+			log.Fatalf("Error writing JSON: %v", err)
+		}
+		return
+	}
+
+	if len(summaries) == 0 {
+		fmt.Printf("No profiles defined in %s (add them under the 'wrappers' key).\n", *filePath)
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROFILE\tALIAS\tCOMMAND\tENV KEYS")
+	for _, s := range summaries {
+		target := strings.TrimSpace(strings.Join(append([]string{s.Command}, s.Args...), " "))
+		if s.Transport == "sse" {
+			target = "sse " + s.URL
+		} else if len(s.Backends) > 0 {
+			target = "backends " + strings.Join(s.Backends, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, orDash(s.Alias), orDash(target), orDash(strings.Join(s.EnvKeys, ", ")))
+	}
+	tw.Flush()
+}
+
+// summarizeProfiles returns the profiles sorted by name, without env values.
+func summarizeProfiles(wrapperConf *config.WrapperConfig) []profileSummary {
+	summaries := make([]profileSummary, 0, len(wrapperConf.Wrappers))
+	for name, profile := range wrapperConf.Wrappers {
+		envKeys := make([]string, 0, len(profile.Env))
+		for key := range profile.Env {
+			envKeys = append(envKeys, key)
+		}
+		sort.Strings(envKeys)
+		profileArgs := profile.Args
+		if profileArgs == nil {
+			profileArgs = []string{}
+		}
+		var backends []string
+		for _, backend := range profile.Backends {
+			backends = append(backends, backend.Alias)
+		}
+		summaries = append(summaries, profileSummary{
+			Name:      name,
+			Command:   profile.Command,
+			Args:      profileArgs,
+			Alias:     profile.Alias,
+			Transport: profile.Transport,
+			URL:       profile.URL,
+			EnvKeys:   envKeys,
+			Backends:  backends,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,25 @@
+package configcmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ithena-one/Ithena/packages/cli/config"
+)
+
+// HandleConfigSchemaCommand handles 'ithena-cli config schema'. It prints the JSON schema of
+// the wrapper config, for editors to complete and check wrappers.yaml.
+func HandleConfigSchemaCommand(args []string) {
+	schemaCmd := flag.NewFlagSet("config schema", flag.ExitOnError)
+	schemaCmd.Parse(args)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(config.WrapperConfigSchema()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package configcmd
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/ithena-one/Ithena/packages/cli/config"
+)
+
+// HandleConfigValidateCommand handles 'ithena-cli config validate'. It checks the wrapper config
+// without starting any server or resolving secrets, prints the problems found per profile and
+// exits non-zero if any of them is fatal. defaultFile is the value of --wrapper-config-file.
+func HandleConfigValidateCommand(verbose bool, defaultFile string, args []string) {
+	validateCmd := flag.NewFlagSet("config validate", flag.ExitOnError)
+	filePath := validateCmd.String("file", defaultFile, "Path to the wrapper configuration file (YAML, or JSON if the name ends in .json)")
+	validateCmd.Parse(args)
+
+	if verbose { log.Printf("Validating wrapper config '%s'...", *filePath) }
+	wrapperConf, problems, err := config.ValidateWrapperConfig(*filePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	byProfile := make(map[string][]config.Problem)
+	for _, problem := range problems {
+		byProfile[problem.Profile] = append(byProfile[problem.Profile], problem)
+	}
+	names := make([]string, 0, len(wrapperConf.Wrappers))
+	for name := range wrapperConf.Wrappers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("Validating %s (%d profile(s))\n\n", *filePath, len(names))
+	if fileProblems := byProfile[""]; len(fileProblems) > 0 {
+		fmt.Println("(file):")
+		printProblems(fileProblems)
+	}
+	for _, name := range names {
+		if len(byProfile[name]) == 0 {
+			fmt.Printf("%s: OK\n", name)
+			continue
+		}
+		fmt.Printf("%s:\n", name)
+		printProblems(byProfile[name])
+	}
+
+	fatal := 0
+	for _, problem := range problems {
+		if problem.Fatal {
+			fatal++
+		}
+	}
+	fmt.Printf("\n%d error(s), %d warning(s).\n", fatal, len(problems)-fatal)
+	if fatal > 0 {
+		os.Exit(1)
+	}
+}
+
+func printProblems(problems []config.Problem) {
+	for _, problem := range problems {
+		level := "warning"
+		if problem.Fatal {
+			level = "error"
+		}
+		fmt.Printf("  %-8s %s\n", level+":", problem.Message)
+	}
+}
@@ -0,0 +1,184 @@
+package logs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// exportPageSize is how many records are read from the store at a time while exporting.
+const exportPageSize = 500
+
+// exportWriter writes audit records in one export format.
+type exportWriter interface {
+	WriteRecord(record types.AuditRecord) error
+	Finish() error
+}
+
+// HandleLogsExportCommand handles the 'ithena-cli logs export' command.
+func HandleLogsExportCommand(verbose bool, args []string) {
+	exportCmd := flag.NewFlagSet("logs export", flag.ExitOnError)
+	format := exportCmd.String("format", "ndjson", "Output format: ndjson, csv or loki")
+	outputPath := exportCmd.String("output", "", "Write the export to this file instead of stdout")
+	filters := addFilterFlags(exportCmd)
+	exportCmd.Parse(args)
+	validateFilterFlags(filters)
+
+	var out io.Writer = os.Stdout
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			log.Fatalf("Error creating output file %s: %v", *outputPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	var writer exportWriter
+	switch *format {
+	case "ndjson":
+		writer = &ndjsonExportWriter{encoder: json.NewEncoder(out)}
+	case "csv":
+		writer = &csvExportWriter{writer: csv.NewWriter(out)}
+	case "loki":
+		writer = &lokiExportWriter{out: out, batch: newLokiBatch()}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown export format '%s'. Supported formats: ndjson, csv, loki\n", *format)
+		os.Exit(1)
+	}
+
+	initLocalStore(verbose, "logs export")
+	validateFilterFlags(filters)
+
+	exported, err := exportLogs(*filters, writer)
+	if err != nil {
+		log.Fatalf("Error exporting logs: %v", err)
+	}
+	if err := writer.Finish(); err != nil {
+		log.Fatalf("Error finishing export: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d log(s).\n", exported)
+}
+
+// exportLogs pages through all logs matching filters and hands each one to writer,
+// so the full table is never held in memory.
+func exportLogs(filters localstore.LogQueryFilters, writer exportWriter) (int, error) {
+	exported := 0
+	for page := 1; ; page++ {
+		result, err := localstore.QueryLogs(filters, page, exportPageSize)
+		if err != nil {
+			return exported, err
+		}
+		for _, record := range result.Logs {
+			if err := writer.WriteRecord(record); err != nil {
+				return exported, fmt.Errorf("failed to write record %s: %w", record.ID, err)
+			}
+			exported++
+		}
+		if !result.HasNext {
+			return exported, nil
+		}
+	}
+}
+
+// ndjsonExportWriter writes one JSON-encoded AuditRecord per line.
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonExportWriter) WriteRecord(record types.AuditRecord) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonExportWriter) Finish() error {
+	return nil
+}
+
+// csvExportWriter writes flattened records, serializing the JSON fields into single cells.
+type csvExportWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+var csvExportHeader = []string{
+	"id", "timestamp", "mcp_method", "tool_name", "duration_ms", "status", "proxy_version",
+	"target_server_alias", "request_preview", "response_preview", "error_details", "session",
+	"request_bytes", "response_bytes", "first_byte_ms",
+}
+
+func (w *csvExportWriter) WriteRecord(record types.AuditRecord) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(csvExportHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	var session interface{}
+	if record.Session != nil {
+		session = record.Session
+	}
+	return w.writer.Write([]string{
+		record.ID,
+		record.Timestamp,
+		stringOrEmpty(record.McpMethod),
+		stringOrEmpty(record.ToolName),
+		int64OrEmpty(record.DurationMs),
+		record.Status,
+		stringOrEmpty(record.ProxyVersion),
+		stringOrEmpty(record.TargetServerAlias),
+		jsonCell(record.RequestPreview),
+		jsonCell(record.ResponsePreview),
+		jsonCell(record.ErrorDetails),
+		jsonCell(session),
+		int64OrEmpty(record.RequestBytes),
+		int64OrEmpty(record.ResponseBytes),
+		int64OrEmpty(record.FirstByteMs),
+	})
+}
+
+func (w *csvExportWriter) Finish() error {
+	if !w.headerWritten {
+		if err := w.writer.Write(csvExportHeader); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// stringOrEmpty dereferences an optional string field.
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// int64OrEmpty formats an optional integer field.
+func int64OrEmpty(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+// jsonCell serializes a JSON field for a single CSV cell; nil becomes an empty cell.
+func jsonCell(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
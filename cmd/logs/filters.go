@@ -0,0 +1,90 @@
+package logs
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+)
+
+// addFilterFlags registers the common log filter flags on fs and returns the filters they populate.
+// The flags mirror the query parameters accepted by the web UI's /api/logs endpoint.
+func addFilterFlags(fs *flag.FlagSet) *localstore.LogQueryFilters {
+	filters := &localstore.LogQueryFilters{}
+	fs.Var((*multiValueFlag)(&filters.Statuses), "status", "Only include logs with this status (e.g. success, failure); repeatable")
+	fs.Var((*multiValueFlag)(&filters.ToolNames), "tool", "Only include logs for this tool name; repeatable")
+	fs.Var((*multiValueFlag)(&filters.McpMethods), "method", "Only include logs for this MCP method; repeatable")
+	fs.StringVar(&filters.SearchTerm, "search", "", "Only include logs whose ID or payloads contain this text")
+	fs.StringVar(&filters.StartTime, "start", "", "Only include logs at or after this RFC3339 time")
+	fs.StringVar(&filters.EndTime, "end", "", "Only include logs at or before this RFC3339 time")
+	fs.Int64Var(&filters.MinDurationMs, "min-ms", 0, "Only include logs that took at least this many milliseconds")
+	fs.Int64Var(&filters.MaxDurationMs, "max-ms", 0, "Only include logs that took at most this many milliseconds")
+	fs.Var(fieldFilterFlag{filters}, "field", "Only include logs whose indexed field has this value, as name=value (repeatable)")
+	return filters
+}
+
+// multiValueFlag collects a repeated flag into a slice; logs matching any of the values are included.
+type multiValueFlag []string
+
+func (f *multiValueFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+func (f *multiValueFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// fieldFilterFlag collects repeated --field name=value flags into LogQueryFilters.Fields.
+type fieldFilterFlag struct {
+	filters *localstore.LogQueryFilters
+}
+
+func (f fieldFilterFlag) String() string {
+	if f.filters == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(f.filters.Fields))
+	for name, value := range f.filters.Fields {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFilterFlag) Set(value string) error {
+	name, fieldValue, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got '%s'", value)
+	}
+	if f.filters.Fields == nil {
+		f.filters.Fields = make(map[string]string)
+	}
+	f.filters.Fields[name] = fieldValue
+	return nil
+}
+
+// validateFilterFlags exits with an error if the filter flags are inconsistent. Field filters
+// are only checked against the indexed fields once the local store is open.
+func validateFilterFlags(filters *localstore.LogQueryFilters) {
+	if err := localstore.ValidateFilters(*filters); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// initLocalStore opens the local log database for a logs subcommand, exiting on failure.
+func initLocalStore(verbose bool, commandName string) {
+	localstore.SetVerbose(verbose)
+	if err := localstore.InitDB(""); err != nil {
+		log.Fatalf("Error initializing local database for '%s': %v", commandName, err)
+	}
+	if verbose {
+		log.Printf("Local database initialized successfully for '%s'.", commandName)
+	}
+}
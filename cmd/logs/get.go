@@ -0,0 +1,127 @@
+package logs
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// HandleLogsGetCommand handles the 'ithena-cli logs get <id>' command.
+func HandleLogsGetCommand(verbose bool, args []string) {
+	getCmd := flag.NewFlagSet("logs get", flag.ExitOnError)
+	asJSON := getCmd.Bool("json", false, "Print the record as JSON, e.g. for jq")
+
+	// The flag may come before or after the ID.
+	var ids []string
+	rest := args
+	for {
+		getCmd.Parse(rest)
+		if getCmd.NArg() == 0 {
+			break
+		}
+		ids = append(ids, getCmd.Arg(0))
+		rest = getCmd.Args()[1:]
+	}
+	if len(ids) != 1 || strings.TrimSpace(ids[0]) == "" {
+		fmt.Fprintln(os.Stderr, "Error: Expected exactly one log ID. Usage: ithena-cli logs get [--json] <id>")
+		os.Exit(1)
+	}
+	id := strings.TrimSpace(ids[0])
+
+	initLocalStore(verbose, "logs get")
+
+	record, err := localstore.GetLogByID(id)
+	if err != nil {
+		log.Fatalf("Error reading log: %v", err)
+	}
+	if record == nil {
+		fmt.Fprintf(os.Stderr, "Error: Log '%s' not found.\n", id)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(record); err != nil {
+			log.Fatalf("Error encoding log: %v", err)
+		}
+		return
+	}
+	printRecord(*record)
+}
+
+// printRecord prints every field of r, with its JSON payloads indented.
+func printRecord(r types.AuditRecord) {
+	label := color.New(color.Faint)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	row := func(name string, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s\t%s\n", label.Sprint(name), value)
+		}
+	}
+	row("ID", r.ID)
+	row("Timestamp", r.Timestamp)
+	row("Status", statusColor(r.Status).Sprint(r.Status))
+	row("Method", stringOrEmpty(r.McpMethod))
+	row("Tool", stringOrEmpty(r.ToolName))
+	row("Server alias", stringOrEmpty(r.TargetServerAlias))
+	if r.DurationMs != nil {
+		row("Duration", fmt.Sprintf("%d ms", *r.DurationMs))
+	}
+	if r.FirstByteMs != nil {
+		row("First byte", fmt.Sprintf("%d ms", *r.FirstByteMs))
+	}
+	if r.RequestBytes != nil {
+		row("Bytes in", formatBytes(*r.RequestBytes))
+	}
+	if r.ResponseBytes != nil {
+		row("Bytes out", formatBytes(*r.ResponseBytes))
+	}
+	row("Proxy version", stringOrEmpty(r.ProxyVersion))
+	if r.PendingUpload {
+		row("Pending upload", "yes")
+	}
+	if r.Session != nil {
+		row("Session", r.Session.SessionID)
+		row("Command", r.Session.CommandPath)
+		row("Command version", r.Session.CommandVersion)
+		if r.Session.WrapperPID != 0 {
+			row("Wrapper PID", fmt.Sprint(r.Session.WrapperPID))
+		}
+	}
+	tw.Flush()
+
+	sections := []struct {
+		title string
+		value interface{}
+	}{
+		{"Tool arguments", r.ToolArgs},
+		{"Request", r.RequestPreview},
+		{"Response", r.ResponsePreview},
+		{"Error", r.ErrorDetails},
+	}
+	for _, s := range sections {
+		if s.value == nil {
+			continue
+		}
+		fmt.Printf("\n%s\n%s\n", color.New(color.Bold).Sprint(s.title+":"), indentedJSON(s.value))
+	}
+}
+
+// indentedJSON formats a payload for reading; strings that aren't JSON are printed as-is.
+func indentedJSON(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
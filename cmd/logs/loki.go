@@ -0,0 +1,224 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// lokiJobLabel identifies ithena-cli records among other streams in Loki.
+const lokiJobLabel = "ithena-cli"
+
+// lokiPushTimeout bounds each POST to a Loki push endpoint.
+const lokiPushTimeout = 30 * time.Second
+
+// lokiStream is one stream of Loki's push API JSON format: a label set and its log lines,
+// each a [unix-nanoseconds, line] pair.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiPushRequest is the body of a POST to Loki's /loki/api/v1/push endpoint.
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// lokiBatch groups records into streams by their label set.
+type lokiBatch struct {
+	streams map[string]*lokiStream
+	order   []string
+	size    int
+}
+
+func newLokiBatch() *lokiBatch {
+	return &lokiBatch{streams: make(map[string]*lokiStream)}
+}
+
+// add appends record to the stream matching its labels. The log line is the JSON record.
+func (b *lokiBatch) add(record types.AuditRecord) error {
+	ts, err := time.Parse(time.RFC3339Nano, record.Timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp '%s': %w", record.Timestamp, err)
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{"job": lokiJobLabel, "status": record.Status}
+	if method := stringOrEmpty(record.McpMethod); method != "" {
+		labels["method"] = method
+	}
+	if tool := stringOrEmpty(record.ToolName); tool != "" {
+		labels["tool"] = tool
+	}
+	if alias := stringOrEmpty(record.TargetServerAlias); alias != "" {
+		labels["alias"] = alias
+	}
+	key := lokiLabelKey(labels)
+
+	stream, ok := b.streams[key]
+	if !ok {
+		stream = &lokiStream{Stream: labels, Values: [][2]string{}}
+		b.streams[key] = stream
+		b.order = append(b.order, key)
+	}
+	stream.Values = append(stream.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(line)})
+	b.size++
+	return nil
+}
+
+// pushRequest returns the batch in push format, with each stream's entries in ascending
+// time order as Loki expects. logs push reads records oldest first, so later batches only
+// carry newer entries; sorting here also covers records sharing a timestamp.
+func (b *lokiBatch) pushRequest() lokiPushRequest {
+	req := lokiPushRequest{Streams: make([]*lokiStream, 0, len(b.order))}
+	for _, key := range b.order {
+		stream := b.streams[key]
+		sort.SliceStable(stream.Values, func(i, j int) bool {
+			ti, _ := strconv.ParseInt(stream.Values[i][0], 10, 64)
+			tj, _ := strconv.ParseInt(stream.Values[j][0], 10, 64)
+			return ti < tj
+		})
+		req.Streams = append(req.Streams, stream)
+	}
+	return req
+}
+
+// lokiLabelKey renders labels in a stable order for grouping.
+func lokiLabelKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s=%q,", name, labels[name])
+	}
+	return sb.String()
+}
+
+// lokiExportWriter writes all records as a single Loki push request document.
+type lokiExportWriter struct {
+	out   io.Writer
+	batch *lokiBatch
+}
+
+func (w *lokiExportWriter) WriteRecord(record types.AuditRecord) error {
+	return w.batch.add(record)
+}
+
+func (w *lokiExportWriter) Finish() error {
+	return json.NewEncoder(w.out).Encode(w.batch.pushRequest())
+}
+
+// lokiPushWriter POSTs records to a Loki push endpoint in batches of batchSize.
+type lokiPushWriter struct {
+	url       string
+	tenant    string
+	batchSize int
+	client    *http.Client
+	batch     *lokiBatch
+	verbose   bool
+}
+
+func (w *lokiPushWriter) WriteRecord(record types.AuditRecord) error {
+	if err := w.batch.add(record); err != nil {
+		return err
+	}
+	if w.batch.size >= w.batchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *lokiPushWriter) Finish() error {
+	return w.flush()
+}
+
+func (w *lokiPushWriter) flush() error {
+	if w.batch.size == 0 {
+		return nil
+	}
+	body, err := json.Marshal(w.batch.pushRequest())
+	if err != nil {
+		return fmt.Errorf("failed to marshal Loki push request: %w", err)
+	}
+	req, err := http.NewRequest("POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Loki push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.tenant != "" {
+		req.Header.Set("X-Scope-OrgID", w.tenant)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to Loki: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Loki responded with status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if w.verbose { log.Printf("Pushed batch of %d log(s) to Loki (Status: %s)", w.batch.size, resp.Status) }
+
+	w.batch = newLokiBatch()
+	return nil
+}
+
+// HandleLogsPushCommand handles the 'ithena-cli logs push' command.
+func HandleLogsPushCommand(verbose bool, args []string) {
+	pushCmd := flag.NewFlagSet("logs push", flag.ExitOnError)
+	lokiURL := pushCmd.String("loki-url", "", "Loki push endpoint (e.g. http://localhost:3100/loki/api/v1/push)")
+	tenant := pushCmd.String("tenant", "", "Loki tenant ID, sent as the X-Scope-OrgID header")
+	batchSize := pushCmd.Int("batch-size", exportPageSize, "Number of logs per push request")
+	filters := addFilterFlags(pushCmd)
+	pushCmd.Parse(args)
+
+	if *lokiURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: --loki-url is required for 'logs push'.")
+		pushCmd.Usage()
+		os.Exit(1)
+	}
+	if *batchSize <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --batch-size must be positive.")
+		os.Exit(1)
+	}
+
+	initLocalStore(verbose, "logs push")
+	validateFilterFlags(filters)
+	// Loki rejects entries much older than the newest one of their stream, so push oldest first.
+	filters.SortOrder = "asc"
+
+	writer := &lokiPushWriter{
+		url:       *lokiURL,
+		tenant:    *tenant,
+		batchSize: *batchSize,
+		client:    &http.Client{Timeout: lokiPushTimeout},
+		batch:     newLokiBatch(),
+		verbose:   verbose,
+	}
+	pushed, err := exportLogs(*filters, writer)
+	if err == nil {
+		err = writer.Finish()
+	}
+	if err != nil {
+		log.Fatalf("Error pushing logs to Loki: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Pushed %d log(s) to %s.\n", pushed, *lokiURL)
+}
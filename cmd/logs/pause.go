@@ -0,0 +1,64 @@
+package logs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+)
+
+// HandleLogsPauseCommand handles 'ithena-cli logs pause'. It creates the pause control file,
+// so every running wrapper stops recording within a second, until 'logs resume'.
+func HandleLogsPauseCommand(verbose bool, args []string) {
+	pauseCmd := flag.NewFlagSet("logs pause", flag.ExitOnError)
+	pauseCmd.Parse(args)
+
+	path := pauseFilePath()
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("Capture is already paused (%s exists). Run 'ithena-cli logs resume' to resume it.\n", path)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	content := fmt.Sprintf("Capture paused by 'ithena-cli logs pause' at %s. Delete this file or run 'ithena-cli logs resume' to resume.\n", time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if verbose { log.Printf("Created pause file %s", path) }
+	fmt.Println("Capture paused. Running wrappers keep proxying but record nothing until 'ithena-cli logs resume'.")
+}
+
+// HandleLogsResumeCommand handles 'ithena-cli logs resume'. It removes the pause control file.
+func HandleLogsResumeCommand(verbose bool, args []string) {
+	resumeCmd := flag.NewFlagSet("logs resume", flag.ExitOnError)
+	resumeCmd.Parse(args)
+
+	path := pauseFilePath()
+	if err := os.Remove(path); errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("Capture is not paused.")
+		return
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to remove %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if verbose { log.Printf("Removed pause file %s", path) }
+	fmt.Println("Capture resumed.")
+}
+
+func pauseFilePath() string {
+	path, err := observability.PauseFilePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return path
+}
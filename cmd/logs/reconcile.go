@@ -0,0 +1,170 @@
+package logs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/auth"
+	"github.com/ithena-one/Ithena/packages/cli/config"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// reconcilePageSize is how many records are read from the store at a time while reconciling.
+const reconcilePageSize = 500
+
+// HandleLogsReconcileCommand handles 'ithena-cli logs reconcile'. It compares the IDs of locally
+// stored records against the IDs the platform reports as received over the same time range and
+// lists the records missing remotely, optionally uploading them again. Records the routing
+// rules keep local are left out. defaultObserveUrl is the value of the global --observe-url
+// flag and defaultConfigFile that of --wrapper-config-file, whose local_only_methods apply.
+func HandleLogsReconcileCommand(verbose bool, defaultObserveUrl string, defaultConfigFile string, args []string) {
+	reconcileCmd := flag.NewFlagSet("logs reconcile", flag.ExitOnError)
+	observeUrlFlag := reconcileCmd.String("observe-url", defaultObserveUrl, "URL for the observability API endpoint")
+	since := reconcileCmd.String("since", "", "Only include logs from this long ago until now (e.g. 24h, 7d)")
+	unsynced := reconcileCmd.Bool("unsynced", false, "Only include logs still flagged pending upload")
+	upload := reconcileCmd.Bool("upload", false, "Upload the logs missing from the platform again")
+	configFile := reconcileCmd.String("wrapper-config-file", defaultConfigFile, "Wrapper configuration file whose local_only_methods are left out of the comparison")
+	filters := addFilterFlags(reconcileCmd)
+	reconcileCmd.Parse(args)
+	observeUrl := *observeUrlFlag
+
+	if *since != "" {
+		window, err := localstore.ParseAge(*since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --since value '%s' (expected e.g. 24h or 7d)\n", *since)
+			os.Exit(1)
+		}
+		filters.Since = time.Now().Add(-window)
+	}
+	filters.PendingUpload = *unsynced
+
+	token, err := auth.GetToken()
+	if err != nil || token == "" {
+		fmt.Fprintln(os.Stderr, "Error: Not authenticated. Run 'ithena-cli auth' to log in, then try again.")
+		os.Exit(1)
+	}
+
+	initLocalStore(verbose, "logs reconcile")
+	validateFilterFlags(filters)
+
+	var localOnlyMethods []string
+	if wrapperConf, err := config.LoadWrapperConfig(*configFile); err == nil {
+		localOnlyMethods = wrapperConf.LocalOnlyMethods
+	} else if verbose {
+		log.Printf("Not using local_only_methods from '%s': %v", *configFile, err)
+	}
+	observability.SetLocalOnlyMethods(localOnlyMethods)
+
+	matching, err := collectLogs(*filters)
+	if err != nil {
+		log.Fatalf("Error reading local logs: %v", err)
+	}
+	var records []types.AuditRecord
+	for _, record := range matching {
+		if !observability.KeptLocal(record, observeUrl) {
+			records = append(records, record)
+		}
+	}
+	if skipped := len(matching) - len(records); skipped > 0 {
+		fmt.Printf("Skipped %d local log(s) that routing rules keep local (local_only_methods or observe URL allowlist).\n", skipped)
+	}
+	if len(records) == 0 {
+		fmt.Println("No local logs match; nothing to reconcile.")
+		return
+	}
+	start, end, err := recordTimeRange(records)
+	if err != nil {
+		log.Fatalf("Error reading local logs: %v", err)
+	}
+
+	if verbose { log.Printf("Asking %s for records received between %s and %s...", observability.KnownIDsUrl(observeUrl), start.Format(time.RFC3339), end.Format(time.RFC3339)) }
+	known, err := observability.KnownRecordIDs(observeUrl, token, start, end)
+	if errors.Is(err, observability.ErrKnownIDsUnsupported) {
+		fmt.Printf("Cannot reconcile: %s does not report which records it received.\n", observeUrl)
+		fmt.Printf("%d of the %d matching local log(s) are still flagged pending upload; they are re-sent automatically on the next run.\n", countPending(records), len(records))
+		return
+	}
+	if err != nil {
+		var statusErr *observability.StatusError
+		if errors.As(err, &statusErr) && verbose && statusErr.Body != "" { log.Printf("Response body: %s", statusErr.Body) }
+		fmt.Fprintf(os.Stderr, "Error: Failed to list the records received by the platform: %v\n", err)
+		os.Exit(1)
+	}
+
+	var missing []types.AuditRecord
+	for _, record := range records {
+		if !known[record.ID] {
+			missing = append(missing, record)
+		}
+	}
+	fmt.Printf("Compared %d local log(s) from %s to %s: %d missing from the platform.\n", len(records), start.Format(time.RFC3339), end.Format(time.RFC3339), len(missing))
+	for _, record := range missing {
+		pending := ""
+		if record.PendingUpload {
+			pending = "  (pending upload)"
+		}
+		fmt.Printf("  %s  %s  %s%s\n", record.ID, record.Timestamp, stringOrDash(record.McpMethod), pending)
+	}
+	if len(missing) == 0 || !*upload {
+		if len(missing) > 0 { fmt.Println("Run again with --upload to upload them.") }
+		return
+	}
+
+	observability.SetVerbose(verbose)
+	delivered, err := observability.Reupload(missing, observeUrl, token)
+	fmt.Printf("Uploaded %d of %d missing log(s).\n", delivered, len(missing))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Some logs could not be uploaded: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// collectLogs pages through all logs matching filters.
+func collectLogs(filters localstore.LogQueryFilters) ([]types.AuditRecord, error) {
+	var records []types.AuditRecord
+	for page := 1; ; page++ {
+		result, err := localstore.QueryLogs(filters, page, reconcilePageSize)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, result.Logs...)
+		if !result.HasNext {
+			return records, nil
+		}
+	}
+}
+
+// recordTimeRange returns the earliest and latest timestamps among records.
+func recordTimeRange(records []types.AuditRecord) (time.Time, time.Time, error) {
+	var start, end time.Time
+	for i, record := range records {
+		ts, err := time.Parse(time.RFC3339Nano, record.Timestamp)
+		if err != nil {
+			return start, end, fmt.Errorf("record %s has an invalid timestamp '%s': %w", record.ID, record.Timestamp, err)
+		}
+		if i == 0 || ts.Before(start) {
+			start = ts
+		}
+		if i == 0 || ts.After(end) {
+			end = ts
+		}
+	}
+	return start, end, nil
+}
+
+// countPending returns how many records are flagged pending upload.
+func countPending(records []types.AuditRecord) int {
+	count := 0
+	for _, record := range records {
+		if record.PendingUpload {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,437 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/jsonrpc"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// replayProtocolVersion is sent in the initialize request replay adds when the bundle has none.
+const replayProtocolVersion = "2025-03-26"
+
+// replayDiffPreviewBytes is how much of each side of a differing response is printed.
+const replayDiffPreviewBytes = 300
+
+// HandleLogsReplayFileCommand handles 'ithena-cli logs replay-file bundle.json --wrapper-profile X'.
+// It reads logs exported with 'logs export' (NDJSON or a JSON array), starts a new wrapper for
+// the profile and sends it the recorded requests in their original order, one at a time.
+// With --diff each response is compared with the recorded one. defaultConfigFile is the value
+// of --wrapper-config-file.
+func HandleLogsReplayFileCommand(verbose bool, defaultConfigFile string, args []string) {
+	replayCmd := flag.NewFlagSet("logs replay-file", flag.ExitOnError)
+	profile := replayCmd.String("wrapper-profile", "", "Profile of the wrapper config to replay the requests against (required)")
+	configFile := replayCmd.String("wrapper-config-file", defaultConfigFile, "Path to the wrapper configuration file (YAML, or JSON if the name ends in .json)")
+	diff := replayCmd.Bool("diff", false, "Compare each response with the recorded one and exit non-zero if any differs")
+	timeout := replayCmd.Duration("timeout", 30*time.Second, "How long to wait for each response")
+
+	// Flags may come before or after the bundle path.
+	var paths []string
+	rest := args
+	for {
+		replayCmd.Parse(rest)
+		if replayCmd.NArg() == 0 {
+			break
+		}
+		paths = append(paths, replayCmd.Arg(0))
+		rest = replayCmd.Args()[1:]
+	}
+	if len(paths) != 1 || *profile == "" {
+		fmt.Fprintln(os.Stderr, "Error: Usage: ithena-cli logs replay-file <bundle.json> --wrapper-profile <name> [--diff] [--timeout 30s]")
+		os.Exit(1)
+	}
+
+	records, err := readLogBundle(paths[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	requests := replayableRequests(records)
+	if len(requests) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s contains no recorded requests to replay.\n", paths[0])
+		os.Exit(1)
+	}
+	if verbose { log.Printf("Replaying %d request(s) from %s against profile '%s'...", len(requests), paths[0], *profile) }
+
+	session, err := startReplayWrapper(verbose, *configFile, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed, differed, skipped, transformed := 0, 0, 0, 0
+	initialized := false
+	if !hasMethod(requests, "initialize") {
+		if _, err := session.call("initialize", defaultInitializeParams(), *timeout); err != nil {
+			session.close()
+			fmt.Fprintf(os.Stderr, "Error: initialize failed: %v\n", err)
+			os.Exit(1)
+		}
+		session.notify("notifications/initialized", nil)
+		initialized = true
+	}
+
+	for i, record := range requests {
+		method := *record.McpMethod
+		label := method
+		if record.ToolName != nil && *record.ToolName != "" {
+			label += " " + *record.ToolName
+		}
+		prefix := fmt.Sprintf("[%d/%d] %s", i+1, len(requests), label)
+		if isTruncatedPreview(record.RequestPreview) {
+			skipped++
+			fmt.Printf("%s  SKIPPED: the request was truncated when it was recorded\n", prefix)
+			continue
+		}
+		if hasTransformedValues(record.RequestPreview) {
+			transformed++
+			prefix += " (redacted/hashed values)"
+		}
+		if record.Status == types.StatusNotification {
+			if method == "notifications/initialized" {
+				initialized = true
+			}
+			if err := session.notify(method, record.RequestPreview); err != nil {
+				failed++
+				fmt.Printf("%s  FAILED: %v\n", prefix, err)
+				continue
+			}
+			fmt.Printf("%s  sent as a notification\n", prefix)
+			continue
+		}
+		start := time.Now()
+		resp, err := session.call(method, record.RequestPreview, *timeout)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if method == "initialize" && !initialized && err == nil && !hasMethod(requests, "notifications/initialized") {
+			session.notify("notifications/initialized", nil)
+			initialized = true
+		}
+
+		recorded := "-"
+		if record.DurationMs != nil {
+			recorded = fmt.Sprintf("%dms", *record.DurationMs)
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("%s  FAILED after %v (recorded %s): %v\n", prefix, elapsed, recorded, err)
+			continue
+		}
+
+		outcome := "ok"
+		if resp.Error != nil {
+			outcome = "error: " + resp.Error.Message
+		}
+		line := fmt.Sprintf("%s  %s in %v (recorded %s, %s)", prefix, outcome, elapsed, recorded, record.Status)
+		if *diff {
+			same, want, got, comparable := compareReplayResponse(record, resp)
+			switch {
+			case !comparable:
+				line += "  no recorded response"
+			case same:
+				line += "  same"
+			default:
+				differed++
+				line += "  DIFFERS"
+				line += fmt.Sprintf("\n    recorded: %s\n    replayed: %s", truncateForDiff(want), truncateForDiff(got))
+			}
+		}
+		fmt.Println(line)
+	}
+
+	session.close()
+	fmt.Printf("\nReplayed %d request(s): %d failed", len(requests)-skipped, failed)
+	if *diff {
+		fmt.Printf(", %d differed", differed)
+	}
+	if skipped > 0 {
+		fmt.Printf(", %d skipped as truncated", skipped)
+	}
+	fmt.Println(".")
+	if transformed > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d replayed request(s) contained values redacted or hashed by the transform rules when they were recorded, so the server got those placeholders instead of the original values.\n", transformed)
+	}
+	if failed > 0 || differed > 0 {
+		os.Exit(1)
+	}
+}
+
+// readLogBundle reads records exported by 'logs export --format ndjson', or a JSON array of them.
+func readLogBundle(path string) ([]types.AuditRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log bundle '%s': %w", path, err)
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []types.AuditRecord
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse log bundle '%s': %w", path, err)
+		}
+		return records, nil
+	}
+
+	var records []types.AuditRecord
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		var record types.AuditRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse log bundle '%s' after %d record(s): %w", path, len(records), err)
+		}
+		records = append(records, record)
+	}
+}
+
+// replayableRequests returns the records of client requests and notifications, oldest first.
+// Notifications the server sent are left out. Exports list the newest logs first, so the
+// original order is restored from the request timestamps.
+func replayableRequests(records []types.AuditRecord) []types.AuditRecord {
+	var requests []types.AuditRecord
+	for _, record := range records {
+		if record.Status == types.StatusNotification && record.RequestBytes == nil && record.RequestPreview == nil {
+			continue
+		}
+		if record.McpMethod != nil && *record.McpMethod != "" {
+			requests = append(requests, record)
+		}
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		ti, erri := time.Parse(time.RFC3339Nano, requests[i].Timestamp)
+		tj, errj := time.Parse(time.RFC3339Nano, requests[j].Timestamp)
+		if erri != nil || errj != nil {
+			return requests[i].Timestamp < requests[j].Timestamp
+		}
+		return ti.Before(tj)
+	})
+	return requests
+}
+
+func hasMethod(records []types.AuditRecord, method string) bool {
+	for _, record := range records {
+		if *record.McpMethod == method {
+			return true
+		}
+	}
+	return false
+}
+
+// isTruncatedPreview reports whether preview is the marker a preview too large to record
+// was replaced with, which can't be sent as the original request.
+func isTruncatedPreview(preview interface{}) bool {
+	marker, ok := preview.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	truncated, _ := marker["_truncated"].(bool)
+	return truncated
+}
+
+// hasTransformedValues reports whether preview contains a value the transform rules
+// redacted or hashed when it was recorded.
+func hasTransformedValues(preview interface{}) bool {
+	switch v := preview.(type) {
+	case string:
+		return v == "[REDACTED]" || strings.HasPrefix(v, "hmac-sha256-64:") || strings.HasPrefix(v, "sha256:")
+	case map[string]interface{}:
+		for _, item := range v {
+			if hasTransformedValues(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasTransformedValues(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func defaultInitializeParams() map[string]interface{} {
+	return map[string]interface{}{
+		"protocolVersion": replayProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "ithena-cli-replay", "version": "1"},
+	}
+}
+
+// compareReplayResponse compares a replayed response with what the record captured: the
+// result for a success, the error otherwise. comparable is false when the record has neither,
+// e.g. for a request that timed out.
+func compareReplayResponse(record types.AuditRecord, resp jsonrpc.Response) (same bool, want, got string, comparable bool) {
+	var recorded, replayed interface{}
+	switch {
+	case record.ResponsePreview != nil:
+		recorded = record.ResponsePreview
+	case record.ErrorDetails != nil:
+		recorded = record.ErrorDetails
+	default:
+		return false, "", "", false
+	}
+	if resp.Error != nil {
+		replayed = resp.Error
+	} else {
+		replayed = resp.Result
+	}
+	want, got = canonicalJSON(recorded), canonicalJSON(replayed)
+	return want == got, want, got, true
+}
+
+// canonicalJSON encodes value with map keys sorted, so equal documents encode identically.
+func canonicalJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	var generic interface{}
+	if json.Unmarshal(data, &generic) != nil {
+		return string(data)
+	}
+	data, _ = json.Marshal(generic)
+	return string(data)
+}
+
+func truncateForDiff(s string) string {
+	if len(s) <= replayDiffPreviewBytes {
+		return s
+	}
+	return s[:replayDiffPreviewBytes] + "…"
+}
+
+// replaySession talks JSON-RPC to a wrapper started for the replay.
+type replaySession struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	nextID int
+}
+
+// startReplayWrapper starts this executable in wrapper mode for profile, so the replayed
+// requests go through the profile's command, env and logging like a live session.
+func startReplayWrapper(verbose bool, configFile, profile string) (*replaySession, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the ithena-cli executable: %w", err)
+	}
+	wrapperArgs := []string{"--wrapper-config-file", configFile, "--wrapper-profile", profile}
+	if verbose {
+		wrapperArgs = append([]string{"--verbose"}, wrapperArgs...)
+	}
+	cmd := exec.Command(self, wrapperArgs...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wrapper stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wrapper stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start wrapper for profile '%s': %w", profile, err)
+	}
+
+	session := &replaySession{cmd: cmd, stdin: stdin, lines: make(chan []byte, 16)}
+	go func() {
+		defer close(session.lines)
+		reader := bufio.NewReaderSize(stdout, 64*1024)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+				session.lines <- trimmed
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return session, nil
+}
+
+// call sends a request and waits for its response. Notifications and requests from the
+// server arriving meanwhile are skipped; server requests get a method-not-found error so the
+// server isn't left waiting.
+func (s *replaySession) call(method string, params interface{}, timeout time.Duration) (jsonrpc.Response, error) {
+	s.nextID++
+	id := s.nextID
+	message := map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method}
+	if params != nil {
+		message["params"] = params
+	}
+	if err := s.send(message); err != nil {
+		return jsonrpc.Response{}, err
+	}
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				return jsonrpc.Response{}, errors.New("wrapper exited before responding")
+			}
+			var incoming struct {
+				jsonrpc.Response
+				Method string `json:"method"`
+			}
+			if err := json.Unmarshal(line, &incoming); err != nil {
+				continue
+			}
+			if incoming.Method != "" {
+				if incoming.ID != nil {
+					s.send(map[string]interface{}{"jsonrpc": "2.0", "id": incoming.ID, "error": jsonrpc.Error{Code: -32601, Message: "Method not supported during replay"}})
+				}
+				continue
+			}
+			if responseID, ok := incoming.ID.(float64); ok && int(responseID) == id {
+				return incoming.Response, nil
+			}
+		case <-deadline:
+			return jsonrpc.Response{}, fmt.Errorf("no response within %v", timeout)
+		}
+	}
+}
+
+// notify sends a notification.
+func (s *replaySession) notify(method string, params interface{}) error {
+	message := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if params != nil {
+		message["params"] = params
+	}
+	return s.send(message)
+}
+
+func (s *replaySession) send(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if _, err := s.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to wrapper: %w", err)
+	}
+	return nil
+}
+
+// close ends the session by closing the wrapper's stdin and waits for it to exit, so its
+// logs are flushed.
+func (s *replaySession) close() {
+	s.stdin.Close()
+	go func() {
+		for range s.lines {
+		}
+	}()
+	s.cmd.Wait()
+}
@@ -0,0 +1,133 @@
+package logs
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// searchSnippetContext is how many bytes of text around a match a snippet shows on each side.
+const searchSnippetContext = 60
+
+// HandleLogsSearchCommand handles the 'ithena-cli logs search "query"' command.
+func HandleLogsSearchCommand(verbose bool, args []string) {
+	searchCmd := flag.NewFlagSet("logs search", flag.ExitOnError)
+	limit := searchCmd.Int("limit", 20, "Maximum number of results to show")
+	filters := addFilterFlags(searchCmd)
+
+	// Flags may come before or after the query words.
+	var terms []string
+	rest := args
+	for {
+		searchCmd.Parse(rest)
+		if searchCmd.NArg() == 0 {
+			break
+		}
+		terms = append(terms, searchCmd.Arg(0))
+		rest = searchCmd.Args()[1:]
+	}
+	validateFilterFlags(filters)
+
+	query := strings.Join(terms, " ")
+	if query == "" {
+		query = filters.SearchTerm
+	}
+	if strings.TrimSpace(query) == "" {
+		fmt.Fprintln(os.Stderr, "Error: Missing search query. Usage: ithena-cli logs search [--limit N] \"query\"")
+		os.Exit(1)
+	}
+
+	initLocalStore(verbose, "logs search")
+	validateFilterFlags(filters)
+
+	results, err := localstore.SearchLogs(query, *filters, *limit)
+	if err != nil {
+		log.Fatalf("Error searching logs: %v", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching logs found.")
+		return
+	}
+
+	highlight := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	for _, result := range results {
+		fmt.Println(formatLogLine(result.Record, highlight))
+		if field, snippet := matchSnippet(result.Record, highlight); snippet != "" {
+			fmt.Printf("    %s %s\n", color.New(color.Faint).Sprint(field+":"), snippet)
+		}
+		fmt.Printf("    %s\n", color.New(color.Faint).Sprint("id: "+result.Record.ID))
+	}
+}
+
+// matchSnippet returns the first payload field of r that matches, with the text around the
+// match highlighted. Matches in the tool or method are already visible on the log line.
+func matchSnippet(r types.AuditRecord, highlight *regexp.Regexp) (field string, snippet string) {
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"error", r.ErrorDetails},
+		{"args", r.ToolArgs},
+		{"request", r.RequestPreview},
+		{"response", r.ResponsePreview},
+	}
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		text, ok := f.value.(string)
+		if !ok {
+			encoded, err := json.Marshal(f.value)
+			if err != nil {
+				continue
+			}
+			text = string(encoded)
+		}
+		loc := highlight.FindStringIndex(text)
+		if loc == nil {
+			continue
+		}
+		start, end := loc[0]-searchSnippetContext, loc[1]+searchSnippetContext
+		prefix, suffix := "…", "…"
+		if start <= 0 {
+			start, prefix = 0, ""
+		}
+		if end >= len(text) {
+			end, suffix = len(text), ""
+		}
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		return f.name, prefix + highlightMatches(text[start:end], highlight, color.New(color.Reset)) + suffix
+	}
+	return "", ""
+}
+
+// highlightMatches renders s in base, with the parts matching highlight emphasized.
+func highlightMatches(s string, highlight *regexp.Regexp, base *color.Color) string {
+	if highlight == nil {
+		return base.Sprint(s)
+	}
+	emphasis := color.New(color.FgBlack, color.BgYellow)
+	var b strings.Builder
+	last := 0
+	for _, loc := range highlight.FindAllStringIndex(s, -1) {
+		b.WriteString(base.Sprint(s[last:loc[0]]))
+		b.WriteString(emphasis.Sprint(s[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(base.Sprint(s[last:]))
+	return b.String()
+}
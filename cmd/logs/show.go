@@ -2,6 +2,7 @@ package logs
 
 import (
 	"bufio" // For reading user input
+	"flag"
 	"fmt"
 	"log"
 	"os" // For os.Remove
@@ -14,16 +15,42 @@ import (
 // var verbose bool // Removed as it's passed as a parameter and not used at package level
 // const defaultWebUIPort = 8675 // Port is now passed as an argument
 
+// uiTokenEnv sets the token required by the web UI's API, like 'logs show --auth-token',
+// without putting it on the command line.
+const uiTokenEnv = "ITHENA_UI_TOKEN"
+
 // HandleLogsShowCommand handles the 'ithena-cli logs show' command.
-func HandleLogsShowCommand(verbose bool, port int, version string) { // Added version parameter
+// port is the value of 'logs --port'; args are the arguments after 'show', which may
+// also set --port.
+func HandleLogsShowCommand(verbose bool, port int, version string, args []string) { // Added version parameter
+	showCmd := flag.NewFlagSet("logs show", flag.ExitOnError)
+	showCmd.IntVar(&port, "port", port, "Port for the local logs web UI (0 picks a free port)")
+	host := showCmd.String("host", "localhost", "Address to bind the web UI to; e.g. 0.0.0.0 makes it reachable from other machines")
+	authToken := showCmd.String("auth-token", os.Getenv(uiTokenEnv), "Require this token on every /api request, as 'Authorization: Bearer <token>' or ?token=<token> (default: $"+uiTokenEnv+")")
+	generateToken := showCmd.Bool("generate-token", false, "Like --auth-token, with a random token that is printed in the viewer URL")
+	printReady := showCmd.Bool("print-ready", false, "Don't open a browser; print 'READY <url>' to stdout once the server is listening")
+	showCmd.Parse(args)
+
 	if verbose {
 		log.Printf("Executing 'logs show' command for port %d (CLI version: %s)...", port, version)
 	}
+	if *generateToken {
+		if *authToken != "" {
+			fmt.Fprintln(os.Stderr, "Error: --generate-token cannot be combined with --auth-token or "+uiTokenEnv+".")
+			os.Exit(1)
+		}
+		token, err := webui.GenerateToken()
+		if err != nil {
+			log.Fatalf("Error generating web UI token: %v", err)
+		}
+		*authToken = token
+	}
 
 	localstore.SetVerbose(verbose) 
 	webui.SetVerbose(verbose) // Pass verbosity to webui as well
 
-	err := localstore.InitDB("")
+	// The viewer only reads, apart from explicit deletions, which use a separate writable handle.
+	err := localstore.InitDBReadOnly("")
 	if err != nil {
 		log.Fatalf("Error initializing local database for 'logs show': %v", err)
 	}
@@ -38,18 +65,28 @@ func HandleLogsShowCommand(verbose bool, port int, version string) { // Added ve
 		log.Printf("Info: Could not determine local log store path: %v", pathErr)
 		dbPath = "(Could not determine path)"
 	}
-	fmt.Printf("Attempting to start local log viewer UI. Access it at http://localhost:%d\n", port)
-	fmt.Printf("Local logs are being read from: %s\n", dbPath)
-	fmt.Println("Press Ctrl+C to stop the server.")
+	// With --print-ready, stdout carries only the READY line; keep the rest on stderr.
+	info := os.Stdout
+	if *printReady {
+		info = os.Stderr
+	}
+	fmt.Fprintf(info, "Attempting to start local log viewer UI on %s port %d\n", *host, port)
+	fmt.Fprintf(info, "Local logs are being read from: %s\n", dbPath)
+	fmt.Fprintln(info, "Press Ctrl+C to stop the server.")
 
-	webui.StartServer(port, version) // Pass the version to StartServer
+	webui.StartServer(*host, port, *authToken, version, *printReady) // Pass the version to StartServer
 }
 
-// HandleLogsClearCommand handles the 'ithena-cli logs clear' command.
-func HandleLogsClearCommand(verbose bool) {
+// HandleLogsClearCommand handles the 'ithena-cli logs clear' command. With filter flags in
+// args, only the matching logs are deleted; without, the whole log file is.
+func HandleLogsClearCommand(verbose bool, args []string) {
 	if verbose {
 		log.Println("Executing 'logs clear' command...")
 	}
+	if len(args) > 0 {
+		clearMatchingLogs(verbose, args)
+		return
+	}
 
 	dbPath, err := localstore.GetDefaultLogStorePathForInfo()
 	if err != nil {
@@ -88,8 +125,86 @@ func HandleLogsClearCommand(verbose bool) {
 	} else {
 		fmt.Printf("Successfully deleted local logs file: %s\n", dbPath)
 	}
+	// The store runs in WAL mode; a writer that was killed leaves its -wal and -shm files
+	// behind, and a stale WAL must not be applied to a new database at the same path.
+	for _, path := range []string{dbPath + "-wal", dbPath + "-shm"} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Fatalf("Error deleting local logs file %s: %v", path, err)
+		}
+	}
 
 	if verbose {
 		log.Println("'logs clear' command finished.")
 	}
-} 
\ No newline at end of file
+}
+
+// clearMatchingLogs handles 'ithena-cli logs clear' with filter flags, e.g. --status failure.
+func clearMatchingLogs(verbose bool, args []string) {
+	clearCmd := flag.NewFlagSet("logs clear", flag.ExitOnError)
+	compactOnly := clearCmd.Bool("compact-only", false, "Don't delete anything; shrink the database file to release the space of logs deleted or pruned earlier")
+	compact := clearCmd.Bool("compact", false, "Shrink the database file after deleting the matching logs")
+	filters := addFilterFlags(clearCmd)
+	clearCmd.Parse(args)
+	if clearCmd.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: Unexpected argument '%s'. Usage: ithena-cli logs clear [--status <s>] [--tool <t>] ... [--compact] | --compact-only\n", clearCmd.Arg(0))
+		os.Exit(1)
+	}
+	validateFilterFlags(filters)
+	if *compactOnly {
+		if filters.HasFilters() || *compact {
+			fmt.Fprintln(os.Stderr, "Error: --compact-only doesn't delete logs and can't be combined with filters or --compact.")
+			os.Exit(1)
+		}
+		initLocalStore(verbose, "logs clear")
+		compactLogStore()
+		return
+	}
+	if !filters.HasFilters() {
+		fmt.Fprintln(os.Stderr, "Error: No filter given. Pass at least one filter such as --status or --tool, or run plain 'ithena-cli logs clear' to delete all logs.")
+		os.Exit(1)
+	}
+
+	initLocalStore(verbose, "logs clear")
+	validateFilterFlags(filters)
+
+	matching, err := localstore.QueryLogs(*filters, 1, 1)
+	if err != nil {
+		log.Fatalf("Error counting matching logs: %v", err)
+	}
+	if matching.TotalCount == 0 {
+		fmt.Println("No matching logs found.")
+		return
+	}
+
+	dbPath, err := localstore.GetDefaultLogStorePathForInfo()
+	if err != nil {
+		dbPath = "(Could not determine path)"
+	}
+	fmt.Printf("This will delete %d log(s) matching the filters from: %s\n", matching.TotalCount, dbPath)
+	fmt.Print("Are you sure you want to continue? [y/N]: ")
+
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input != "y" && input != "yes" {
+		fmt.Println("Operation cancelled.")
+		return
+	}
+
+	deleted, err := localstore.DeleteLogs(*filters)
+	if err != nil {
+		log.Fatalf("Error deleting logs: %v", err)
+	}
+	fmt.Printf("Deleted %d log(s).\n", deleted)
+	if *compact {
+		compactLogStore()
+	}
+}
+
+// compactLogStore shrinks the database file, exiting with an error if it can't.
+func compactLogStore() {
+	if err := localstore.Compact(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to compact the local log database: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Compacted the local log database.")
+}
@@ -0,0 +1,93 @@
+package logs
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+)
+
+// HandleLogsStatsCommand handles the 'ithena-cli logs stats' command.
+func HandleLogsStatsCommand(verbose bool, args []string) {
+	statsCmd := flag.NewFlagSet("logs stats", flag.ExitOnError)
+	since := statsCmd.String("since", "", "Only include logs from this long ago until now (e.g. 24h, 7d)")
+	filters := addFilterFlags(statsCmd)
+	statsCmd.Parse(args)
+	validateFilterFlags(filters)
+
+	if *since != "" {
+		window, err := localstore.ParseAge(*since)
+		if err != nil || window <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --since value '%s' (expected e.g. 24h or 7d)\n", *since)
+			os.Exit(1)
+		}
+		filters.Since = time.Now().Add(-window)
+	}
+
+	initLocalStore(verbose, "logs stats")
+	validateFilterFlags(filters)
+
+	stats, err := localstore.GetStats(*filters)
+	if err != nil {
+		log.Fatalf("Error computing log stats: %v", err)
+	}
+	if stats.Total == 0 {
+		fmt.Println("No matching logs found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Total logs\t%d\n", stats.Total)
+	for _, sc := range stats.ByStatus {
+		fmt.Fprintf(tw, "  %s\t%d\t(%.1f%%)\n", sc.Status, sc.Count, 100*float64(sc.Count)/float64(stats.Total))
+	}
+	if len(stats.Percentiles) > 0 {
+		fmt.Fprintf(tw, "Avg duration\t%.1f ms\n", stats.AvgDurationMs)
+		for _, p := range stats.Percentiles {
+			fmt.Fprintf(tw, "p%g duration\t%d ms\n", p.Percent, p.DurationMs)
+		}
+		fmt.Fprintf(tw, "Max duration\t%d ms\n", stats.MaxDurationMs)
+	}
+	fmt.Fprintf(tw, "Bytes in\t%s\n", formatBytes(stats.RequestBytes))
+	fmt.Fprintf(tw, "Bytes out\t%s\n", formatBytes(stats.ResponseBytes))
+	tw.Flush()
+
+	if len(stats.TopTools) == 0 {
+		return
+	}
+	fmt.Println()
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOP TOOLS\tCOUNT\tBYTES IN\tBYTES OUT")
+	for _, v := range stats.TopTools {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", truncateForTable(v.Value, topValueMaxWidth), v.Count,
+			formatBytes(v.RequestBytes), formatBytes(v.ResponseBytes))
+	}
+	tw.Flush()
+
+	fmt.Println()
+	fmt.Printf("Tool call rates over %s:\n", formatWindow(stats.WindowMinutes))
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tCALLS\tPER MIN\tPEAK/MIN")
+	for _, rate := range stats.ToolRates {
+		perMinute := fmt.Sprintf("%.2f", rate.CallsPerMinute)
+		if rate.CallsPerMinute < 0.01 {
+			perMinute = "<0.01"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\n", truncateForTable(rate.Tool, topValueMaxWidth), rate.Calls, perMinute, rate.PeakPerMinute)
+	}
+	tw.Flush()
+}
+
+// formatWindow renders a stats window length, e.g. "45m" or "2d 3h".
+func formatWindow(minutes float64) string {
+	d := time.Duration(minutes * float64(time.Minute)).Round(time.Minute)
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd %dh", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	}
+	return strings.TrimSuffix(d.String(), "0s")
+}
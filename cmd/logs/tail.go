@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+const (
+	// tailPollInterval is how often 'logs tail' checks for new logs. Wrappers write from
+	// other processes, so the database has to be polled.
+	tailPollInterval = 1 * time.Second
+	// tailBatchLimit caps how many logs are read per poll; the rest follow immediately.
+	tailBatchLimit = 200
+)
+
+// HandleLogsTailCommand handles the 'ithena-cli logs tail' command. It prints logs as they
+// are stored until interrupted.
+func HandleLogsTailCommand(verbose bool, args []string) {
+	tailCmd := flag.NewFlagSet("logs tail", flag.ExitOnError)
+	filters := addFilterFlags(tailCmd)
+	tailCmd.StringVar(&filters.Status, "filter-status", "", "Same as --status")
+	tailCmd.Parse(args)
+
+	initLocalStore(verbose, "logs tail")
+	validateFilterFlags(filters)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cursor, err := localstore.LatestLogCursor()
+	if err != nil {
+		log.Fatalf("Error reading local logs: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "Waiting for new logs. Press Ctrl+C to stop.")
+
+	poll := time.NewTicker(tailPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		}
+		for {
+			records, next, err := localstore.LogsAfter(cursor, *filters, tailBatchLimit)
+			if err != nil {
+				log.Printf("Error reading new logs: %v", err)
+				break
+			}
+			cursor = next
+			for _, r := range records {
+				printTailLine(r)
+			}
+			if len(records) < tailBatchLimit {
+				break
+			}
+		}
+	}
+}
+
+// printTailLine prints one record as a compact line: time, alias, method, tool, status, duration.
+func printTailLine(r types.AuditRecord) {
+	fmt.Println(formatLogLine(r, nil))
+}
+
+// formatLogLine renders a record as a compact colorized line. Parts matching highlight, if
+// non-nil, are emphasized.
+func formatLogLine(r types.AuditRecord, highlight *regexp.Regexp) string {
+	duration := "-"
+	if r.DurationMs != nil {
+		duration = fmt.Sprintf("%dms", *r.DurationMs)
+	}
+	return fmt.Sprintf("%s  %s  %s  %s  %s  %s",
+		color.New(color.Faint).Sprint(formatLastSeen(r.Timestamp)),
+		highlightMatches(stringOrDash(r.TargetServerAlias), highlight, color.New(color.FgCyan)),
+		highlightMatches(stringOrDash(r.McpMethod), highlight, color.New(color.Reset)),
+		highlightMatches(stringOrDash(r.ToolName), highlight, color.New(color.Bold)),
+		statusColor(r.Status).Sprint(r.Status),
+		duration)
+}
+
+// statusColor matches the web UI: red for errors, yellow for timeouts and interruptions.
+func statusColor(status string) *color.Color {
+	switch status {
+	case types.StatusSuccess:
+		return color.New(color.FgGreen)
+	case types.StatusFailure, types.StatusRPCError, types.StatusTransportError:
+		return color.New(color.FgRed)
+	case types.StatusTimeout, types.StatusCancelled, types.StatusRestarted, types.StatusBinaryStream:
+		return color.New(color.FgYellow)
+	default:
+		return color.New(color.Reset)
+	}
+}
+
+func stringOrDash(s *string) string {
+	if s == nil || *s == "" {
+		return "-"
+	}
+	return *s
+}
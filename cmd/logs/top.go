@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+)
+
+// topValueMaxWidth truncates long values (typically error messages) so the table stays readable.
+const topValueMaxWidth = 80
+
+// HandleLogsTopCommand handles the 'ithena-cli logs top' command.
+func HandleLogsTopCommand(verbose bool, args []string) {
+	topCmd := flag.NewFlagSet("logs top", flag.ExitOnError)
+	by := topCmd.String("by", string(localstore.TopByError), "Value to rank: error, tool, method or status")
+	limit := topCmd.Int("n", 10, "Number of values to show")
+	since := topCmd.String("since", "", "Only include logs from this long ago until now (e.g. 24h, 7d)")
+	filters := addFilterFlags(topCmd)
+	topCmd.Parse(args)
+	validateFilterFlags(filters)
+
+	dimension := localstore.TopDimension(*by)
+	switch dimension {
+	case localstore.TopByError, localstore.TopByTool, localstore.TopByMethod, localstore.TopByStatus:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown value for --by '%s'. Supported values: error, tool, method, status\n", *by)
+		os.Exit(1)
+	}
+	if *since != "" {
+		window, err := localstore.ParseAge(*since)
+		if err != nil || window <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --since value '%s' (expected e.g. 24h or 7d)\n", *since)
+			os.Exit(1)
+		}
+		filters.Since = time.Now().Add(-window)
+	}
+
+	initLocalStore(verbose, "logs top")
+	validateFilterFlags(filters)
+
+	values, err := localstore.TopValues(dimension, *filters, *limit)
+	if err != nil {
+		log.Fatalf("Error querying top values: %v", err)
+	}
+	if len(values) == 0 {
+		fmt.Println("No matching logs found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "RANK\tCOUNT\tLAST SEEN\tBYTES IN\tBYTES OUT\t%s\n", strings.ToUpper(*by))
+	for i, v := range values {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\n", i+1, v.Count, formatLastSeen(v.LastSeen),
+			formatBytes(v.RequestBytes), formatBytes(v.ResponseBytes), truncateForTable(v.Value, topValueMaxWidth))
+	}
+	tw.Flush()
+}
+
+// formatLastSeen renders a stored UTC timestamp in local time, falling back to the raw value.
+func formatLastSeen(timestamp string) string {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// formatBytes renders a byte count with a binary unit suffix (e.g. "1.5 KiB").
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// truncateForTable flattens value onto one line and shortens it to at most maxWidth runes.
+func truncateForTable(value string, maxWidth int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	runes := []rune(value)
+	if len(runes) <= maxWidth {
+		return value
+	}
+	return string(runes[:maxWidth-3]) + "..."
+}
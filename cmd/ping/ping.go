@@ -0,0 +1,82 @@
+package ping
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/auth"
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+)
+
+// HandlePingCommand handles the 'ithena-cli ping' command. It sends one synthetic audit record
+// through the regular upload path and reports whether the platform accepted it.
+// defaultObserveUrl is the value of the global --observe-url flag.
+func HandlePingCommand(verbose bool, defaultObserveUrl string, args []string) {
+	pingCmd := flag.NewFlagSet("ping", flag.ExitOnError)
+	observeUrlFlag := pingCmd.String("observe-url", defaultObserveUrl, "URL for the observability API endpoint")
+	pingCmd.Parse(args)
+	observeUrl := *observeUrlFlag
+
+	token, err := auth.GetToken()
+	if err != nil || token == "" {
+		fmt.Fprintln(os.Stderr, "Ping failed: not authenticated.")
+		fmt.Fprintln(os.Stderr, "  Run 'ithena-cli auth' to log in, then try again.")
+		os.Exit(1)
+	}
+
+	if verbose { log.Printf("Pinging %s with a synthetic audit record...", observeUrl) }
+	latency, err := observability.Ping(observeUrl, token)
+	latency = latency.Round(time.Millisecond)
+	if err == nil {
+		fmt.Printf("OK: %s accepted a test record in %v.\n", observeUrl, latency)
+		return
+	}
+
+	var statusErr *observability.StatusError
+	if errors.As(err, &statusErr) {
+		fmt.Fprintf(os.Stderr, "Ping failed: %s responded with %s after %v.\n", observeUrl, statusErr.Status, latency)
+		if verbose && statusErr.Body != "" { log.Printf("Response body: %s", statusErr.Body) }
+	} else {
+		fmt.Fprintf(os.Stderr, "Ping failed after %v: %v\n", latency, err)
+	}
+	fmt.Fprintf(os.Stderr, "  %s\n", guidanceFor(err, statusErr))
+	os.Exit(1)
+}
+
+// guidanceFor suggests the most likely fix for a failed ping.
+func guidanceFor(err error, statusErr *observability.StatusError) string {
+	if statusErr != nil {
+		switch {
+		case statusErr.StatusCode == http.StatusForbidden && observability.OrgID() != "":
+			return fmt.Sprintf("Access was denied. Check that your account belongs to organization '%s' (--org-id or ITHENA_ORG_ID).", observability.OrgID())
+		case statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden:
+			return "Your token was rejected. Run 'ithena-cli auth logout' and then 'ithena-cli auth' to log in again."
+		case statusErr.StatusCode == http.StatusNotFound:
+			return "The endpoint was not found. Check the --observe-url value."
+		case statusErr.StatusCode == http.StatusRequestEntityTooLarge:
+			return "The platform rejected the payload size. Check whether a proxy in between limits request bodies."
+		case statusErr.StatusCode >= 500:
+			return "The platform reported a server error. Try again later; logs are kept locally meanwhile."
+		}
+		return "The platform did not accept the record. Run with --verbose to see the response body."
+	}
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "Connection refused. Check the --observe-url value and any HTTP(S)_PROXY settings."
+	case errors.As(err, &dnsErr):
+		return "The host name could not be resolved. Check the --observe-url value and your DNS or proxy settings."
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "The request timed out. Check your network connection and any HTTP(S)_PROXY settings."
+	}
+	return "Check the --observe-url value, your network connection and any HTTP(S)_PROXY settings."
+}
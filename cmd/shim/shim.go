@@ -0,0 +1,142 @@
+package shim
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// HandleShimGenerateCommand handles 'ithena-cli shim generate <command> [ithena-cli flags...]'.
+// It writes a script that runs the real command under ithena-cli, forwarding every argument.
+// Both binaries are resolved to absolute paths when the shim is generated, so the shim can sit
+// ahead of the real command on PATH, under the same name, without calling itself.
+func HandleShimGenerateCommand(verbose bool, args []string) {
+	generateCmd := flag.NewFlagSet("shim generate", flag.ExitOnError)
+	output := generateCmd.String("output", "", "Write the shim to this file and make it executable (default: print it)")
+	force := generateCmd.Bool("force", false, "Overwrite --output if it exists")
+	ithenaPath := generateCmd.String("ithena", "", "Path of the ithena-cli binary the shim runs (default: this binary)")
+	commandPath := generateCmd.String("command-path", "", "Path of the real command (default: looked up on PATH)")
+	generateCmd.Parse(args)
+
+	if generateCmd.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Error: Expected a command. Usage: ithena-cli shim generate [--output <path>] <command> [ithena-cli flags...]")
+		os.Exit(1)
+	}
+	command := generateCmd.Arg(0)
+	ithenaFlags := generateCmd.Args()[1:]
+
+	realCommand, err := resolveBinary(*commandPath, command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot find '%s': %v. Use --command-path to point at it.\n", command, err)
+		os.Exit(1)
+	}
+	ithena := *ithenaPath
+	if ithena == "" {
+		if ithena, err = os.Executable(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot locate the ithena-cli binary: %v. Use --ithena to point at it.\n", err)
+			os.Exit(1)
+		}
+	}
+	if ithena, err = filepath.Abs(ithena); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if verbose { log.Printf("Generating shim for '%s' (resolved to %s) running %s", command, realCommand, ithena) }
+
+	script := shimScript(command, realCommand, ithena, ithenaFlags)
+	if *output == "" {
+		fmt.Print(script)
+		return
+	}
+
+	if sameFile(*output, realCommand) {
+		fmt.Fprintf(os.Stderr, "Error: --output %s is the real command itself; write the shim to a directory earlier on PATH instead.\n", *output)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(*output); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists. Use --force to overwrite it.\n", *output)
+		os.Exit(1)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, []byte(script), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write shim: %v\n", err)
+		os.Exit(1)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(*output, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to make shim executable: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote shim for '%s' to %s.\n", command, *output)
+	fmt.Printf("Put %s ahead of %s on PATH to log every run of '%s'.\n", filepath.Dir(*output), filepath.Dir(realCommand), command)
+}
+
+// resolveBinary returns the absolute path of the command to run: explicit if set, otherwise
+// command looked up on PATH.
+func resolveBinary(explicit, command string) (string, error) {
+	path := explicit
+	if path == "" {
+		var err error
+		if path, err = exec.LookPath(command); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Abs(path)
+}
+
+// sameFile reports whether a and b name the same existing file.
+func sameFile(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// shimScript returns a POSIX shell script, or a batch file on Windows, that runs realCommand
+// under ithena and passes its exit status through. The "--" keeps ithena-cli from reading the
+// command's own arguments as flags.
+func shimScript(command, realCommand, ithena string, ithenaFlags []string) string {
+	if runtime.GOOS == "windows" {
+		var b strings.Builder
+		fmt.Fprintf(&b, "@echo off\r\nrem Generated by 'ithena-cli shim generate %s'. Runs %s under ithena-cli.\r\n", command, realCommand)
+		fmt.Fprintf(&b, "%s", batchQuote(ithena))
+		for _, f := range ithenaFlags {
+			fmt.Fprintf(&b, " %s", batchQuote(f))
+		}
+		fmt.Fprintf(&b, " -- %s %%*\r\nexit /b %%ERRORLEVEL%%\r\n", batchQuote(realCommand))
+		return b.String()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#!/bin/sh\n# Generated by 'ithena-cli shim generate %s'. Runs %s under ithena-cli.\n", command, realCommand)
+	fmt.Fprintf(&b, "exec %s", shellQuote(ithena))
+	for _, f := range ithenaFlags {
+		fmt.Fprintf(&b, " %s", shellQuote(f))
+	}
+	fmt.Fprintf(&b, " -- %s \"$@\"\n", shellQuote(realCommand))
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// batchQuote quotes s for a Windows batch file.
+func batchQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
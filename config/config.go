@@ -2,7 +2,7 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,20 +14,135 @@ type WrapperProfile struct {
 	Args    []string          `yaml:"args"`
 	Env     map[string]string `yaml:"env"` // Placeholders like {{env:VAR}}, {{keyring:svc:acc}}, {{file:path}}
 	Alias   string            `yaml:"alias,omitempty"`
+
+	// Extends names a profile whose command, args, env and alias this one inherits; fields set
+	// here override them, and env entries are merged. AppendArgs appends Args to the inherited
+	// args instead of replacing them.
+	Extends    string `yaml:"extends,omitempty"`
+	AppendArgs bool   `yaml:"append_args,omitempty"`
+
+	// Transport selects how the wrapper talks to the MCP server: "stdio" (default) spawns
+	// Command, "sse" connects to URL using the HTTP+SSE transport instead.
+	Transport string `yaml:"transport,omitempty"`
+	URL       string `yaml:"url,omitempty"`
+
+	// Framing selects how stdio messages are delimited: "newline" (default) or
+	// "content-length" for LSP-style "Content-Length: N" headers.
+	Framing string `yaml:"framing,omitempty"`
+
+	// ObserveURL sends this profile's records to its own endpoint instead of --observe-url.
+	// Offline keeps them local-only: nothing is uploaded, even when authenticated.
+	ObserveURL string `yaml:"observe_url,omitempty"`
+	Offline    bool   `yaml:"offline,omitempty"`
+
+	// Restart set to "on-failure" starts the stdio command again when it exits with a non-zero
+	// status, up to MaxRestarts times (default 5, -1 for no limit), waiting RestartBackoff in between.
+	Restart        string               `yaml:"restart,omitempty"`
+	MaxRestarts    int                  `yaml:"max_restarts,omitempty"`
+	RestartBackoff RestartBackoffConfig `yaml:"restart_backoff,omitempty"`
+
+	// RequestTimeout is how long a request may go unanswered before it is logged with status
+	// "timeout" (e.g. "2m"). Defaults to ITHENA_REQUEST_TIMEOUT or 10 minutes.
+	RequestTimeout time.Duration `yaml:"request_timeout,omitempty"`
+
+	// CaptureStderr also records the stdio command's stderr in the local logs: "tail" attaches
+	// the last lines to the record of a crash, "lines" records every line. "off" by default.
+	CaptureStderr string `yaml:"capture_stderr,omitempty"`
+
+	// Streaming keeps requests of the listed methods correlated across several response
+	// frames with the same ID, until one has an error or true at DonePointer.
+	Streaming StreamingConfig `yaml:"streaming,omitempty"`
+
+	// RecordNotifications records JSON-RPC notifications in both directions with status
+	// "notification"; by default they are only logged with --verbose.
+	RecordNotifications bool `yaml:"record_notifications,omitempty"`
+
+	// Backends runs several stdio commands behind one wrapper instead of Command, routing each
+	// client message to one of them by method. The first backend gets requests no pattern matches.
+	Backends []BackendConfig `yaml:"backends,omitempty"`
+}
+
+// BackendConfig is one of the commands a profile with several backends runs. Its records are
+// tagged with Alias; Env is applied over the profile's env.
+type BackendConfig struct {
+	Alias   string            `yaml:"alias"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Methods []string          `yaml:"methods,omitempty"` // e.g. "files/read"; a trailing "*" matches by prefix
+}
+
+// StreamingConfig lists methods whose responses may arrive as several frames.
+type StreamingConfig struct {
+	Methods     []string `yaml:"methods,omitempty"`      // e.g. "tools/call"; a trailing "*" matches by prefix
+	DonePointer string   `yaml:"done_pointer,omitempty"` // JSON pointer into each frame; default "/result/done"
+}
+
+// RestartBackoffConfig bounds the delay before each restart, which doubles every time.
+type RestartBackoffConfig struct {
+	Initial time.Duration `yaml:"initial,omitempty"` // Delay before the first restart, e.g. "1s" (default)
+	Max     time.Duration `yaml:"max,omitempty"`     // Upper bound for the delay, e.g. "30s" (default)
 }
 
 // WrapperConfig defines the top-level structure of the YAML configuration file.
 // It contains a map of profile names to their definitions.
 type WrapperConfig struct {
 	Wrappers map[string]WrapperProfile `yaml:"wrappers"`
+
+	// LocalOnlyMethods lists MCP methods whose records are always stored locally and never
+	// uploaded, even when authenticated. A trailing "*" matches by prefix (e.g. "resources/*").
+	LocalOnlyMethods []string `yaml:"local_only_methods,omitempty"`
+
+	// ToolNameFields maps MCP methods to the request param holding the tool name, for servers
+	// that don't follow MCP's tools/call convention. Dotted paths reach into nested params.
+	// Deprecated: use ToolExtraction, which takes precedence where both match a method.
+	ToolNameFields map[string]string `yaml:"tool_name_fields,omitempty"`
+
+	// ToolExtraction lists rules for finding the tool name and arguments in request params,
+	// checked in order before ToolNameFields and the built-in MCP defaults.
+	ToolExtraction []ToolExtractionRule `yaml:"tool_extraction,omitempty"`
+
+	// Transforms rewrites request/response previews before they are stored or uploaded.
+	// With --watch-config, edits to this section apply to running wrappers without a restart.
+	Transforms TransformConfig `yaml:"transforms,omitempty"`
+
+	// IndexedFields promotes values inside the stored JSON to indexed columns of the local log
+	// database, for filtering with --field. Omitting the key leaves existing columns alone;
+	// an empty list removes them.
+	IndexedFields []IndexedField `yaml:"indexed_fields,omitempty"`
 }
 
-// LoadWrapperConfig reads the specified YAML file and parses it into WrapperConfig struct.
+// IndexedField names a value inside a log's request, response, error or tool args by
+// JSON pointer.
+type IndexedField struct {
+	Name    string `yaml:"name"`
+	Source  string `yaml:"source"`  // request, response, error or tool_args
+	Pointer string `yaml:"pointer"` // e.g. "/_meta/request_id"
+}
+
+// ToolExtractionRule maps a method (exact, or a prefix when it ends in "*") to JSON pointers
+// locating the tool name and arguments in its request params.
+type ToolExtractionRule struct {
+	Method   string `yaml:"method"`
+	ToolName string `yaml:"tool_name,omitempty"` // e.g. "/name"
+	ToolArgs string `yaml:"tool_args,omitempty"` // e.g. "/arguments"
+}
+
+// TransformConfig lists the preview transforms to apply to every record.
+type TransformConfig struct {
+	SanitizePaths     bool     `yaml:"sanitize_paths,omitempty"`      // Replace the home directory with "~"
+	StripPathPrefixes []string `yaml:"strip_path_prefixes,omitempty"` // Remove these path prefixes
+	RedactKeys        []string `yaml:"redact_keys,omitempty"`         // Replace values of these object keys with "[REDACTED]"
+	HashKeys          []string `yaml:"hash_keys,omitempty"`           // Replace values of these object keys with a keyed hash (HMAC-SHA256, truncated)
+}
+
+// LoadWrapperConfig reads the specified YAML file, or JSON file if its name ends in ".json",
+// and parses it into WrapperConfig struct.
 func LoadWrapperConfig(filePath string) (*WrapperConfig, error) {
-	// Read the YAML file content
-	yamlFile, err := os.ReadFile(filePath)
+	// Read the file content, as YAML
+	yamlFile, err := readConfigFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read wrapper config file '%s': %w", filePath, err)
+		return nil, err
 	}
 
 	// Parse the YAML content
@@ -44,6 +159,9 @@ func LoadWrapperConfig(filePath string) (*WrapperConfig, error) {
 		// Optionally log a warning or return an error if the structure is mandatory
 		// log.Printf("Warning: Wrapper config file '%s' is missing 'wrappers' map.", filePath)
 	}
+	if err := resolveExtends(config.Wrappers); err != nil {
+		return nil, fmt.Errorf("invalid wrapper config file '%s': %w", filePath, err)
+	}
 
 	return &config, nil
 } 
\ No newline at end of file
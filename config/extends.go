@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resolveExtends applies the 'extends' field of every profile: a profile starts from the
+// command, args, env and alias of the profile it extends, after that one's own 'extends' has
+// been applied, and overrides them with its own. Unknown base profiles and cycles are errors.
+func resolveExtends(profiles map[string]WrapperProfile) error {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report the same problem on every run
+
+	resolved := make(map[string]bool, len(profiles))
+	for _, name := range names {
+		if err := resolveProfileExtends(profiles, name, resolved, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveProfileExtends resolves the profile name, and the profiles it extends first. chain
+// holds the profiles whose resolution is waiting on this one.
+func resolveProfileExtends(profiles map[string]WrapperProfile, name string, resolved map[string]bool, chain []string) error {
+	if resolved[name] {
+		return nil
+	}
+	for i, waiting := range chain {
+		if waiting == name {
+			return fmt.Errorf("profiles extend each other in a cycle: %s", strings.Join(append(chain[i:], name), " -> "))
+		}
+	}
+
+	profile := profiles[name]
+	if profile.Extends != "" {
+		if _, ok := profiles[profile.Extends]; !ok {
+			return fmt.Errorf("profile '%s' extends unknown profile '%s'", name, profile.Extends)
+		}
+		if err := resolveProfileExtends(profiles, profile.Extends, resolved, append(chain, name)); err != nil {
+			return err
+		}
+		profiles[name] = mergeProfiles(profiles[profile.Extends], profile)
+	}
+	resolved[name] = true
+	return nil
+}
+
+// mergeProfiles returns child with the command, args, env and alias it doesn't set taken
+// from base. Env entries are merged, the child's winning; args are replaced when the child
+// sets any, or appended to the base's with append_args.
+func mergeProfiles(base, child WrapperProfile) WrapperProfile {
+	merged := child
+	if merged.Command == "" && len(child.Backends) == 0 {
+		merged.Command = base.Command
+	}
+	if merged.Alias == "" {
+		merged.Alias = base.Alias
+	}
+	switch {
+	case child.AppendArgs:
+		merged.Args = append(append([]string{}, base.Args...), child.Args...)
+	case child.Args == nil:
+		merged.Args = append([]string(nil), base.Args...)
+	}
+	if len(base.Env) > 0 {
+		env := make(map[string]string, len(base.Env)+len(child.Env))
+		for key, value := range base.Env {
+			env[key] = value
+		}
+		for key, value := range child.Env {
+			env[key] = value
+		}
+		merged.Env = env
+	}
+	return merged
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readConfigFile reads a wrapper config file as YAML. Files ending in ".json" are converted
+// with jsonToYAML first, so both formats decode into the same types with the same defaults.
+func readConfigFile(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wrapper config file '%s': %w", filePath, err)
+	}
+	if !strings.EqualFold(filepath.Ext(filePath), ".json") {
+		return data, nil
+	}
+	converted, err := jsonToYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wrapper config file '%s': %w", filePath, err)
+	}
+	return converted, nil
+}
+
+// jsonToYAML rewrites a JSON document as the equivalent YAML flow document, line for line, so
+// line numbers in decoding errors still point into the JSON file. JSON is almost YAML already:
+// only its strings, whose escapes (e.g. "\/" or surrogate pairs) YAML doesn't all accept, and
+// tabs are rewritten. An empty document stays empty, like an empty YAML file.
+func jsonToYAML(data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return data, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("line %d: %w", bytes.Count(data[:syntaxErr.Offset], []byte("\n"))+1, err)
+		}
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '\t':
+			out = append(out, ' ')
+		case '"':
+			end := i + 1
+			for data[end] != '"' {
+				if data[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			var s string
+			if err := json.Unmarshal(data[i:end+1], &s); err != nil {
+				return nil, err
+			}
+			out = append(out, strconv.Quote(s)...) // Go's escapes are all valid in YAML double-quoted strings
+			i = end
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// profileOverridePrefix starts the name of every environment variable that overrides a
+// profile field: ITHENA_PROFILE_<PROFILE>_<FIELD>, e.g. ITHENA_PROFILE_MYSERVER_COMMAND.
+const profileOverridePrefix = "ITHENA_PROFILE_"
+
+// profileOverrideFields lists the field names accepted after the profile name. ENV_<NAME>
+// additionally sets (or replaces) one entry of the profile's env map.
+var profileOverrideFields = []string{"COMMAND", "ARGS", "ALIAS", "TRANSPORT", "URL", "FRAMING", "RESTART", "MAX_RESTARTS", "CAPTURE_STDERR", "REQUEST_TIMEOUT", "OBSERVE_URL", "OFFLINE"}
+
+// profileEnvOverridePrefix is the field prefix that overrides a single env entry.
+const profileEnvOverridePrefix = "ENV_"
+
+// profileOverrideName returns the environment variable prefix for a profile's overrides.
+// The profile name is upper-cased and anything but letters and digits becomes "_", so
+// profile "my-server" is overridden with ITHENA_PROFILE_MY_SERVER_<FIELD>.
+func profileOverrideName(profileName string) string {
+	var b strings.Builder
+	b.WriteString(profileOverridePrefix)
+	for _, r := range strings.ToUpper(profileName) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	b.WriteRune('_')
+	return b.String()
+}
+
+// ApplyProfileOverrides applies the ITHENA_PROFILE_<PROFILE>_<FIELD> variables in environ
+// (os.Environ() format) to profile and returns the names of the variables it applied.
+// Variable names are matched case-insensitively. ARGS takes a JSON array of strings, or
+// whitespace-separated arguments. otherProfiles are the names of the config's other
+// profiles, so variables meant for a profile whose name extends this one (e.g. "db" and
+// "db-replica") are not mistaken for unknown fields.
+func ApplyProfileOverrides(profileName string, profile *WrapperProfile, environ []string, otherProfiles []string) ([]string, error) {
+	prefix := profileOverrideName(profileName)
+	var otherPrefixes []string
+	for _, name := range otherProfiles {
+		if other := profileOverrideName(name); name != profileName && strings.HasPrefix(other, prefix) {
+			otherPrefixes = append(otherPrefixes, other)
+		}
+	}
+
+	var applied []string
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(strings.ToUpper(name), prefix) {
+			continue
+		}
+		if belongsToOther(strings.ToUpper(name), otherPrefixes) {
+			continue
+		}
+		field := name[len(prefix):]
+		if err := applyProfileOverride(profile, field, value); err != nil {
+			return applied, fmt.Errorf("invalid override %s: %w", name, err)
+		}
+		applied = append(applied, name)
+	}
+	sort.Strings(applied)
+	return applied, nil
+}
+
+func belongsToOther(name string, otherPrefixes []string) bool {
+	for _, other := range otherPrefixes {
+		if strings.HasPrefix(name, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfileOverride sets one profile field from its override value.
+func applyProfileOverride(profile *WrapperProfile, field, value string) error {
+	upper := strings.ToUpper(field)
+	if strings.HasPrefix(upper, profileEnvOverridePrefix) && len(field) > len(profileEnvOverridePrefix) {
+		// The env key keeps its original case, since env names are case-sensitive.
+		if profile.Env == nil {
+			profile.Env = make(map[string]string)
+		}
+		profile.Env[field[len(profileEnvOverridePrefix):]] = value
+		return nil
+	}
+
+	switch upper {
+	case "COMMAND":
+		profile.Command = value
+	case "ARGS":
+		args, err := parseOverrideArgs(value)
+		if err != nil {
+			return err
+		}
+		profile.Args = args
+	case "ALIAS":
+		profile.Alias = value
+	case "TRANSPORT":
+		profile.Transport = value
+	case "URL":
+		profile.URL = value
+	case "FRAMING":
+		profile.Framing = value
+	case "RESTART":
+		profile.Restart = value
+	case "OBSERVE_URL":
+		profile.ObserveURL = value
+	case "OFFLINE":
+		offline, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected true or false, got '%s'", value)
+		}
+		profile.Offline = offline
+	case "REQUEST_TIMEOUT":
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 2m, got '%s'", value)
+		}
+		profile.RequestTimeout = d
+	case "CAPTURE_STDERR":
+		profile.CaptureStderr = value
+	case "MAX_RESTARTS":
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected an integer, got '%s'", value)
+		}
+		profile.MaxRestarts = n
+	default:
+		return fmt.Errorf("unknown field '%s' (supported: %s, ENV_<NAME>)", field, strings.Join(profileOverrideFields, ", "))
+	}
+	return nil
+}
+
+// parseOverrideArgs reads ARGS as a JSON array of strings when it starts with "[",
+// otherwise as whitespace-separated arguments.
+func parseOverrideArgs(value string) ([]string, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(trimmed), &args); err != nil {
+			return nil, fmt.Errorf("expected a JSON array of strings: %w", err)
+		}
+		return args, nil
+	}
+	return strings.Fields(trimmed), nil
+}
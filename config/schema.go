@@ -0,0 +1,145 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaDraft is the JSON schema dialect of WrapperConfigSchema; editors support it most widely.
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// durationPattern matches the Go duration strings accepted for time.Duration fields, e.g. "1m30s".
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// schemaAnnotation holds what reflection can't tell about a field. Keys of schemaAnnotations
+// are YAML paths, with "*" standing for any profile name or list item.
+type schemaAnnotation struct {
+	Description string
+	Enum        []string
+	Required    bool // The key must be present in its object; omitempty can't tell, as LoadWrapperConfig accepts a file without wrappers
+}
+
+var schemaAnnotations = map[string]schemaAnnotation{
+	"wrappers":                           {Description: "Profiles by name; the name is used with --wrapper-profile."},
+	"wrappers.*.command":                 {Description: "Command that starts the MCP server (stdio transport)."},
+	"wrappers.*.args":                    {Description: "Arguments for the command."},
+	"wrappers.*.env":                     {Description: "Extra environment for the command. Values may use {{env:VAR}} (or {{env:VAR:-fallback}}), {{keyring:service:account}}, {{file:path}} and {{exec:command}} placeholders."},
+	"wrappers.*.alias":                   {Description: "Server alias recorded in the logs; defaults to the command."},
+	"wrappers.*.extends":                 {Description: "Profile whose command, args, env and alias this one inherits; its own fields override them and env entries are merged."},
+	"wrappers.*.append_args":             {Description: "With extends, append args to the inherited args instead of replacing them."},
+	"wrappers.*.transport":               {Description: "How to reach the server.", Enum: []string{"stdio", "sse"}},
+	"wrappers.*.url":                     {Description: "Server URL for the sse transport."},
+	"wrappers.*.framing":                 {Description: "Message framing on the server's stdio.", Enum: []string{"newline", "content-length"}},
+	"wrappers.*.observe_url":             {Description: "Observability endpoint for this profile's logs."},
+	"wrappers.*.offline":                 {Description: "Keep this profile's logs local, never uploading them."},
+	"wrappers.*.restart":                 {Description: "Whether to restart the server when it exits with an error.", Enum: []string{"no", "on-failure"}},
+	"wrappers.*.max_restarts":            {Description: "Restarts allowed before giving up; default 5 when unset or 0, -1 for no limit."},
+	"wrappers.*.request_timeout":         {Description: "Record requests without a response after this long as timed out, e.g. \"30s\"."},
+	"wrappers.*.capture_stderr":          {Description: "What to record from the server's stderr.", Enum: []string{"off", "tail", "lines"}},
+	"wrappers.*.streaming":               {Description: "Methods answered with several response frames per request."},
+	"wrappers.*.streaming.methods":       {Description: "Method names; a trailing \"*\" matches by prefix."},
+	"wrappers.*.streaming.done_pointer":  {Description: "JSON pointer to the completion flag in each frame; defaults to \"/result/done\"."},
+	"wrappers.*.record_notifications":    {Description: "Also record notifications in both directions, with status \"notification\"."},
+	"wrappers.*.backends":                {Description: "Several stdio servers behind one wrapper, instead of command; each client message goes to one of them by method."},
+	"wrappers.*.backends.*.alias":        {Description: "Server alias recorded in the logs of this backend.", Required: true},
+	"wrappers.*.backends.*.command":      {Description: "Command that starts this backend.", Required: true},
+	"wrappers.*.backends.*.env":          {Description: "Environment for this backend, applied over the profile's env; placeholders as in env."},
+	"wrappers.*.backends.*.methods":      {Description: "Methods routed to this backend; a trailing \"*\" matches by prefix. The first backend gets the rest."},
+	"wrappers.*.restart_backoff.initial": {Description: "Delay before the first restart; defaults to \"1s\"."},
+	"wrappers.*.restart_backoff.max":     {Description: "Upper bound for the restart delay; defaults to \"30s\"."},
+	"local_only_methods":                 {Description: "Methods whose records are kept local, never uploaded."},
+	"tool_name_fields":                   {Description: "Deprecated: use tool_extraction. Parameter field holding the tool name, by method."},
+	"tool_extraction":                    {Description: "JSON pointers locating tool names and arguments, by method."},
+	"tool_extraction.*.method":           {Required: true},
+	"transforms":                         {Description: "Rewrites applied to payloads before they are logged."},
+	"indexed_fields":                     {Description: "Payload values indexed for --field filters."},
+	"indexed_fields.*.name":              {Required: true},
+	"indexed_fields.*.source":            {Enum: []string{"request", "response", "error", "tool_args"}, Required: true},
+	"indexed_fields.*.pointer":           {Description: "JSON pointer into the source payload.", Required: true},
+	"transforms.redact_keys":             {Description: "Replace values of these object keys with \"[REDACTED]\"."},
+	"transforms.hash_keys":               {Description: "Replace values of these object keys with a keyed hash (HMAC-SHA256, truncated to 64 bits)."},
+	"transforms.sanitize_paths":          {Description: "Replace the home directory with \"~\"."},
+	"transforms.strip_path_prefixes":     {Description: "Remove these prefixes from paths."},
+}
+
+// WrapperConfigSchema returns a JSON schema for the wrapper configuration file. It is built
+// from the WrapperConfig struct and its yaml tags, so new fields show up without changes here;
+// schemaAnnotations only adds descriptions, allowed values and required keys.
+func WrapperConfigSchema() map[string]interface{} {
+	schema := schemaFor(reflect.TypeOf(WrapperConfig{}), "")
+	schema["$schema"] = schemaDraft
+	schema["title"] = "ithena-cli wrapper configuration"
+	return schema
+}
+
+// schemaFor describes values of type t found at path.
+func schemaFor(t reflect.Type, path string) map[string]interface{} {
+	var schema map[string]interface{}
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		schema = map[string]interface{}{"type": "string", "pattern": durationPattern}
+	case t.Kind() == reflect.Struct:
+		schema = structSchema(t, path)
+	case t.Kind() == reflect.Map:
+		schema = map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), joinSchemaPath(path, "*"))}
+	case t.Kind() == reflect.Slice:
+		schema = map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), joinSchemaPath(path, "*"))}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = map[string]interface{}{"type": "integer"}
+	default:
+		schema = map[string]interface{}{"type": "string"}
+	}
+
+	if annotation, ok := schemaAnnotations[path]; ok {
+		if annotation.Description != "" {
+			schema["description"] = annotation.Description
+		}
+		if len(annotation.Enum) > 0 {
+			schema["enum"] = annotation.Enum
+		}
+	}
+	return schema
+}
+
+// structSchema describes a struct by its yaml tags. Fields are required only where
+// schemaAnnotations says so.
+func structSchema(t reflect.Type, path string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		name, _, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fieldPath := joinSchemaPath(path, name)
+		properties[name] = schemaFor(field.Type, fieldPath)
+		if schemaAnnotations[fieldPath].Required {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
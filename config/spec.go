@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CommandSpec describes a single command to wrap, as a JSON document. Unlike the YAML
+// profiles it holds exactly one server, so IDEs and other tools can generate it on the fly.
+//
+//	{"command": "npx", "args": ["-y", "server"], "env": {"TOKEN": "{{env:TOKEN}}"}, "cwd": "/work"}
+type CommandSpec struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`   // Same placeholders as WrapperProfile.Env
+	Cwd     string            `json:"cwd,omitempty"`   // Working directory for the command; defaults to the current one
+	Alias   string            `json:"alias,omitempty"` // Defaults to Command
+	Framing string            `json:"framing,omitempty"`
+
+	CaptureStderr string `json:"capture_stderr,omitempty"` // Same as WrapperProfile.CaptureStderr
+}
+
+// LoadCommandSpec reads and validates a JSON command spec file.
+func LoadCommandSpec(filePath string) (*CommandSpec, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command spec file '%s': %w", filePath, err)
+	}
+
+	var spec CommandSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse command spec file '%s': %w", filePath, err)
+	}
+	if spec.Command == "" {
+		return nil, fmt.Errorf("command spec file '%s' has no 'command'", filePath)
+	}
+	if spec.Cwd != "" {
+		info, err := os.Stat(spec.Cwd)
+		if err != nil {
+			return nil, fmt.Errorf("command spec file '%s': invalid 'cwd': %w", filePath, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("command spec file '%s': 'cwd' %s is not a directory", filePath, spec.Cwd)
+		}
+	}
+	if spec.Alias == "" {
+		spec.Alias = spec.Command
+	}
+	return &spec, nil
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/placeholder"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one finding of ValidateWrapperConfig. Fatal problems would make the profile
+// fail or behave differently than written; the rest are warnings.
+type Problem struct {
+	Profile string // Empty for problems outside any profile
+	Message string
+	Fatal   bool
+}
+
+// ValidateWrapperConfig loads the config file like LoadWrapperConfig and checks each profile
+// without running anything: unknown fields, missing commands, placeholder syntax in env,
+// duplicate aliases and indexed field definitions. An error is returned only when the file cannot be read or parsed at all,
+// or a profile extends an unknown profile or itself.
+func ValidateWrapperConfig(filePath string) (*WrapperConfig, []Problem, error) {
+	config, err := LoadWrapperConfig(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := readConfigFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	problems := unknownFieldProblems(data)
+
+	names := make([]string, 0, len(config.Wrappers))
+	for name := range config.Wrappers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	aliasOwners := make(map[string][]string)
+	for _, name := range names {
+		profile := config.Wrappers[name]
+		problems = append(problems, validateProfile(name, profile)...)
+		if profile.Alias != "" {
+			aliasOwners[profile.Alias] = append(aliasOwners[profile.Alias], name)
+		}
+	}
+	for _, field := range config.IndexedFields {
+		if err := localstore.ValidateIndexedField(localstore.IndexedField{Name: field.Name, Source: field.Source, Pointer: field.Pointer}); err != nil {
+			problems = append(problems, Problem{Message: fmt.Sprintf("indexed_fields: %v", err), Fatal: true})
+		}
+	}
+	for _, name := range names {
+		profile := config.Wrappers[name]
+		alias := profile.Alias
+		owners := aliasOwners[alias]
+		if len(owners) <= 1 {
+			continue
+		}
+		message := fmt.Sprintf("alias '%s' is also used by %s; their logs can't be told apart", alias, otherProfiles(owners, name))
+		if profile.Extends != "" && config.Wrappers[profile.Extends].Alias == alias {
+			// Extending a profile copies its alias unless this one sets its own.
+			message = fmt.Sprintf("alias '%s' is inherited from '%s'; set alias in this profile so its logs can be told apart from those of %s", alias, profile.Extends, otherProfiles(owners, name))
+		}
+		problems = append(problems, Problem{Profile: name, Message: message})
+	}
+	return config, problems, nil
+}
+
+// validateProfile checks the fields of a single profile.
+func validateProfile(name string, profile WrapperProfile) []Problem {
+	var problems []Problem
+	add := func(fatal bool, format string, args ...interface{}) {
+		problems = append(problems, Problem{Profile: name, Message: fmt.Sprintf(format, args...), Fatal: fatal})
+	}
+
+	switch profile.Transport {
+	case "", "stdio":
+		if len(profile.Backends) > 0 {
+			if strings.TrimSpace(profile.Command) != "" {
+				add(true, "command and backends can't both be set")
+			}
+			problems = append(problems, validateBackends(name, profile)...)
+		} else if strings.TrimSpace(profile.Command) == "" {
+			add(true, "command is empty")
+		} else if _, err := exec.LookPath(profile.Command); err != nil {
+			add(false, "command '%s' was not found: %v", profile.Command, err)
+		}
+	case "sse":
+		if strings.TrimSpace(profile.URL) == "" {
+			add(true, "url is required with transport 'sse'")
+		}
+		if len(profile.Backends) > 0 {
+			add(true, "backends require transport 'stdio'")
+		}
+	default:
+		add(true, "unknown transport '%s' (expected 'stdio' or 'sse')", profile.Transport)
+	}
+	if profile.AppendArgs && profile.Extends == "" {
+		add(false, "append_args has no effect without extends")
+	}
+	if pointer := profile.Streaming.DonePointer; pointer != "" && !strings.HasPrefix(pointer, "/") {
+		add(true, "streaming.done_pointer '%s' must be a JSON pointer starting with '/'", pointer)
+	}
+
+	problems = append(problems, envProblems(name, "env", profile.Env)...)
+	return problems
+}
+
+// validateBackends checks the backends of a profile that has several.
+func validateBackends(name string, profile WrapperProfile) []Problem {
+	var problems []Problem
+	add := func(fatal bool, format string, args ...interface{}) {
+		problems = append(problems, Problem{Profile: name, Message: fmt.Sprintf(format, args...), Fatal: fatal})
+	}
+
+	if profile.Restart != "" && profile.Restart != "no" {
+		add(true, "restart is not supported with backends")
+	}
+	aliases := make(map[string]bool)
+	patterns := make(map[string]string)
+	for i, backend := range profile.Backends {
+		field := fmt.Sprintf("backends[%d]", i)
+		if strings.TrimSpace(backend.Alias) == "" {
+			add(true, "%s: alias is empty", field)
+		} else if aliases[backend.Alias] {
+			add(true, "%s: alias '%s' is used by another backend", field, backend.Alias)
+		}
+		aliases[backend.Alias] = true
+		if strings.TrimSpace(backend.Command) == "" {
+			add(true, "%s: command is empty", field)
+		} else if _, err := exec.LookPath(backend.Command); err != nil {
+			add(false, "%s: command '%s' was not found: %v", field, backend.Command, err)
+		}
+		for _, pattern := range backend.Methods {
+			if owner, ok := patterns[pattern]; ok {
+				add(true, "%s: method '%s' is also routed to backend '%s'", field, pattern, owner)
+			}
+			patterns[pattern] = backend.Alias
+		}
+		problems = append(problems, envProblems(name, field+".env", backend.Env)...)
+	}
+	return problems
+}
+
+// envProblems lints the placeholders in an env map; field prefixes the key in messages.
+func envProblems(name string, field string, env map[string]string) []Problem {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var problems []Problem
+	for _, key := range keys {
+		for _, issue := range placeholder.LintPlaceholders(env[key]) {
+			problems = append(problems, Problem{Profile: name, Message: fmt.Sprintf("%s.%s: %s", field, key, issue.Message), Fatal: !issue.Warning})
+		}
+	}
+	return problems
+}
+
+// unknownFieldProblems decodes the file strictly and reports every field the config types
+// don't know, usually a typo that would otherwise be silently ignored. Problems inside a
+// profile are attributed to it.
+func unknownFieldProblems(data []byte) []Problem {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var strict WrapperConfig
+	err := dec.Decode(&strict)
+
+	var typeErr *yaml.TypeError
+	if err == nil || !errors.As(err, &typeErr) {
+		return nil
+	}
+	starts := profileStartLines(data)
+	var problems []Problem
+	for _, msg := range typeErr.Errors {
+		problem := Problem{Message: msg, Fatal: true}
+		var line int
+		inProfile := strings.Contains(msg, "config.WrapperProfile") || strings.Contains(msg, "config.RestartBackoffConfig") || strings.Contains(msg, "config.BackendConfig")
+		if _, scanErr := fmt.Sscanf(msg, "line %d:", &line); scanErr == nil && inProfile {
+			problem.Profile = profileAtLine(starts, line)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// profileStart is the line of a profile's name under the 'wrappers' key.
+type profileStart struct {
+	name string
+	line int
+}
+
+// profileStartLines returns the profiles under 'wrappers' in file order.
+func profileStartLines(data []byte) []profileStart {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	top := root.Content[0]
+	if top.Kind != yaml.MappingNode {
+		return nil
+	}
+	var starts []profileStart
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value != "wrappers" || top.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		wrappers := top.Content[i+1]
+		for j := 0; j+1 < len(wrappers.Content); j += 2 {
+			starts = append(starts, profileStart{name: wrappers.Content[j].Value, line: wrappers.Content[j].Line})
+		}
+	}
+	sort.Slice(starts, func(a, b int) bool { return starts[a].line < starts[b].line })
+	return starts
+}
+
+// profileAtLine returns the profile whose definition contains line.
+func profileAtLine(starts []profileStart, line int) string {
+	name := ""
+	for _, start := range starts {
+		if start.line > line {
+			break
+		}
+		name = start.name
+	}
+	return name
+}
+
+// otherProfiles lists the profiles in owners other than self, quoted.
+func otherProfiles(owners []string, self string) string {
+	var others []string
+	for _, owner := range owners {
+		if owner != self {
+			others = append(others, "'"+owner+"'")
+		}
+	}
+	return strings.Join(others, ", ")
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// WatchWrapperConfig polls filePath every interval and calls onChange with the newly parsed
+// config whenever the file's size or modification time changes. Files that fail to parse are
+// reported and skipped, leaving the previous config in effect. Call the returned function to stop.
+func WatchWrapperConfig(filePath string, interval time.Duration, onChange func(*WrapperConfig)) (stop func()) {
+	done := make(chan struct{})
+	lastMod, lastSize := statFile(filePath)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mod, size := statFile(filePath)
+				if mod.Equal(lastMod) && size == lastSize {
+					continue
+				}
+				lastMod, lastSize = mod, size
+
+				conf, err := LoadWrapperConfig(filePath)
+				if err != nil {
+					log.Printf("Config Warning: Ignoring change to '%s': %v", filePath, err)
+					continue
+				}
+				onChange(conf)
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// statFile returns the modification time and size of a file, or zero values if it cannot be read.
+func statFile(filePath string) (time.Time, int64) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
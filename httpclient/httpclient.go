@@ -0,0 +1,57 @@
+// Package httpclient builds the HTTP clients used to reach the Ithena platform (log uploads,
+// ping and auth), so they share the proxy settings and the request timeout.
+package httpclient
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTimeout bounds each request, including reading the response body.
+const defaultTimeout = 30 * time.Second
+
+// timeoutEnv overrides defaultTimeout with a Go duration (e.g. "2m").
+const timeoutEnv = "ITHENA_HTTP_TIMEOUT"
+
+// timeout is the effective request timeout, set by SetTimeout.
+var timeout = defaultTimeout
+
+// SetTimeout sets the request timeout of new clients. 0 falls back to ITHENA_HTTP_TIMEOUT,
+// then to 30s; a negative duration is an error.
+func SetTimeout(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("invalid HTTP timeout %s: it must not be negative", d)
+	}
+	if d == 0 {
+		d = defaultTimeout
+		if v := strings.TrimSpace(os.Getenv(timeoutEnv)); v != "" {
+			if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+				d = parsed
+			} else {
+				log.Printf("HTTP Warning: Ignoring invalid %s value '%s', using %s", timeoutEnv, v, defaultTimeout)
+			}
+		}
+	}
+	timeout = d
+	return nil
+}
+
+var (
+	transportOnce sync.Once
+	transport     *http.Transport
+)
+
+// New returns a client with the configured timeout whose requests go through the proxy
+// named by HTTPS_PROXY, HTTP_PROXY and NO_PROXY (or their lowercase forms), if any.
+func New() *http.Client {
+	transportOnce.Do(func() {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+	})
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
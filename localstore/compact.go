@@ -0,0 +1,77 @@
+package localstore
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrDatabaseBusy is returned by Compact when another connection, such as 'logs show' or a
+// running wrapper, kept it from getting exclusive access to the database.
+var ErrDatabaseBusy = errors.New("the log database is in use by another process ('logs show' or a running wrapper); stop it and try again")
+
+// Compact rebuilds the database file with VACUUM so the space freed by deleted logs is
+// returned to the file system, then truncates the write-ahead log. VACUUM can't run inside a
+// transaction and needs every other connection to be idle; it waits up to the busy timeout
+// and then fails with ErrDatabaseBusy. Other processes may keep the file open meanwhile.
+func Compact() error {
+	if DB == nil {
+		return errors.New("localstore: database not initialized, call InitDB first")
+	}
+	db := writer()
+
+	dbPath, err := databaseFilePath()
+	if err != nil {
+		return err
+	}
+	before := databaseFileSize(dbPath)
+
+	if _, err := db.Exec("VACUUM;"); err != nil {
+		if isBusy(err) {
+			return ErrDatabaseBusy
+		}
+		return fmt.Errorf("localstore: failed to compact database: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE);"); err != nil {
+		if verbose { log.Printf("LocalStore: Failed to truncate the write-ahead log after compacting: %v", err) }
+	}
+
+	if verbose { log.Printf("LocalStore: Compacted %s from %d to %d bytes (including its write-ahead log).", dbPath, before, databaseFileSize(dbPath)) }
+	return nil
+}
+
+// databaseFilePath returns the file backing the main database of DB.
+func databaseFilePath() (string, error) {
+	var seq int
+	var name, file string
+	if err := writer().QueryRow("PRAGMA database_list;").Scan(&seq, &name, &file); err != nil {
+		return "", fmt.Errorf("localstore: failed to determine database file: %w", err)
+	}
+	return file, nil
+}
+
+// databaseFileSize returns the size of the database file plus its write-ahead log, 0 for
+// files that can't be read.
+func databaseFileSize(dbPath string) int64 {
+	var total int64
+	for _, path := range []string{dbPath, dbPath + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// isBusy reports whether err is SQLite failing to get a lock held by another connection.
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff // Primary result code without the extended bits
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
@@ -0,0 +1,237 @@
+package localstore
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IndexedField promotes a value inside one of the stored JSON columns to an indexed column,
+// so logs can be filtered on it without scanning the JSON of every row.
+type IndexedField struct {
+	Name    string // Column is named "field_<Name>"; lowercase letters, digits and '_'
+	Source  string // One of the indexedFieldSources keys, e.g. "request"
+	Pointer string // JSON pointer into the source, e.g. "/meta/request_id"
+}
+
+// indexedFieldSources maps IndexedField.Source values to the logs columns they read.
+var indexedFieldSources = map[string]string{
+	"request":   "request_preview",
+	"response":  "response_preview",
+	"error":     "error_details",
+	"tool_args": "tool_args",
+}
+
+// indexedFieldsTableName records the expression of every field column, so changed
+// definitions can be detected.
+const indexedFieldsTableName = "indexed_fields"
+
+// indexedFieldColumnPrefix keeps field columns apart from the built-in ones.
+const indexedFieldColumnPrefix = "field_"
+
+var indexedFieldNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// configuredIndexedFields is set by SetIndexedFields; nil leaves the database's columns as they are.
+var configuredIndexedFields []IndexedField
+
+// SetIndexedFields sets the fields InitDB should have as columns. Columns of fields missing from
+// fields are dropped and changed ones are rebuilt; nil (unlike an empty slice) leaves the
+// columns as they are, e.g. for commands that don't read the wrapper config.
+func SetIndexedFields(fields []IndexedField) {
+	configuredIndexedFields = fields
+}
+
+// ValidateIndexedField checks a field definition without touching the database.
+func ValidateIndexedField(field IndexedField) error {
+	if !indexedFieldNameRegex.MatchString(field.Name) {
+		return fmt.Errorf("invalid field name '%s': use lowercase letters, digits and '_', starting with a letter", field.Name)
+	}
+	if _, ok := indexedFieldSources[field.Source]; !ok {
+		return fmt.Errorf("field '%s': unknown source '%s' (expected request, response, error or tool_args)", field.Name, field.Source)
+	}
+	if _, err := jsonPathFromPointer(field.Pointer); err != nil {
+		return fmt.Errorf("field '%s': %w", field.Name, err)
+	}
+	return nil
+}
+
+// jsonPathFromPointer converts a JSON pointer such as "/items/0/id" to the equivalent SQLite
+// JSON path, `$."items"[0]."id"`. Tokens made only of digits are array indexes.
+func jsonPathFromPointer(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("invalid JSON pointer '%s': it must start with '/'", pointer)
+	}
+	path := "$"
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if index, err := strconv.Atoi(token); err == nil && index >= 0 && token == strconv.Itoa(index) {
+			path += fmt.Sprintf("[%d]", index)
+			continue
+		}
+		if strings.ContainsAny(token, `"'`) {
+			return "", fmt.Errorf("invalid JSON pointer '%s': quotes are not supported in keys", pointer)
+		}
+		path += `."` + token + `"`
+	}
+	return path, nil
+}
+
+// indexedFieldExpression returns the SQL expression computing a field's value.
+func indexedFieldExpression(field IndexedField) (string, error) {
+	if err := ValidateIndexedField(field); err != nil {
+		return "", err
+	}
+	path, _ := jsonPathFromPointer(field.Pointer)
+	return fmt.Sprintf("json_extract(%s, '%s')", indexedFieldSources[field.Source], path), nil
+}
+
+// syncIndexedFields makes the field columns match configuredIndexedFields. Columns are
+// VIRTUAL generated columns, computed from the JSON on read, so existing logs get values
+// too; each one has an index.
+func syncIndexedFields() error {
+	if configuredIndexedFields == nil {
+		return nil
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := indexedFieldExpressions(tx)
+	if err != nil {
+		return err
+	}
+	wanted := make(map[string]string, len(configuredIndexedFields))
+	for _, field := range configuredIndexedFields {
+		expression, err := indexedFieldExpression(field)
+		if err != nil {
+			return err
+		}
+		if _, duplicate := wanted[field.Name]; duplicate {
+			return fmt.Errorf("field '%s' is defined more than once", field.Name)
+		}
+		wanted[field.Name] = expression
+	}
+
+	changed := false
+	for name, expression := range existing {
+		if wanted[name] != expression {
+			if err := dropIndexedField(tx, name); err != nil {
+				return err
+			}
+			changed = true
+		}
+	}
+	for name, expression := range wanted {
+		if existing[name] == expression {
+			continue
+		}
+		if err := addIndexedField(tx, name, expression); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// indexedFieldExpressions returns the recorded field columns by name.
+func indexedFieldExpressions(tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.Query(fmt.Sprintf("SELECT name, expression FROM %s;", indexedFieldsTableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexed fields: %w", err)
+	}
+	defer rows.Close()
+	fields := make(map[string]string)
+	for rows.Next() {
+		var name, expression string
+		if err := rows.Scan(&name, &expression); err != nil {
+			return nil, fmt.Errorf("failed to scan indexed field: %w", err)
+		}
+		fields[name] = expression
+	}
+	return fields, rows.Err()
+}
+
+func addIndexedField(tx *sql.Tx, name, expression string) error {
+	if verbose { log.Printf("LocalStore: Adding indexed field %s = %s", name, expression) }
+	column := indexedFieldColumnPrefix + name
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT GENERATED ALWAYS AS (%s) VIRTUAL;", logsTableName, column, expression)); err != nil {
+		return fmt.Errorf("failed to add column for field '%s': %w", name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE INDEX idx_logs_%s ON %s (%s);", column, logsTableName, column)); err != nil {
+		return fmt.Errorf("failed to index field '%s': %w", name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (name, expression) VALUES (?, ?);", indexedFieldsTableName), name, expression); err != nil {
+		return fmt.Errorf("failed to record field '%s': %w", name, err)
+	}
+	return nil
+}
+
+func dropIndexedField(tx *sql.Tx, name string) error {
+	if verbose { log.Printf("LocalStore: Removing indexed field %s", name) }
+	column := indexedFieldColumnPrefix + name
+	if _, err := tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS idx_logs_%s;", column)); err != nil {
+		return fmt.Errorf("failed to drop index of field '%s': %w", name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", logsTableName, column)); err != nil {
+		return fmt.Errorf("failed to drop column of field '%s': %w", name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = ?;", indexedFieldsTableName), name); err != nil {
+		return fmt.Errorf("failed to forget field '%s': %w", name, err)
+	}
+	return nil
+}
+
+// IndexedFieldNames returns the names of the fields the database has columns for, sorted.
+func IndexedFieldNames() ([]string, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("localstore: database not initialized")
+	}
+	rows, err := DB.Query(fmt.Sprintf("SELECT name FROM %s ORDER BY name;", indexedFieldsTableName))
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to list indexed fields: %w", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan indexed field: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func isIndexedField(names []string, name string) bool {
+	for _, known := range names {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldFilterClauses returns one equality condition per filtered field, in name order.
+func fieldFilterClauses(fields map[string]string) ([]string, []interface{}) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	clauses := make([]string, len(names))
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		clauses[i] = indexedFieldColumnPrefix + name + " = ?"
+		args[i] = fields[name]
+	}
+	return clauses, args
+}
@@ -8,7 +8,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	// SQLite driver
 	_ "modernc.org/sqlite" // Pure Go SQLite driver (no CGO)
@@ -26,16 +30,53 @@ func SetVerbose(v bool) {
 // DB is a package-level variable to hold the database connection.
 var DB *sql.DB
 
-const currentSchemaVersion = 1
+// currentSchemaVersion is the version of the last entry in migrations.
+const currentSchemaVersion = 4
 const logsTableName = "logs"
 
+// Environment variables that configure automatic pruning when the database is opened.
+// ITHENA_LOG_MAX_AGE accepts Go durations ("72h") or whole days ("30d").
+const (
+	logMaxAgeEnv  = "ITHENA_LOG_MAX_AGE"
+	logMaxRowsEnv = "ITHENA_LOG_MAX_ROWS"
+)
+
+// writeDB is the writable handle used for explicit user-requested writes (e.g. deleting a
+// log) while DB is read-only. It is nil when DB itself is writable; see writer.
+var writeDB *sql.DB
+
+// connectionPragmas apply to every connection: WAL lets readers (e.g. 'logs show') proceed
+// while another process writes, and the busy timeout makes a connection wait up to 5s for a
+// lock instead of failing immediately with "database is locked". Transactions on this handle
+// all write, so they take the write lock up front (_txlock=immediate): upgrading a read lock
+// later fails with SQLITE_BUSY without waiting when another process wrote in the meantime.
+const connectionPragmas = "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+// readOnlyPragmas makes every connection opened with it reject writes. The journal mode is
+// persistent, so it was already set by the writable handle.
+const readOnlyPragmas = "?_pragma=busy_timeout(5000)&_pragma=query_only(1)"
+
 // InitDB initializes the SQLite database for local log storage.
 // It ensures the database file and necessary tables are created and migrated if needed.
+// An empty path uses the --log-db or ITHENA_LOG_DB path, or the default location.
 func InitDB(explicitDBPath string) error {
+	return initDB(explicitDBPath, false)
+}
+
+// InitDBReadOnly initializes the database like InitDB, but DB rejects writes (PRAGMA
+// query_only), so a viewer can't interfere with wrappers writing concurrently. The schema is
+// still created or migrated first if needed, and automatic pruning is skipped. Explicit writes
+// such as DeleteLogByID go through a separate writable handle.
+func InitDBReadOnly(explicitDBPath string) error {
+	return initDB(explicitDBPath, true)
+}
+
+func initDB(explicitDBPath string, readOnly bool) error {
+	writeDB = nil
 	dbPath := explicitDBPath
 	var err error
 	if dbPath == "" {
-		dbPath, err = getDefaultLogStorePath()
+		dbPath, err = logStorePath()
 		if err != nil {
 			return fmt.Errorf("failed to get default log store path: %w", err)
 		}
@@ -52,11 +93,12 @@ func InitDB(explicitDBPath string) error {
 	}
 
 	// Open the SQLite database file. It will be created if it doesn't exist.
-	// The DSN for modernc.org/sqlite is simply the path to the file.
-	DB, err = sql.Open("sqlite", dbPath)
+	// The DSN for modernc.org/sqlite is the path to the file, plus per-connection pragmas.
+	DB, err = sql.Open("sqlite", dbPath+connectionPragmas)
 	if err != nil {
 		return fmt.Errorf("failed to open database at %s: %w", dbPath, err)
 	}
+	applyPoolConfig(DB, poolConfigFromEnv())
 
 	// Check if the database connection is actually working.
 	if err = DB.Ping(); err != nil {
@@ -81,117 +123,150 @@ func InitDB(explicitDBPath string) error {
 		log.Println("LocalStore: Schema initialized successfully.")
 	}
 
+	if readOnly {
+		// Keep the writable handle for explicit writes and switch readers to a query_only one.
+		roDB, err := sql.Open("sqlite", dbPath+readOnlyPragmas)
+		if err != nil {
+			DB.Close()
+			DB = nil
+			return fmt.Errorf("failed to open read-only database at %s: %w", dbPath, err)
+		}
+		applyPoolConfig(roDB, poolConfigFromEnv())
+		if err = roDB.Ping(); err != nil {
+			roDB.Close()
+			DB.Close()
+			DB = nil
+			return fmt.Errorf("failed to ping read-only database at %s: %w", dbPath, err)
+		}
+		writeDB, DB = DB, roDB
+		if verbose {
+			log.Println("LocalStore: Database opened read-only.")
+		}
+		return nil
+	}
+
+	pruneFromEnv()
+
 	return nil
 }
 
-// createSchema handles the creation and migration of database schema.
-func createSchema() error {
-	// 1. Create schema_version table if it doesn't exist
-	_, err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL PRIMARY KEY);`)
-	if err != nil {
-		return fmt.Errorf("failed to create schema_version table: %w", err)
+// writer returns the handle to use for writes: writeDB when DB is read-only, otherwise DB.
+func writer() *sql.DB {
+	if writeDB != nil {
+		return writeDB
 	}
+	return DB
+}
 
-	// 2. Check current version
-	var dbVersion int
-	err = DB.QueryRow(`SELECT version FROM schema_version ORDER BY version DESC LIMIT 1;`).Scan(&dbVersion)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			// No version found, assume new database, insert current version
-			_, err = DB.Exec(`INSERT INTO schema_version (version) VALUES (?);`, currentSchemaVersion)
-			if err != nil {
-				return fmt.Errorf("failed to insert initial schema version: %w", err)
-			}
-			dbVersion = currentSchemaVersion
+// pruneFromEnv applies the retention limits configured via environment variables, if any.
+// Failures are logged but never prevent the database from being used.
+func pruneFromEnv() {
+	var maxAge time.Duration
+	var maxRows int
+	if ageStr := strings.TrimSpace(os.Getenv(logMaxAgeEnv)); ageStr != "" {
+		age, err := ParseAge(ageStr)
+		if err != nil {
+			log.Printf("LocalStore Warning: Ignoring invalid %s value '%s': %v", logMaxAgeEnv, ageStr, err)
 		} else {
-			return fmt.Errorf("failed to query schema version: %w", err)
+			maxAge = age
 		}
 	}
+	if rowsStr := strings.TrimSpace(os.Getenv(logMaxRowsEnv)); rowsStr != "" {
+		rows, err := strconv.Atoi(rowsStr)
+		if err != nil || rows < 0 {
+			log.Printf("LocalStore Warning: Ignoring invalid %s value '%s'", logMaxRowsEnv, rowsStr)
+		} else {
+			maxRows = rows
+		}
+	}
+	if maxAge <= 0 && maxRows <= 0 {
+		return
+	}
 
-	// 3. Perform migrations if dbVersion < currentSchemaVersion
-	if dbVersion < currentSchemaVersion {
-		// Placeholder for migration logic if schema evolves in the future
-		if verbose {
-			log.Printf("LocalStore: Database schema version %d is older than current version %d. Migrating...", dbVersion, currentSchemaVersion)
-		}
-		// Example: if dbVersion == 1 && currentSchemaVersion == 2 { migrateToV2() }
-		// For now, we just ensure the logs table for V1 exists.
-		// Update the version after successful migration
-		// _, err = DB.Exec(`UPDATE schema_version SET version = ? WHERE version = ?;`, currentSchemaVersion, dbVersion) // This is wrong, should insert new or be atomic
-		// A better way for versioning is to have an upgrade path and insert new version record or update a single row.
-		// For now, we'll just ensure the latest tables are there and update to currentSchemaVersion if it was a new DB.
-	}
-
-	// 4. Create logs table (version 1 schema)
-	// Ensure this matches types.AuditRecord fields that need to be columnized vs JSON.
-	createLogsTableSQL := fmt.Sprintf(`
-	CREATE TABLE IF NOT EXISTS %s (
-		id TEXT NOT NULL PRIMARY KEY,
-		timestamp TEXT NOT NULL,
-		mcp_method TEXT,
-		tool_name TEXT,
-		duration_ms INTEGER,
-		status TEXT NOT NULL,
-		proxy_version TEXT,
-		target_server_alias TEXT,
-		request_preview TEXT, -- Stored as JSON
-		response_preview TEXT, -- Stored as JSON
-		error_details TEXT -- Stored as JSON
-	);
-	`, logsTableName)
+	deleted, err := PruneLogs(maxAge, maxRows)
+	if err != nil {
+		log.Printf("LocalStore Warning: Failed to prune old logs: %v", err)
+		return
+	}
+	if verbose {
+		log.Printf("LocalStore: Pruned %d log(s) (max age: %s, max rows: %d)", deleted, maxAge, maxRows)
+	}
+}
+
+// ParseAge parses a Go duration string, additionally accepting a "d" suffix for days ("30d").
+func ParseAge(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days: %w", err)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
 
-	_, err = DB.Exec(createLogsTableSQL)
+// createSchema creates the schema_version table and brings the schema up to
+// currentSchemaVersion by applying pending migrations (see migrations.go).
+func createSchema() error {
+	_, err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL PRIMARY KEY);`)
 	if err != nil {
-		return fmt.Errorf("failed to create %s table: %w", logsTableName, err)
+		return fmt.Errorf("failed to create schema_version table: %w", err)
 	}
 
-	// Create indexes for common query patterns
-	indexes := []string{
-		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON %s (timestamp DESC);", logsTableName),
-		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_status ON %s (status);", logsTableName),
-		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_tool_name ON %s (tool_name);", logsTableName),
-		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_mcp_method ON %s (mcp_method);", logsTableName),
+	dbVersion, err := migrate()
+	if err != nil {
+		return err
 	}
+	if verbose { log.Printf("LocalStore: Schema is at version %d", dbVersion) }
+	if dbVersion >= 3 {
+		if err := ensureSearchIndex(); err != nil {
+			// Searches fall back to LIKE without the index.
+			log.Printf("LocalStore Warning: Failed to create the full-text search index: %v", err)
+		}
+	}
+	searchIndexReady = searchIndexExists(DB)
+	if err := syncIndexedFields(); err != nil {
+		// Logging keeps working without the field columns.
+		log.Printf("LocalStore Warning: Failed to update indexed fields: %v", err)
+	}
+	return nil
+}
 
-	for _, indexSQL := range indexes {
-		_, err = DB.Exec(indexSQL)
-		if err != nil {
-			// Non-fatal, but log it
-			log.Printf("LocalStore Warning: Failed to create index (%s): %v", indexSQL, err)
-		}
-	}
-
-	// If we reached here and the initial dbVersion was less than current (e.g. new DB)
-	// ensure the schema_version table reflects the current version.
-	if dbVersion < currentSchemaVersion {
-		// This is a simplified way, for a real app you might have version-specific migrations.
-		// We are basically saying that by creating all tables up to currentSchemaVersion, we are at currentSchemaVersion.
-		// If schema_version was empty, we already inserted currentSchemaVersion.
-		// If it was an older version, we would run migrations then update.
-		// For now, if it was old, we assume applying the latest CREATE TABLE IF NOT EXISTS is enough for V1.
-		// A more robust migration system would track each version and apply incremental changes.
-		// For current simple case, if we just created the table, and old version was less, we ensure it is set.
-		// We already inserted `currentSchemaVersion` if `sql.ErrNoRows` was met.
-		// If `dbVersion` was genuinely an older, existing version, we'd need proper migration steps here.
-		// For now, this will ensure that if the DB was old and we just applied V1 schema, version is updated.
-		// This part needs more robust handling if actual schema migrations are introduced.
-		// _, err = DB.Exec(`INSERT OR REPLACE INTO schema_version (version) VALUES (?);`, currentSchemaVersion)
-		// For a single row version table: 
-		_, err = DB.Exec(`INSERT INTO schema_version (version) VALUES (?) ON CONFLICT(version) DO UPDATE SET version = excluded.version WHERE excluded.version > (SELECT MAX(version) FROM schema_version);`, currentSchemaVersion)
-		// Actually, simpler for a single schema version table, just update if it exists, or insert if not (which we did earlier).
-		// The earlier check for sql.ErrNoRows already handles inserting the first version.
-		// If there was an older version, and we had migrations, this is where we'd update it AFTER migrations.
-		// For now, we consider the schema up-to-date if all CREATE TABLE IF NOT EXISTS passes.
-		// Let's assume for V1, if dbVersion was < currentSchemaVersion, and we are at V1, this is the first run for V1.
-		if verbose && dbVersion < currentSchemaVersion {
-			log.Printf("LocalStore: Schema potentially updated to version %d", currentSchemaVersion)
+// ensureColumn adds a column to an existing table if it is not already present.
+func ensureColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect columns of %s: %w", table, err)
+	}
+	found := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan column info of %s: %w", table, err)
 		}
+		if name == column {
+			found = true
+		}
+	}
+	rows.Close()
+	if found {
+		return nil
 	}
 
+	if verbose {
+		log.Printf("LocalStore: Adding column %s to table %s", column, table)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s to %s: %w", column, table, err)
+	}
 	return nil
 }
 
 // SaveBatch saves a batch of audit records to the local SQLite database.
+// Records whose ID is already stored are skipped, so saving the same record twice is harmless.
 func SaveBatch(records []types.AuditRecord) error {
 	if DB == nil {
 		return errors.New("localstore: database not initialized, call InitDB first")
@@ -204,8 +279,8 @@ func SaveBatch(records []types.AuditRecord) error {
 	defer tx.Rollback() // Rollback if commit is not called or if any error occurs
 
 	stmtSQL := fmt.Sprintf(`
-	INSERT INTO %s (id, timestamp, mcp_method, tool_name, duration_ms, status, proxy_version, target_server_alias, request_preview, response_preview, error_details)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	INSERT OR IGNORE INTO %s (id, timestamp, mcp_method, tool_name, duration_ms, status, proxy_version, target_server_alias, request_preview, response_preview, error_details, session_metadata, request_bytes, response_bytes, pending_upload, tool_args, first_byte_ms)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 	`, logsTableName)
 
 	stmt, err := tx.Prepare(stmtSQL)
@@ -214,6 +289,19 @@ func SaveBatch(records []types.AuditRecord) error {
 	}
 	defer stmt.Close()
 
+	// Newly inserted logs are added to the full-text index under the same rowid.
+	var indexStmt *sql.Stmt
+	if searchIndexReady {
+		indexStmt, err = tx.Prepare(fmt.Sprintf(`
+		INSERT INTO %s (rowid, tool_name, mcp_method, error_details, tool_args, request_preview, response_preview, id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+		`, searchIndexTableName))
+		if err != nil {
+			return fmt.Errorf("localstore: failed to prepare search index statement: %w", err)
+		}
+		defer indexStmt.Close()
+	}
+
 	for _, record := range records {
 		// Serialize JSON fields
 		reqPreviewBytes, err := json.Marshal(record.RequestPreview)
@@ -232,6 +320,26 @@ func SaveBatch(records []types.AuditRecord) error {
 			errDetailsBytes = []byte("null")
 		}
 
+		var sessionMetadata sql.NullString
+		if record.Session != nil {
+			sessionBytes, err := json.Marshal(record.Session)
+			if err != nil {
+				log.Printf("LocalStore Warning: Failed to marshal Session for record %s: %v. Storing as NULL.", record.ID, err)
+			} else {
+				sessionMetadata = sql.NullString{String: string(sessionBytes), Valid: true}
+			}
+		}
+
+		var toolArgs sql.NullString
+		if record.ToolArgs != nil {
+			toolArgsBytes, err := json.Marshal(record.ToolArgs)
+			if err != nil {
+				log.Printf("LocalStore Warning: Failed to marshal ToolArgs for record %s: %v. Storing as NULL.", record.ID, err)
+			} else {
+				toolArgs = sql.NullString{String: string(toolArgsBytes), Valid: true}
+			}
+		}
+
 		// Handle potentially nil pointers for string/int fields by converting to sql.NullString, sql.NullInt64
 		var mcpMethod sql.NullString
 		if record.McpMethod != nil {
@@ -245,6 +353,10 @@ func SaveBatch(records []types.AuditRecord) error {
 		if record.DurationMs != nil {
 			durationMs = sql.NullInt64{Int64: *record.DurationMs, Valid: true}
 		}
+		var firstByteMs sql.NullInt64
+		if record.FirstByteMs != nil {
+			firstByteMs = sql.NullInt64{Int64: *record.FirstByteMs, Valid: true}
+		}
 		var proxyVersion sql.NullString
 		if record.ProxyVersion != nil {
 			proxyVersion = sql.NullString{String: *record.ProxyVersion, Valid: true}
@@ -253,8 +365,16 @@ func SaveBatch(records []types.AuditRecord) error {
 		if record.TargetServerAlias != nil {
 			targetServerAlias = sql.NullString{String: *record.TargetServerAlias, Valid: true}
 		}
+		var requestBytes sql.NullInt64
+		if record.RequestBytes != nil {
+			requestBytes = sql.NullInt64{Int64: *record.RequestBytes, Valid: true}
+		}
+		var responseBytes sql.NullInt64
+		if record.ResponseBytes != nil {
+			responseBytes = sql.NullInt64{Int64: *record.ResponseBytes, Valid: true}
+		}
 
-		_, err = stmt.Exec(
+		res, err := stmt.Exec(
 			record.ID,
 			record.Timestamp, // Assuming this is already a string in ISO 8601 format
 			mcpMethod,
@@ -266,12 +386,31 @@ func SaveBatch(records []types.AuditRecord) error {
 			string(reqPreviewBytes),
 			string(respPreviewBytes),
 			string(errDetailsBytes),
+			sessionMetadata,
+			requestBytes,
+			responseBytes,
+			record.PendingUpload,
+			toolArgs,
+			firstByteMs,
 		)
 		if err != nil {
 			// Log the error and continue to try other records in the batch, but the transaction will be rolled back.
 			log.Printf("LocalStore Error: Failed to execute statement for record %s: %v. Batch will be rolled back.", record.ID, err)
 			return fmt.Errorf("localstore: failed to execute statement for record %s: %w", record.ID, err) // Ensure rollback
 		}
+
+		if indexStmt != nil {
+			if inserted, _ := res.RowsAffected(); inserted == 0 {
+				continue // Already stored and indexed
+			}
+			rowID, err := res.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("localstore: failed to get rowid of record %s: %w", record.ID, err)
+			}
+			if _, err := indexStmt.Exec(rowID, toolName, mcpMethod, string(errDetailsBytes), toolArgs, string(reqPreviewBytes), string(respPreviewBytes), record.ID); err != nil {
+				return fmt.Errorf("localstore: failed to index record %s: %w", record.ID, err)
+			}
+		}
 	}
 
 	err = tx.Commit()
@@ -285,43 +424,304 @@ func SaveBatch(records []types.AuditRecord) error {
 	return nil
 }
 
+// PruneLogs deletes logs whose timestamp is older than maxAge and then keeps at most
+// maxRows of the newest remaining logs. A zero value disables the corresponding rule.
+// Both deletions run in one transaction; the total number of deleted rows is returned.
+func PruneLogs(maxAge time.Duration, maxRows int) (int64, error) {
+	if DB == nil {
+		return 0, errors.New("localstore: database not initialized, call InitDB first")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("localstore: failed to begin prune transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Timestamps are RFC3339Nano text, whose trimmed fractional seconds do not sort
+	// lexicographically (".1Z" > ".12Z"). julianday() parses them into comparable numbers.
+	var deleted int64
+	if maxAge > 0 {
+		cutoff := time.Now().Add(-maxAge).UTC().Format(time.RFC3339Nano)
+		res, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE julianday(timestamp) < julianday(?)", logsTableName), cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("localstore: failed to prune logs older than %s: %w", maxAge, err)
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	if maxRows > 0 {
+		res, err := tx.Exec(fmt.Sprintf(
+			"DELETE FROM %[1]s WHERE id NOT IN (SELECT id FROM %[1]s ORDER BY julianday(timestamp) DESC, id DESC LIMIT ?)",
+			logsTableName), maxRows)
+		if err != nil {
+			return 0, fmt.Errorf("localstore: failed to prune logs beyond %d rows: %w", maxRows, err)
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("localstore: failed to commit prune transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// logDBEnv overrides the database path; the --log-db flag (SetLogDBPath) takes precedence.
+const logDBEnv = "ITHENA_LOG_DB"
+
+// logDBPath is the database path set with SetLogDBPath. Empty means ITHENA_LOG_DB or the default.
+var logDBPath string
+
+// SetLogDBPath sets the database file used when InitDB is called without an explicit path.
+func SetLogDBPath(path string) {
+	logDBPath = path
+}
+
+// configuredLogStorePath returns the database path from SetLogDBPath or ITHENA_LOG_DB, or ""
+// if neither is set.
+func configuredLogStorePath() string {
+	if logDBPath != "" {
+		return logDBPath
+	}
+	return strings.TrimSpace(os.Getenv(logDBEnv))
+}
+
+// logStorePath returns the effective database path: the configured one if set, else the default.
+func logStorePath() (string, error) {
+	if path := configuredLogStorePath(); path != "" {
+		return path, nil
+	}
+	return getDefaultLogStorePath()
+}
+
+// logStoreDirName is the directory holding the local database under the chosen base directory.
+const logStoreDirName = "ithena-cli"
+
+// fallbackWarnOnce limits the "not using the config directory" warning to once per process.
+var fallbackWarnOnce sync.Once
+
 // getDefaultLogStorePath helper function to get the default database path.
+// It prefers the user config directory; when that is unavailable (e.g. no HOME in a CI
+// container) it falls back to a per-user directory under os.TempDir(), then to the
+// current directory, so local logging keeps working in stripped-down environments. The
+// temporary directory is shared with other users, so the one used there must be private to
+// the current user.
 func getDefaultLogStorePath() (string, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	type candidate struct {
+		dir         string
+		description string
+		private     bool // Under a shared directory: created 0700 and checked with checkPrivateDir
 	}
-	ithenaConfigDir := filepath.Join(configDir, "ithena-cli") 
-	if err := os.MkdirAll(ithenaConfigDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create directory %s: %w", ithenaConfigDir, err)
+	var candidates []candidate
+	var failures []string
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, candidate{filepath.Join(configDir, logStoreDirName), "user config directory", false})
+	} else {
+		failures = append(failures, fmt.Sprintf("user config directory: %v", err))
 	}
-	return filepath.Join(ithenaConfigDir, "local_logs.v1.db"), nil 
+	candidates = append(candidates,
+		candidate{filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", logStoreDirName, os.Getuid())), "temporary directory", true},
+		candidate{filepath.Join(".", "."+logStoreDirName), "current directory", false},
+	)
+
+	for i, c := range candidates {
+		perm := os.FileMode(0755)
+		if c.private {
+			perm = 0700
+		}
+		if err := os.MkdirAll(c.dir, perm); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", c.description, c.dir, err))
+			continue
+		}
+		if c.private {
+			if err := checkPrivateDir(c.dir); err != nil {
+				failures = append(failures, fmt.Sprintf("%s %s: %v", c.description, c.dir, err))
+				continue
+			}
+		}
+		if i > 0 || len(failures) > 0 {
+			fallbackWarnOnce.Do(func() {
+				log.Printf("LocalStore Warning: Local logs are not in the user config directory (%s); storing them in the %s at %s instead.",
+					strings.Join(failures, "; "), c.description, c.dir)
+			})
+		}
+		return filepath.Join(c.dir, "local_logs.v1.db"), nil
+	}
+	return "", fmt.Errorf("no writable directory for local logs (%s)", strings.Join(failures, "; "))
 }
 
-// GetDefaultLogStorePathForInfo returns the default path where local logs are stored.
-// This is primarily for informational display to the user.
+// GetDefaultLogStorePathForInfo returns the path where local logs are stored: the one set
+// with --log-db or ITHENA_LOG_DB, or the default. This is primarily for informational display
+// to the user.
 func GetDefaultLogStorePathForInfo() (string, error) {
-	return getDefaultLogStorePath()
+	return logStorePath()
+}
+
+// logSelectColumns lists the columns read back into an AuditRecord, in scanAuditRecord order.
+const logSelectColumns = "id, timestamp, mcp_method, tool_name, duration_ms, status, proxy_version, target_server_alias, request_preview, response_preview, error_details, session_metadata, request_bytes, response_bytes, pending_upload, tool_args, first_byte_ms"
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// prefixedScanner scans leading extra columns (e.g. rowid) before the logSelectColumns ones.
+type prefixedScanner struct {
+	row    rowScanner
+	prefix []interface{}
+}
+
+func (p prefixedScanner) Scan(dest ...interface{}) error {
+	return p.row.Scan(append(append([]interface{}{}, p.prefix...), dest...)...)
+}
+
+// scanAuditRecord reads one row selected with logSelectColumns into an AuditRecord.
+func scanAuditRecord(row rowScanner) (types.AuditRecord, error) {
+	var r types.AuditRecord
+	var reqPreviewJSON, respPreviewJSON, errDetailsJSON, sessionJSON, toolArgsJSON sql.NullString // For raw JSON strings
+	// Need to use sql.NullString etc. for potentially NULL DB columns when scanning
+	var mcpMethod, toolName, proxyVersion, targetServerAlias sql.NullString
+	var durationMs, requestBytes, responseBytes, firstByteMs sql.NullInt64
+
+	err := row.Scan(
+		&r.ID, &r.Timestamp, &mcpMethod, &toolName, &durationMs,
+		&r.Status, &proxyVersion, &targetServerAlias,
+		&reqPreviewJSON, &respPreviewJSON, &errDetailsJSON, &sessionJSON,
+		&requestBytes, &responseBytes, &r.PendingUpload, &toolArgsJSON, &firstByteMs,
+	)
+	if err != nil {
+		return r, err
+	}
+
+	// Assign to pointers in AuditRecord if valid
+	if mcpMethod.Valid { r.McpMethod = &mcpMethod.String }
+	if toolName.Valid { r.ToolName = &toolName.String }
+	if durationMs.Valid { r.DurationMs = &durationMs.Int64 }
+	if proxyVersion.Valid { r.ProxyVersion = &proxyVersion.String }
+	if targetServerAlias.Valid { r.TargetServerAlias = &targetServerAlias.String }
+	if requestBytes.Valid { r.RequestBytes = &requestBytes.Int64 }
+	if responseBytes.Valid { r.ResponseBytes = &responseBytes.Int64 }
+	if firstByteMs.Valid { r.FirstByteMs = &firstByteMs.Int64 }
+
+	// Deserialize JSON strings back into interface{}
+	if reqPreviewJSON.Valid { json.Unmarshal([]byte(reqPreviewJSON.String), &r.RequestPreview) }
+	if respPreviewJSON.Valid { json.Unmarshal([]byte(respPreviewJSON.String), &r.ResponsePreview) }
+	if errDetailsJSON.Valid { json.Unmarshal([]byte(errDetailsJSON.String), &r.ErrorDetails) }
+	if toolArgsJSON.Valid { json.Unmarshal([]byte(toolArgsJSON.String), &r.ToolArgs) }
+	if sessionJSON.Valid {
+		var session types.SessionMetadata
+		if json.Unmarshal([]byte(sessionJSON.String), &session) == nil {
+			r.Session = &session
+		}
+	}
+
+	return r, nil
 }
 
 // LogQueryFilters defines available filters for querying logs.
 // All filters are ANDed together if multiple are provided.
 type LogQueryFilters struct {
-	Status   string // e.g., "success", "failure"
+	Status   string // Exact status to match, e.g. "success", "timeout" or a custom value
 	ToolName string // Exact match for tool_name
 	McpMethod string // Exact match for mcp_method
-	SearchTerm string // Simple text search across ID, and JSON previews (requires LIKE clause)
+	Statuses []string // Any of these statuses; combined with Status if both are set
+	ToolNames []string // Any of these tool names; combined with ToolName if both are set
+	McpMethods []string // Any of these MCP methods; combined with McpMethod if both are set
+	SearchTerm string // Case-insensitive text search across ID, tool, method and JSON previews; "tool:", "error:" etc. limit it to one (see parseSearchTerm)
+	Since time.Time // Only logs at or after this time; zero means no lower bound
+	StartTime string // RFC3339; only logs at or after this time ("" means no bound)
+	EndTime string // RFC3339; only logs at or before this time ("" means no bound)
+	MinDurationMs int64 // Only logs that took at least this long; 0 means no bound
+	MaxDurationMs int64 // Only logs that took at most this long; 0 means no bound
+	Fields map[string]string // Exact values of indexed fields by name (see IndexedField)
+	PendingUpload bool // Only records still flagged pending_upload
+	SortBy string // Column QueryLogs sorts by, one of SortColumns ("" means timestamp)
+	SortOrder string // "asc" or "desc" ("" means desc)
+}
+
+// SortColumns lists the columns QueryLogs can sort by.
+var SortColumns = []string{"timestamp", "duration_ms", "first_byte_ms", "request_bytes", "response_bytes", "status", "tool_name", "mcp_method"}
+
+// orderByClause returns the ORDER BY expression for QueryLogs. Rows without a value for the
+// sort column come last either way, and ties are broken by newest first. The column is
+// interpolated into the SQL, so anything but one of SortColumns sorts by timestamp, even if
+// the caller skipped ValidateFilters.
+func orderByClause(filters LogQueryFilters) string {
+	column := filters.SortBy
+	if !slices.Contains(SortColumns, column) {
+		column = "timestamp"
+	}
+	order := "DESC"
+	if strings.EqualFold(filters.SortOrder, "asc") {
+		order = "ASC"
+	}
+	// RFC3339Nano text doesn't sort lexicographically within a second, so compare julianday.
+	if column == "timestamp" {
+		return "julianday(timestamp) " + order
+	}
+	return fmt.Sprintf("%[1]s IS NULL, %[1]s %[2]s, julianday(timestamp) DESC", column, order)
+}
+
+// ValidateFilters checks the filter fields that buildFilterClause cannot reject itself:
+// time bounds must be RFC3339, duration bounds must form a non-empty range and field filters
+// must name indexed fields (checked against the database once it is initialized).
+func ValidateFilters(filters LogQueryFilters) error {
+	for name, value := range map[string]string{"start": filters.StartTime, "end": filters.EndTime} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("invalid %s time '%s' (expected RFC3339, e.g. 2024-05-01T12:00:00Z)", name, value)
+		}
+	}
+	for name := range filters.Fields {
+		if !indexedFieldNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid field name '%s'", name)
+		}
+	}
+	if len(filters.Fields) > 0 && DB != nil {
+		known, err := IndexedFieldNames()
+		if err != nil {
+			return err
+		}
+		for name := range filters.Fields {
+			if !isIndexedField(known, name) {
+				return fmt.Errorf("unknown field '%s' (indexed fields: %s)", name, strings.Join(known, ", "))
+			}
+		}
+	}
+	if filters.SortBy != "" && !slices.Contains(SortColumns, filters.SortBy) {
+		return fmt.Errorf("invalid sort column '%s' (expected one of: %s)", filters.SortBy, strings.Join(SortColumns, ", "))
+	}
+	if filters.SortOrder != "" && !strings.EqualFold(filters.SortOrder, "asc") && !strings.EqualFold(filters.SortOrder, "desc") {
+		return fmt.Errorf("invalid sort order '%s' (expected asc or desc)", filters.SortOrder)
+	}
+	if filters.MinDurationMs < 0 || filters.MaxDurationMs < 0 {
+		return errors.New("duration bounds must not be negative")
+	}
+	if filters.MaxDurationMs > 0 && filters.MinDurationMs > filters.MaxDurationMs {
+		return fmt.Errorf("minimum duration %dms is greater than maximum duration %dms", filters.MinDurationMs, filters.MaxDurationMs)
+	}
+	return nil
 }
 
 // QueryLogsResult holds the result of a log query, including total count for pagination.
+// Page is the page actually returned, which may be lower than the one requested.
 type QueryLogsResult struct {
 	Logs       []types.AuditRecord `json:"logs"`
 	TotalCount int                 `json:"total_count"`
 	Page       int                 `json:"page"`
 	Limit      int                 `json:"limit"`
+	TotalPages int                 `json:"total_pages"` // 0 when nothing matches
+	HasNext    bool                `json:"has_next"`
+	HasPrev    bool                `json:"has_prev"`
 }
 
-// QueryLogs retrieves a paginated and filtered list of logs from the database.
+// QueryLogs retrieves a paginated and filtered list of logs from the database, newest first
+// unless filters.SortBy and SortOrder say otherwise. A page past the end is clamped to the
+// last page, so it returns the oldest logs rather than none.
 func QueryLogs(filters LogQueryFilters, page int, limit int) (*QueryLogsResult, error) {
 	if DB == nil {
 		return nil, errors.New("localstore: database not initialized")
@@ -333,49 +733,36 @@ func QueryLogs(filters LogQueryFilters, page int, limit int) (*QueryLogsResult,
 	if limit <= 0 {
 		limit = 20 // Default limit
 	}
-	offset := (page - 1) * limit
 
-	var queryArgs []interface{}
-	whereClauses := []string{"1 = 1"} // Start with a true condition to simplify appending ANDs
+	whereStr, queryArgs := buildFilterClause(filters)
 
-	if filters.Status != "" {
-		whereClauses = append(whereClauses, "status = ?")
-		queryArgs = append(queryArgs, filters.Status)
-	}
-	if filters.ToolName != "" {
-		whereClauses = append(whereClauses, "tool_name = ?")
-		queryArgs = append(queryArgs, filters.ToolName)
-	}
-	if filters.McpMethod != "" {
-		whereClauses = append(whereClauses, "mcp_method = ?")
-		queryArgs = append(queryArgs, filters.McpMethod)
-	}
-	if filters.SearchTerm != "" {
-		// Basic search: check ID and LIKE against JSON previews
-		// This is not super efficient for JSON but okay for a local tool with moderate data.
-		// For SQLite, JSON fields are just text, so LIKE works.
-		searchTermPattern := "%" + filters.SearchTerm + "%"
-		whereClauses = append(whereClauses, "(id LIKE ? OR request_preview LIKE ? OR response_preview LIKE ? OR error_details LIKE ?)")
-		queryArgs = append(queryArgs, searchTermPattern, searchTermPattern, searchTermPattern, searchTermPattern)
-	}
-
-	baseQuery := fmt.Sprintf("SELECT id, timestamp, mcp_method, tool_name, duration_ms, status, proxy_version, target_server_alias, request_preview, response_preview, error_details FROM %s", logsTableName)
+	baseQuery := fmt.Sprintf("SELECT %s FROM %s", logSelectColumns, logsTableName)
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", logsTableName)
 
-	whereStr := strings.Join(whereClauses, " AND ")
-
-	fullQuery := fmt.Sprintf("%s WHERE %s ORDER BY timestamp DESC LIMIT ? OFFSET ?", baseQuery, whereStr)
+	fullQuery := fmt.Sprintf("%s WHERE %s ORDER BY %s LIMIT ? OFFSET ?", baseQuery, whereStr, orderByClause(filters))
 	fullCountQuery := fmt.Sprintf("%s WHERE %s", countQuery, whereStr)
 
+	// Arguments for the count query (only filters)
+	finalCountQueryArgs := make([]interface{}, len(queryArgs))
+	copy(finalCountQueryArgs, queryArgs)
+
+	// Counted first so the requested page can be clamped to the pages that exist.
+	var totalCount int
+	err := DB.QueryRow(fullCountQuery, finalCountQueryArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to count logs: %w (Query: %s, Args: %v)", err, fullCountQuery, finalCountQueryArgs)
+	}
+	totalPages := (totalCount + limit - 1) / limit
+	if page > totalPages {
+		page = max(totalPages, 1)
+	}
+	offset := (page - 1) * limit
+
 	// Arguments for the main query (filters + limit + offset)
 	finalQueryArgs := make([]interface{}, len(queryArgs))
 	copy(finalQueryArgs, queryArgs)
 	finalQueryArgs = append(finalQueryArgs, limit, offset)
 
-	// Arguments for the count query (only filters)
-	finalCountQueryArgs := make([]interface{}, len(queryArgs))
-	copy(finalCountQueryArgs, queryArgs)
-
 	rows, err := DB.Query(fullQuery, finalQueryArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("localstore: failed to execute query logs: %w (Query: %s, Args: %v)", err, fullQuery, finalQueryArgs)
@@ -384,46 +771,228 @@ func QueryLogs(filters LogQueryFilters, page int, limit int) (*QueryLogsResult,
 
 	logs := []types.AuditRecord{}
 	for rows.Next() {
-		var r types.AuditRecord
-		var reqPreviewJSON, respPreviewJSON, errDetailsJSON sql.NullString // For raw JSON strings
-		// Need to use sql.NullString etc. for potentially NULL DB columns when scanning
-		var mcpMethod, toolName, proxyVersion, targetServerAlias sql.NullString
-		var durationMs sql.NullInt64
-
-		err = rows.Scan(
-			&r.ID, &r.Timestamp, &mcpMethod, &toolName, &durationMs,
-			&r.Status, &proxyVersion, &targetServerAlias,
-			&reqPreviewJSON, &respPreviewJSON, &errDetailsJSON,
-		)
+		r, err := scanAuditRecord(rows)
 		if err != nil {
 			return nil, fmt.Errorf("localstore: failed to scan log row: %w", err)
 		}
+		logs = append(logs, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating log rows: %w", err)
+	}
+
+	return &QueryLogsResult{
+		Logs:       logs,
+		TotalCount: totalCount,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}, nil
+}
+
+// buildFilterClause turns filters into a SQL WHERE expression (without the WHERE keyword)
+// and its positional arguments. An empty filter yields an always-true expression.
+func buildFilterClause(filters LogQueryFilters) (string, []interface{}) {
+	var queryArgs []interface{}
+	whereClauses := []string{"1 = 1"} // Start with a true condition to simplify appending ANDs
+
+	for _, f := range []struct {
+		column string
+		values []string
+	}{
+		{"status", filterValues(filters.Status, filters.Statuses)},
+		{"tool_name", filterValues(filters.ToolName, filters.ToolNames)},
+		{"mcp_method", filterValues(filters.McpMethod, filters.McpMethods)},
+	} {
+		switch len(f.values) {
+		case 0:
+		case 1:
+			whereClauses = append(whereClauses, f.column+" = ?")
+			queryArgs = append(queryArgs, f.values[0])
+		default:
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(f.values)), ", ")
+			whereClauses = append(whereClauses, fmt.Sprintf("%s IN (%s)", f.column, placeholders))
+			for _, value := range f.values {
+				queryArgs = append(queryArgs, value)
+			}
+		}
+	}
+	if filters.SearchTerm != "" {
+		// Full-text match, or LIKE without the index, against the ID, tool, method and JSON
+		// previews (see searchColumns), or only the column named by a "field:" prefix.
+		clause, args := searchClause(filters.SearchTerm)
+		whereClauses = append(whereClauses, clause)
+		queryArgs = append(queryArgs, args...)
+	}
+	if !filters.Since.IsZero() {
+		whereClauses = append(whereClauses, "julianday(timestamp) >= julianday(?)")
+		queryArgs = append(queryArgs, filters.Since.UTC().Format(time.RFC3339Nano))
+	}
+	// Timestamps are compared as julian days: stored RFC3339Nano strings with differing
+	// fractional digits or offsets don't sort correctly as text.
+	if filters.StartTime != "" {
+		whereClauses = append(whereClauses, "julianday(timestamp) >= julianday(?)")
+		queryArgs = append(queryArgs, filters.StartTime)
+	}
+	if filters.EndTime != "" {
+		whereClauses = append(whereClauses, "julianday(timestamp) <= julianday(?)")
+		queryArgs = append(queryArgs, filters.EndTime)
+	}
+	if filters.MinDurationMs > 0 {
+		whereClauses = append(whereClauses, "duration_ms >= ?")
+		queryArgs = append(queryArgs, filters.MinDurationMs)
+	}
+	if filters.MaxDurationMs > 0 {
+		whereClauses = append(whereClauses, "duration_ms <= ?")
+		queryArgs = append(queryArgs, filters.MaxDurationMs)
+	}
+	if filters.PendingUpload {
+		whereClauses = append(whereClauses, "pending_upload = 1")
+	}
+	if len(filters.Fields) > 0 {
+		clauses, args := fieldFilterClauses(filters.Fields)
+		whereClauses = append(whereClauses, clauses...)
+		queryArgs = append(queryArgs, args...)
+	}
+
+	return strings.Join(whereClauses, " AND "), queryArgs
+}
+
+// filterValues merges a single-value filter with its multi-value counterpart, skipping empty
+// and repeated values.
+func filterValues(single string, values []string) []string {
+	var merged []string
+	seen := make(map[string]bool)
+	for _, value := range append([]string{single}, values...) {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		merged = append(merged, value)
+	}
+	return merged
+}
 
-		// Assign to pointers in AuditRecord if valid
-		if mcpMethod.Valid { r.McpMethod = &mcpMethod.String }
-		if toolName.Valid { r.ToolName = &toolName.String }
-		if durationMs.Valid { r.DurationMs = &durationMs.Int64 }
-		if proxyVersion.Valid { r.ProxyVersion = &proxyVersion.String }
-		if targetServerAlias.Valid { r.TargetServerAlias = &targetServerAlias.String }
+// LatestLogCursor returns a cursor positioned after the newest stored log, for use with LogsAfter.
+func LatestLogCursor() (int64, error) {
+	if DB == nil {
+		return 0, errors.New("localstore: database not initialized")
+	}
+	var cursor int64
+	if err := DB.QueryRow(fmt.Sprintf("SELECT COALESCE(MAX(rowid), 0) FROM %s", logsTableName)).Scan(&cursor); err != nil {
+		return 0, fmt.Errorf("localstore: failed to read latest log cursor: %w", err)
+	}
+	return cursor, nil
+}
 
-		// Deserialize JSON strings back into interface{}
-		if reqPreviewJSON.Valid { json.Unmarshal([]byte(reqPreviewJSON.String), &r.RequestPreview) }
-		if respPreviewJSON.Valid { json.Unmarshal([]byte(respPreviewJSON.String), &r.ResponsePreview) }
-		if errDetailsJSON.Valid { json.Unmarshal([]byte(errDetailsJSON.String), &r.ErrorDetails) }
+// LogsAfter returns up to limit logs matching filters that were inserted after cursor, in
+// insertion order, together with the cursor to pass on the next call. Cursors are SQLite
+// rowids, so this also sees logs written by other processes (e.g. a wrapper running while
+// 'logs show' is open). If the table was cleared since cursor was taken, it starts over.
+func LogsAfter(cursor int64, filters LogQueryFilters, limit int) ([]types.AuditRecord, int64, error) {
+	if DB == nil {
+		return nil, cursor, errors.New("localstore: database not initialized")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
 
+	latest, err := LatestLogCursor()
+	if err != nil {
+		return nil, cursor, err
+	}
+	if latest < cursor {
+		cursor = 0 // Rows were deleted and rowids reused; every current row is new to the caller.
+	}
+
+	whereStr, queryArgs := buildFilterClause(filters)
+	query := fmt.Sprintf("SELECT rowid, %s FROM %s WHERE rowid > ? AND %s ORDER BY rowid LIMIT ?", logSelectColumns, logsTableName, whereStr)
+	queryArgs = append([]interface{}{cursor}, queryArgs...)
+	queryArgs = append(queryArgs, limit)
+
+	rows, err := DB.Query(query, queryArgs...)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("localstore: failed to query new logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []types.AuditRecord{}
+	next := cursor
+	for rows.Next() {
+		var rowID int64
+		r, err := scanAuditRecord(prefixedScanner{row: rows, prefix: []interface{}{&rowID}})
+		if err != nil {
+			return nil, cursor, fmt.Errorf("localstore: failed to scan new log row: %w", err)
+		}
 		logs = append(logs, r)
+		next = rowID
 	}
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("localstore: error iterating log rows: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, cursor, fmt.Errorf("localstore: error iterating new log rows: %w", err)
 	}
+	// With fewer than limit results every row up to latest has been considered, so non-matching
+	// rows need not be rescanned next time.
+	if len(logs) < limit && latest > next {
+		next = latest
+	}
+	return logs, next, nil
+}
 
-	var totalCount int
-	err = DB.QueryRow(fullCountQuery, finalCountQueryArgs...).Scan(&totalCount)
+// HasFilters reports whether filters restrict the logs at all.
+func (filters LogQueryFilters) HasFilters() bool {
+	return len(filterValues(filters.Status, filters.Statuses)) > 0 || len(filterValues(filters.ToolName, filters.ToolNames)) > 0 ||
+		len(filterValues(filters.McpMethod, filters.McpMethods)) > 0 || filters.SearchTerm != "" || filters.PendingUpload ||
+		!filters.Since.IsZero() || filters.StartTime != "" || filters.EndTime != "" ||
+		filters.MinDurationMs > 0 || filters.MaxDurationMs > 0 || len(filters.Fields) > 0
+}
+
+// DeleteLogs removes the logs matching filters, as QueryLogs would select them, and returns
+// how many were deleted. Filters matching every log are rejected; clearing the whole store is
+// done by deleting its file.
+func DeleteLogs(filters LogQueryFilters) (int, error) {
+	if DB == nil {
+		return 0, errors.New("localstore: database not initialized")
+	}
+	if !filters.HasFilters() {
+		return 0, errors.New("localstore: refusing to delete logs without a filter")
+	}
+
+	whereStr, queryArgs := buildFilterClause(filters)
+	res, err := writer().Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", logsTableName, whereStr), queryArgs...)
 	if err != nil {
-		return nil, fmt.Errorf("localstore: failed to count logs: %w (Query: %s, Args: %v)", err, fullCountQuery, finalCountQueryArgs)
+		return 0, fmt.Errorf("localstore: failed to delete logs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("localstore: failed to confirm deletion of logs: %w", err)
+	}
+	if verbose { log.Printf("LocalStore: Deleted %d log(s) matching filters", n) }
+	return int(n), nil
+}
+
+// ErrLogNotFound is returned by DeleteLogByID when no log has the given ID.
+var ErrLogNotFound = errors.New("localstore: log not found")
+
+// DeleteLogByID removes a single log entry. It returns ErrLogNotFound if no row was deleted.
+func DeleteLogByID(id string) error {
+	if DB == nil {
+		return errors.New("localstore: database not initialized")
 	}
 
-	return &QueryLogsResult{Logs: logs, TotalCount: totalCount, Page: page, Limit: limit}, nil
+	res, err := writer().Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", logsTableName), id)
+	if err != nil {
+		return fmt.Errorf("localstore: failed to delete log %s: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("localstore: failed to confirm deletion of log %s: %w", id, err)
+	}
+	if n == 0 {
+		return ErrLogNotFound
+	}
+	return nil
 }
 
 // GetLogByID retrieves a single log entry by its ID.
@@ -432,21 +1001,9 @@ func GetLogByID(id string) (*types.AuditRecord, error) {
 		return nil, errors.New("localstore: database not initialized")
 	}
 
-	query := fmt.Sprintf("SELECT id, timestamp, mcp_method, tool_name, duration_ms, status, proxy_version, target_server_alias, request_preview, response_preview, error_details FROM %s WHERE id = ?", logsTableName)
-	
-	row := DB.QueryRow(query, id)
-
-	var r types.AuditRecord
-	var reqPreviewJSON, respPreviewJSON, errDetailsJSON sql.NullString
-	var mcpMethod, toolName, proxyVersion, targetServerAlias sql.NullString
-	var durationMs sql.NullInt64
-
-	err := row.Scan(
-		&r.ID, &r.Timestamp, &mcpMethod, &toolName, &durationMs,
-		&r.Status, &proxyVersion, &targetServerAlias,
-		&reqPreviewJSON, &respPreviewJSON, &errDetailsJSON,
-	)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", logSelectColumns, logsTableName)
 
+	r, err := scanAuditRecord(DB.QueryRow(query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Log not found, return nil, nil (or a specific ErrNotFound error)
@@ -454,17 +1011,114 @@ func GetLogByID(id string) (*types.AuditRecord, error) {
 		return nil, fmt.Errorf("localstore: failed to scan log row for ID %s: %w", id, err)
 	}
 
-	if mcpMethod.Valid { r.McpMethod = &mcpMethod.String }
-	if toolName.Valid { r.ToolName = &toolName.String }
-	if durationMs.Valid { r.DurationMs = &durationMs.Int64 }
-	if proxyVersion.Valid { r.ProxyVersion = &proxyVersion.String }
-	if targetServerAlias.Valid { r.TargetServerAlias = &targetServerAlias.String }
+	return &r, nil
+}
 
-	if reqPreviewJSON.Valid { json.Unmarshal([]byte(reqPreviewJSON.String), &r.RequestPreview) }
-	if respPreviewJSON.Valid { json.Unmarshal([]byte(respPreviewJSON.String), &r.ResponsePreview) }
-	if errDetailsJSON.Valid { json.Unmarshal([]byte(errDetailsJSON.String), &r.ErrorDetails) }
+// DistinctStatuses returns every status value present in the store, sorted alphabetically.
+func DistinctStatuses() ([]string, error) {
+	if DB == nil {
+		return nil, errors.New("localstore: database not initialized")
+	}
 
-	return &r, nil
+	rows, err := DB.Query(fmt.Sprintf("SELECT DISTINCT status FROM %s ORDER BY status", logsTableName))
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to query statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []string{}
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan status row: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating status rows: %w", err)
+	}
+	return statuses, nil
+}
+
+// TopDimension names a column that TopValues can group by.
+type TopDimension string
+
+const (
+	TopByError  TopDimension = "error"
+	TopByTool   TopDimension = "tool"
+	TopByMethod TopDimension = "method"
+	TopByStatus TopDimension = "status"
+)
+
+// topDimensionExprs maps each TopDimension to the SQL expression grouped on. Errors are grouped
+// by their message so that differing data payloads for the same failure count together.
+var topDimensionExprs = map[TopDimension]string{
+	TopByError:  "COALESCE(json_extract(error_details, '$.message'), error_details)",
+	TopByTool:   "tool_name",
+	TopByMethod: "mcp_method",
+	TopByStatus: "status",
+}
+
+// topDimensionConditions adds a condition for dimensions whose column holds more than the
+// NULL that IS NOT NULL skips: SaveBatch stores a nil ErrorDetails as the JSON text 'null'.
+var topDimensionConditions = map[TopDimension]string{
+	TopByError: "error_details != 'null'",
+}
+
+// TopValue is one ranked row returned by TopValues.
+type TopValue struct {
+	Value         string `json:"value"`
+	Count         int    `json:"count"`
+	LastSeen      string `json:"last_seen"`      // RFC3339 UTC timestamp of the newest matching log
+	RequestBytes  int64  `json:"request_bytes"`  // Total raw request size; logs recorded before byte counts existed count as 0
+	ResponseBytes int64  `json:"response_bytes"` // Total raw response size
+}
+
+// TopValues returns the limit most frequent values of the given dimension among logs
+// matching filters, most frequent first. Logs without a value for the dimension are skipped.
+func TopValues(by TopDimension, filters LogQueryFilters, limit int) ([]TopValue, error) {
+	if DB == nil {
+		return nil, errors.New("localstore: database not initialized")
+	}
+	expr, ok := topDimensionExprs[by]
+	if !ok {
+		return nil, fmt.Errorf("localstore: unknown top dimension '%s'", by)
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	whereStr, queryArgs := buildFilterClause(filters)
+	if condition, ok := topDimensionConditions[by]; ok {
+		whereStr += " AND " + condition
+	}
+	query := fmt.Sprintf(
+		"SELECT %[1]s AS value, COUNT(*) AS cnt, strftime('%%Y-%%m-%%dT%%H:%%M:%%fZ', MAX(julianday(timestamp))) AS last_seen, "+
+			"COALESCE(SUM(request_bytes), 0), COALESCE(SUM(response_bytes), 0) "+
+			"FROM %[2]s WHERE %[3]s AND %[1]s IS NOT NULL GROUP BY value ORDER BY cnt DESC, MAX(julianday(timestamp)) DESC LIMIT ?",
+		expr, logsTableName, whereStr)
+	queryArgs = append(queryArgs, limit)
+
+	rows, err := DB.Query(query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to query top %s values: %w", by, err)
+	}
+	defer rows.Close()
+
+	values := []TopValue{}
+	for rows.Next() {
+		var v TopValue
+		var lastSeen sql.NullString
+		if err := rows.Scan(&v.Value, &v.Count, &lastSeen, &v.RequestBytes, &v.ResponseBytes); err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan top %s row: %w", by, err)
+		}
+		v.LastSeen = lastSeen.String
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating top %s rows: %w", by, err)
+	}
+	return values, nil
 }
 
 // TODO: Implement QueryLogs and GetLogByID functions later for the 'logs show' command. 
\ No newline at end of file
@@ -0,0 +1,162 @@
+package localstore
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// migration upgrades the schema to version. up runs inside a transaction that also records
+// the new version, so a failed migration leaves the database at the previous version.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Append new migrations here and bump
+// currentSchemaVersion to match; never edit one that has shipped.
+var migrations = []migration{
+	{version: 1, description: "create logs table", up: migrateV1},
+	{version: 2, description: "add session, byte size, pending upload, tool args and first byte columns; create pending table", up: migrateV2},
+	{version: 3, description: "create full-text search index", up: createSearchIndex},
+	{version: 4, description: "create indexed_fields table", up: migrateV4},
+}
+
+// migrateV1 creates the logs table as originally released, with its indexes.
+func migrateV1(tx *sql.Tx) error {
+	_, err := tx.Exec(fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		id TEXT NOT NULL PRIMARY KEY,
+		timestamp TEXT NOT NULL,
+		mcp_method TEXT,
+		tool_name TEXT,
+		duration_ms INTEGER,
+		status TEXT NOT NULL,
+		proxy_version TEXT,
+		target_server_alias TEXT,
+		request_preview TEXT, -- Stored as JSON
+		response_preview TEXT, -- Stored as JSON
+		error_details TEXT -- Stored as JSON
+	);
+	`, logsTableName))
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", logsTableName, err)
+	}
+
+	indexes := []string{
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON %s (timestamp DESC);", logsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_status ON %s (status);", logsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_tool_name ON %s (tool_name);", logsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_logs_mcp_method ON %s (mcp_method);", logsTableName),
+	}
+	for _, indexSQL := range indexes {
+		if _, err := tx.Exec(indexSQL); err != nil {
+			return fmt.Errorf("failed to create index (%s): %w", indexSQL, err)
+		}
+	}
+	return nil
+}
+
+// migrateV2 adds the columns and the pending upload table that version 1 databases gained
+// in place before migrations existed, so each step checks whether it was already applied.
+func migrateV2(tx *sql.Tx) error {
+	columns := []struct{ name, definition string }{
+		{"session_metadata", "TEXT"}, // Stored as JSON
+		{"request_bytes", "INTEGER"},
+		{"response_bytes", "INTEGER"},
+		{"pending_upload", "INTEGER NOT NULL DEFAULT 0"}, // 1 while the record awaits upload to the platform
+		{"tool_args", "TEXT"},                            // Stored as JSON
+		{"first_byte_ms", "INTEGER"},
+	}
+	for _, column := range columns {
+		if err := ensureColumn(tx, logsTableName, column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	return createPendingTable(tx)
+}
+
+// migrateV4 creates the table recording the columns added for configured indexed fields.
+func migrateV4(tx *sql.Tx) error {
+	_, err := tx.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (name TEXT NOT NULL PRIMARY KEY, expression TEXT NOT NULL);`, indexedFieldsTableName))
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", indexedFieldsTableName, err)
+	}
+	return nil
+}
+
+// migrate applies every migration newer than the database's recorded version, each in its
+// own transaction, and returns the resulting version.
+func migrate() (int, error) {
+	dbVersion, err := schemaVersion(DB)
+	if err != nil {
+		return 0, err
+	}
+	if dbVersion > currentSchemaVersion {
+		log.Printf("LocalStore Warning: Database schema version %d is newer than this version of ithena-cli supports (%d); it was probably written by a newer release.", dbVersion, currentSchemaVersion)
+		return dbVersion, nil
+	}
+
+	for _, m := range migrations {
+		if m.version <= dbVersion {
+			continue
+		}
+		if verbose { log.Printf("LocalStore: Migrating schema from version %d to %d (%s)...", dbVersion, m.version, m.description) }
+		if err := applyMigration(m); err != nil {
+			return dbVersion, fmt.Errorf("migration to version %d (%s) failed: %w", m.version, m.description, err)
+		}
+		dbVersion = m.version
+	}
+	return dbVersion, nil
+}
+
+// applyMigration runs one migration and records its version atomically. Another process may
+// have applied it since the version was read, in which case it is skipped.
+func applyMigration(m migration) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	dbVersion, err := schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+	if dbVersion >= m.version {
+		return nil
+	}
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if err := setSchemaVersion(tx, m.version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// schemaVersion returns the recorded schema version, 0 for a new database.
+func schemaVersion(q queryer) (int, error) {
+	var version int
+	if err := q.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version;`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to query schema version: %w", err)
+	}
+	return version, nil
+}
+
+// setSchemaVersion replaces the recorded version, keeping schema_version to a single row.
+func setSchemaVersion(tx *sql.Tx, version int) error {
+	if _, err := tx.Exec(`DELETE FROM schema_version;`); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES (?);`, version); err != nil {
+		return fmt.Errorf("failed to record schema version %d: %w", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package localstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// pendingTableName journals records destined for the remote platform until delivery is
+// confirmed, so batches in flight when the CLI exits or crashes can be re-sent by a later run.
+const pendingTableName = "pending_uploads"
+
+// PendingRecord is a journaled record together with the endpoint it was meant for.
+type PendingRecord struct {
+	ObserveUrl string
+	Record     types.AuditRecord
+}
+
+// createPendingTable creates the pending upload journal if needed.
+func createPendingTable(tx *sql.Tx) error {
+	_, err := tx.Exec(fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		id TEXT NOT NULL PRIMARY KEY, -- AuditRecord.ID, so a record is journaled at most once
+		observe_url TEXT NOT NULL,
+		record TEXT NOT NULL, -- Full AuditRecord as JSON
+		queued_at TEXT NOT NULL,
+		claimed_by TEXT, -- Process currently sending the record, if any
+		claimed_at TEXT
+	);
+	`, pendingTableName))
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", pendingTableName, err)
+	}
+	return nil
+}
+
+// SavePending journals records for observeUrl, claimed by claimedBy. Records whose ID is
+// already journaled are left untouched, so a record is never queued twice.
+func SavePending(records []types.AuditRecord, observeUrl string, claimedBy string) error {
+	if DB == nil {
+		return errors.New("localstore: database not initialized, call InitDB first")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("localstore: failed to begin pending transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT OR IGNORE INTO %s (id, observe_url, record, queued_at, claimed_by, claimed_at) VALUES (?, ?, ?, ?, ?, ?);",
+		pendingTableName))
+	if err != nil {
+		return fmt.Errorf("localstore: failed to prepare pending statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, record := range records {
+		recordJSON, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("localstore: failed to marshal pending record %s: %w", record.ID, err)
+		}
+		if _, err := stmt.Exec(record.ID, observeUrl, string(recordJSON), now, claimedBy, now); err != nil {
+			return fmt.Errorf("localstore: failed to journal pending record %s: %w", record.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("localstore: failed to commit pending records: %w", err)
+	}
+	return nil
+}
+
+// ClaimPending marks every unclaimed pending record as claimed by claimedBy and returns them,
+// oldest first. Claims older than staleAfter are taken over, since their owner has most likely
+// exited without releasing them. Claiming keeps concurrent CLI processes from sending the same
+// records twice.
+func ClaimPending(claimedBy string, staleAfter time.Duration) ([]PendingRecord, error) {
+	if DB == nil {
+		return nil, errors.New("localstore: database not initialized, call InitDB first")
+	}
+
+	now := time.Now().UTC()
+	staleBefore := now.Add(-staleAfter).Format(time.RFC3339Nano)
+	_, err := DB.Exec(fmt.Sprintf(
+		"UPDATE %s SET claimed_by = ?, claimed_at = ? WHERE claimed_by IS NULL OR julianday(claimed_at) < julianday(?);",
+		pendingTableName), claimedBy, now.Format(time.RFC3339Nano), staleBefore)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to claim pending records: %w", err)
+	}
+
+	rows, err := DB.Query(fmt.Sprintf(
+		"SELECT id, observe_url, record FROM %s WHERE claimed_by = ? ORDER BY julianday(queued_at), id;",
+		pendingTableName), claimedBy)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to query pending records: %w", err)
+	}
+	defer rows.Close()
+
+	pending := []PendingRecord{}
+	var unreadable []string
+	for rows.Next() {
+		var id, observeUrl, recordJSON string
+		if err := rows.Scan(&id, &observeUrl, &recordJSON); err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan pending record: %w", err)
+		}
+		var record types.AuditRecord
+		if err := json.Unmarshal([]byte(recordJSON), &record); err != nil {
+			log.Printf("LocalStore Warning: Dropping unreadable pending record %s: %v", id, err)
+			unreadable = append(unreadable, id)
+			continue
+		}
+		pending = append(pending, PendingRecord{ObserveUrl: observeUrl, Record: record})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating pending records: %w", err)
+	}
+	// Deleted only after iteration: with a single pooled connection, a write while rows is
+	// still open would wait for the connection rows holds.
+	rows.Close()
+	if len(unreadable) > 0 {
+		DeletePending(unreadable)
+	}
+	return pending, nil
+}
+
+// DeletePending removes delivered records from the journal.
+func DeletePending(ids []string) error {
+	return execForIDs(pendingTableName, "DELETE FROM %s WHERE id IN (%s);", ids)
+}
+
+// ReleasePending clears the claim on records that could not be delivered, leaving them
+// queued for a later attempt.
+func ReleasePending(ids []string) error {
+	return execForIDs(pendingTableName, "UPDATE %s SET claimed_by = NULL, claimed_at = NULL WHERE id IN (%s);", ids)
+}
+
+// MarkUploaded clears the pending_upload flag on locally stored copies of records that have
+// since been delivered to the platform.
+func MarkUploaded(ids []string) error {
+	return execForIDs(logsTableName, "UPDATE %s SET pending_upload = 0 WHERE pending_upload = 1 AND id IN (%s);", ids)
+}
+
+// execForIDs runs a statement against table for a set of record IDs.
+// queryFormat receives the table name and the placeholder list.
+func execForIDs(table, queryFormat string, ids []string) error {
+	if DB == nil {
+		return errors.New("localstore: database not initialized, call InitDB first")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	if _, err := DB.Exec(fmt.Sprintf(queryFormat, table, placeholders), args...); err != nil {
+		return fmt.Errorf("localstore: failed to update records in %s: %w", table, err)
+	}
+	return nil
+}
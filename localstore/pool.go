@@ -0,0 +1,75 @@
+package localstore
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that tune the database/sql connection pool.
+// ITHENA_DB_CONN_MAX_LIFETIME accepts Go durations ("10m"); 0 keeps connections indefinitely.
+const (
+	dbMaxOpenConnsEnv    = "ITHENA_DB_MAX_OPEN_CONNS"
+	dbMaxIdleConnsEnv    = "ITHENA_DB_MAX_IDLE_CONNS"
+	dbConnMaxLifetimeEnv = "ITHENA_DB_CONN_MAX_LIFETIME"
+)
+
+// PoolConfig holds the connection pool settings applied by InitDB.
+type PoolConfig struct {
+	MaxOpenConns    int           // 0 means unlimited
+	MaxIdleConns    int           // Connections kept open between queries
+	ConnMaxLifetime time.Duration // 0 means connections are never closed for age
+}
+
+// DefaultPoolConfig returns the pool settings used when no environment overrides are set.
+// SQLite allows a single writer per database file, and with several pooled connections
+// concurrent writes from the same process fail with SQLITE_BUSY instead of waiting their
+// turn. One connection serializes all access in-process; reads are short, so the cost is low.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1}
+}
+
+// poolConfigFromEnv returns DefaultPoolConfig with any valid environment overrides applied.
+// Invalid values are logged and ignored.
+func poolConfigFromEnv() PoolConfig {
+	cfg := DefaultPoolConfig()
+	if v := strings.TrimSpace(os.Getenv(dbMaxOpenConnsEnv)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Printf("LocalStore Warning: Ignoring invalid %s value '%s'", dbMaxOpenConnsEnv, v)
+		} else {
+			cfg.MaxOpenConns = n
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv(dbMaxIdleConnsEnv)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Printf("LocalStore Warning: Ignoring invalid %s value '%s'", dbMaxIdleConnsEnv, v)
+		} else {
+			cfg.MaxIdleConns = n
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv(dbConnMaxLifetimeEnv)); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Printf("LocalStore Warning: Ignoring invalid %s value '%s'", dbConnMaxLifetimeEnv, v)
+		} else {
+			cfg.ConnMaxLifetime = d
+		}
+	}
+	return cfg
+}
+
+// applyPoolConfig configures db's connection pool.
+func applyPoolConfig(db *sql.DB, cfg PoolConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if verbose {
+		log.Printf("LocalStore: Connection pool: max open %d, max idle %d, max lifetime %s",
+			cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime)
+	}
+}
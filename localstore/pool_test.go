@@ -0,0 +1,83 @@
+package localstore
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// TestConcurrentWritersAndReader saves batches from several goroutines while another one
+// queries, with the default pool and with several pooled connections, and checks no call
+// fails (e.g. with SQLITE_BUSY) and every record is stored.
+func TestConcurrentWritersAndReader(t *testing.T) {
+	for _, maxOpen := range []string{"", "4"} {
+		t.Run("max_open_conns="+maxOpen, func(t *testing.T) {
+			t.Setenv(dbMaxOpenConnsEnv, maxOpen)
+			if err := InitDB(t.TempDir() + "/logs.db"); err != nil {
+				t.Fatalf("InitDB: %v", err)
+			}
+			defer DB.Close()
+
+			const writers, batches, batchSize = 4, 20, 10
+			errs := make(chan error, writers*batches+1)
+			var wg sync.WaitGroup
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for b := 0; b < batches; b++ {
+						batch := make([]types.AuditRecord, batchSize)
+						for i := range batch {
+							batch[i] = types.AuditRecord{
+								ID:        fmt.Sprintf("w%d-b%d-r%d", w, b, i),
+								Status:    types.StatusSuccess,
+								Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+							}
+						}
+						if err := SaveBatch(batch); err != nil {
+							errs <- fmt.Errorf("writer %d: %w", w, err)
+							return
+						}
+					}
+				}(w)
+			}
+
+			done := make(chan struct{})
+			var reader sync.WaitGroup
+			reader.Add(1)
+			go func() {
+				defer reader.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					if _, err := QueryLogs(LogQueryFilters{}, 1, 50); err != nil {
+						errs <- fmt.Errorf("reader: %w", err)
+						return
+					}
+				}
+			}()
+
+			wg.Wait()
+			close(done)
+			reader.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+
+			result, err := QueryLogs(LogQueryFilters{}, 1, 1)
+			if err != nil {
+				t.Fatalf("QueryLogs: %v", err)
+			}
+			if want := writers * batches * batchSize; result.TotalCount != want {
+				t.Errorf("stored %d records, want %d", result.TotalCount, want)
+			}
+		})
+	}
+}
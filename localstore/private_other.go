@@ -0,0 +1,21 @@
+//go:build !unix
+
+package localstore
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkPrivateDir makes sure dir is a real directory rather than a link to somewhere else.
+// The temporary directory is per user on Windows, so ownership is not checked.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return nil
+}
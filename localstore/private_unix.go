@@ -0,0 +1,29 @@
+//go:build unix
+
+package localstore
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir makes sure dir, in a directory other users can write to, is a real
+// directory owned by the current user that nobody else can read or write. Another user
+// could otherwise create it first, or put a symlink there, and read or tamper with the logs.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by another user (uid %d)", dir, stat.Uid)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("%s has mode %o, want 700", dir, perm)
+	}
+	return nil
+}
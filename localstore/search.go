@@ -0,0 +1,302 @@
+package localstore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+	"modernc.org/sqlite"
+)
+
+// searchColumns are the columns LogQueryFilters.SearchTerm looks in, with the weight a match
+// in each adds to a SearchLogs score: a hit on the tool or method is more telling than one
+// somewhere in a payload. The full-text index (searchIndexTableName) has the same columns in
+// the same order.
+var searchColumns = []struct {
+	name   string
+	weight int
+}{
+	{"tool_name", 8},
+	{"mcp_method", 4},
+	{"error_details", 3},
+	{"tool_args", 2},
+	{"request_preview", 1},
+	{"response_preview", 1},
+	{"id", 1},
+}
+
+// searchScopes maps the "field:" prefixes a search term may start with to the column the rest
+// of the term is searched in, e.g. "tool:weather" only matches tool_name.
+var searchScopes = map[string]string{
+	"tool":     "tool_name",
+	"method":   "mcp_method",
+	"error":    "error_details",
+	"args":     "tool_args",
+	"request":  "request_preview",
+	"response": "response_preview",
+	"id":       "id",
+}
+
+// parseSearchTerm splits an optional scope off term, returning the column to search (""
+// for all search columns) and the text to search for. A prefix that is not a known scope,
+// as in "http://host", is part of the text.
+func parseSearchTerm(term string) (column string, text string) {
+	scope, rest, found := strings.Cut(term, ":")
+	if col, ok := searchScopes[strings.ToLower(strings.TrimSpace(scope))]; found && ok && strings.TrimSpace(rest) != "" {
+		return col, strings.TrimSpace(rest)
+	}
+	return "", term
+}
+
+// foldFunction is a SQL function lowercasing its argument with Unicode rules; SQLite's own
+// lower() and LIKE only fold ASCII letters.
+const foldFunction = "ithena_fold"
+
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction(foldFunction, 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return strings.ToLower(v), nil
+		case []byte:
+			return strings.ToLower(string(v)), nil
+		default:
+			return v, nil
+		}
+	})
+}
+
+// likePattern returns a LIKE pattern matching term anywhere, with LIKE wildcards in term
+// escaped (use with ESCAPE '\'). It is lowercased to match columns passed through foldFunction.
+func likePattern(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(term))
+	return "%" + escaped + "%"
+}
+
+// searchIndexTableName is the FTS5 table mirroring the search columns of the logs table. Rows
+// share the rowid of their log; they are added by SaveBatch and removed by a trigger.
+const searchIndexTableName = "logs_fts"
+
+// searchIndexReady reports whether the search index exists. It doesn't when the SQLite build
+// lacks FTS5, in which case searches fall back to LIKE.
+var searchIndexReady bool
+
+// createSearchIndex creates the full-text index with its delete trigger and fills it from the
+// existing logs. Without FTS5 support it logs a warning and leaves the schema unchanged;
+// ensureSearchIndex creates it on a later open once FTS5 is available.
+func createSearchIndex(tx *sql.Tx) error {
+	supported, err := createSearchIndexIfSupported(tx)
+	if err == nil && !supported {
+		log.Printf("LocalStore Warning: SQLite was built without FTS5; log search will use slower substring matching.")
+	}
+	return err
+}
+
+// ensureSearchIndex creates the full-text index if a database already at version 3 lacks it
+// because the migration ran without FTS5.
+func ensureSearchIndex() error {
+	if searchIndexExists(DB) {
+		return nil
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	supported, err := createSearchIndexIfSupported(tx)
+	if err != nil || !supported {
+		return err
+	}
+	if verbose { log.Printf("LocalStore: Created the full-text search index missing from an earlier migration") }
+	return tx.Commit()
+}
+
+// createSearchIndexIfSupported is createSearchIndex without the warning, reporting whether
+// the SQLite build supports FTS5.
+func createSearchIndexIfSupported(tx *sql.Tx) (bool, error) {
+	_, err := tx.Exec(fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(tool_name, mcp_method, error_details, tool_args, request_preview, response_preview, id);`, searchIndexTableName))
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create %s table: %w", searchIndexTableName, err)
+	}
+	_, err = tx.Exec(fmt.Sprintf(`
+	CREATE TRIGGER IF NOT EXISTS %[1]s_delete AFTER DELETE ON %[2]s BEGIN
+		DELETE FROM %[1]s WHERE rowid = old.rowid;
+	END;`, searchIndexTableName, logsTableName))
+	if err != nil {
+		return false, fmt.Errorf("failed to create %s delete trigger: %w", searchIndexTableName, err)
+	}
+	_, err = tx.Exec(fmt.Sprintf(`
+	INSERT INTO %[1]s (rowid, tool_name, mcp_method, error_details, tool_args, request_preview, response_preview, id)
+	SELECT rowid, tool_name, mcp_method, error_details, tool_args, request_preview, response_preview, id FROM %[2]s
+	WHERE rowid NOT IN (SELECT rowid FROM %[1]s);`, searchIndexTableName, logsTableName))
+	if err != nil {
+		return false, fmt.Errorf("failed to index existing logs: %w", err)
+	}
+	return true, nil
+}
+
+// searchIndexExists reports whether the full-text index table is present.
+func searchIndexExists(q queryer) bool {
+	var name string
+	err := q.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?;`, searchIndexTableName).Scan(&name)
+	return err == nil
+}
+
+// matchQuery turns a search term into an FTS5 query matching every word of it as a prefix,
+// e.g. `get weath` matches "get_weather". ok is false when the term has no word characters,
+// which FTS5 can't search for.
+func matchQuery(term string) (query string, ok bool) {
+	var phrases []string
+	for _, word := range strings.Fields(term) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(phrases, " "), len(phrases) > 0
+}
+
+// scopedMatchQuery is matchQuery for a term that may carry a scope (see parseSearchTerm),
+// restricting the FTS5 query to the scoped column.
+func scopedMatchQuery(term string) (query string, ok bool) {
+	column, text := parseSearchTerm(term)
+	query, ok = matchQuery(text)
+	if ok && column != "" {
+		query = fmt.Sprintf("{%s} : (%s)", column, query)
+	}
+	return query, ok
+}
+
+// searchClause returns a condition matching term, case-insensitively, in any search column
+// or in the column its scope names, and its args. It uses the full-text index when available
+// and LIKE otherwise. The index only matches words starting with the term, so when no log
+// has such a word the condition falls back to LIKE, and `ather` still finds "weather".
+func searchClause(term string) (string, []interface{}) {
+	if query, ok := scopedMatchQuery(term); ok && searchIndexReady {
+		likeClause, likeArgs := likeSearchClause(term)
+		clause := fmt.Sprintf("(CASE WHEN EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s MATCH ?) THEN id IN (SELECT id FROM %[1]s WHERE %[1]s MATCH ?) ELSE %[2]s END)", searchIndexTableName, likeClause)
+		return clause, append([]interface{}{query, query}, likeArgs...)
+	}
+	return likeSearchClause(term)
+}
+
+// likeSearchClause returns a condition matching term as a case-insensitive substring of any
+// search column, or of the column its scope names.
+func likeSearchClause(term string) (string, []interface{}) {
+	column, text := parseSearchTerm(term)
+	pattern := likePattern(text)
+	var conditions []string
+	var args []interface{}
+	for _, col := range searchColumns {
+		if column != "" && col.name != column {
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf(`%s(%s) LIKE ? ESCAPE '\'`, foldFunction, col.name))
+		args = append(args, pattern)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// SearchResult is a log matched by SearchLogs with its relevance score.
+type SearchResult struct {
+	Record types.AuditRecord `json:"record"`
+	Score  float64           `json:"score"` // Higher is more relevant: the weighted BM25 rank, or the sum of the weights of the matching columns without the full-text index
+}
+
+// SearchLogs returns up to limit logs matching query (case-insensitive, within filters),
+// ranked by relevance and then by recency. query overrides filters.SearchTerm and may be
+// scoped to one column like it (see parseSearchTerm). With the full-text index, words of
+// query match the start of words; when that finds nothing, query is matched as a substring.
+func SearchLogs(query string, filters LogQueryFilters, limit int) ([]SearchResult, error) {
+	if DB == nil {
+		return nil, errors.New("localstore: database not initialized")
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("localstore: empty search query")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	filters.SearchTerm = ""
+	if matchExpr, ok := scopedMatchQuery(query); ok && searchIndexReady {
+		results, err := searchLogsIndexed(matchExpr, filters, limit)
+		if err != nil || len(results) > 0 {
+			return results, err
+		}
+	}
+
+	whereStr, whereArgs := buildFilterClause(filters)
+	likeClause, likeArgs := likeSearchClause(query)
+	whereStr += " AND " + likeClause
+	whereArgs = append(whereArgs, likeArgs...)
+
+	column, text := parseSearchTerm(query)
+	pattern := likePattern(text)
+	var scoreTerms []string
+	var queryArgs []interface{}
+	for _, col := range searchColumns {
+		if column != "" && col.name != column {
+			continue
+		}
+		scoreTerms = append(scoreTerms, fmt.Sprintf(`(CASE WHEN %s(%s) LIKE ? ESCAPE '\' THEN %d ELSE 0 END)`, foldFunction, col.name, col.weight))
+		queryArgs = append(queryArgs, pattern)
+	}
+	queryArgs = append(queryArgs, whereArgs...)
+	queryArgs = append(queryArgs, limit)
+
+	sqlQuery := fmt.Sprintf("SELECT %s AS score, %s FROM %s WHERE %s ORDER BY score DESC, julianday(timestamp) DESC LIMIT ?",
+		strings.Join(scoreTerms, " + "), logSelectColumns, logsTableName, whereStr)
+	return querySearchResults(sqlQuery, queryArgs)
+}
+
+// searchLogsIndexed is SearchLogs using the full-text index, ranked by BM25 with the column
+// weights of searchColumns. The matches are joined back to the logs table by id.
+func searchLogsIndexed(matchExpr string, filters LogQueryFilters, limit int) ([]SearchResult, error) {
+	whereStr, whereArgs := buildFilterClause(filters)
+
+	weights := make([]string, len(searchColumns))
+	for i, col := range searchColumns {
+		weights[i] = fmt.Sprint(col.weight)
+	}
+	queryArgs := append([]interface{}{matchExpr}, whereArgs...)
+	queryArgs = append(queryArgs, limit)
+
+	sqlQuery := fmt.Sprintf(`SELECT -m.rank AS score, %[1]s FROM %[2]s
+	JOIN (SELECT id AS match_id, bm25(%[3]s, %[4]s) AS rank FROM %[3]s WHERE %[3]s MATCH ?) m ON m.match_id = %[2]s.id
+	WHERE %[5]s ORDER BY m.rank, julianday(timestamp) DESC LIMIT ?`,
+		logSelectColumns, logsTableName, searchIndexTableName, strings.Join(weights, ", "), whereStr)
+	return querySearchResults(sqlQuery, queryArgs)
+}
+
+// querySearchResults runs a search query whose first column is the score, followed by
+// logSelectColumns.
+func querySearchResults(sqlQuery string, queryArgs []interface{}) ([]SearchResult, error) {
+	rows, err := DB.Query(sqlQuery, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to search logs: %w", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var score float64
+		r, err := scanAuditRecord(prefixedScanner{row: rows, prefix: []interface{}{&score}})
+		if err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan search result row: %w", err)
+		}
+		results = append(results, SearchResult{Record: r, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating search results: %w", err)
+	}
+	return results, nil
+}
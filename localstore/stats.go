@@ -0,0 +1,182 @@
+package localstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// statsPercentiles are the duration percentiles reported by GetStats.
+var statsPercentiles = []float64{50, 90, 99}
+
+// statsTopTools is how many tools GetStats ranks.
+const statsTopTools = 5
+
+// StatusCount is the number of logs with one status value.
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// DurationPercentile is the duration at or below which Percent percent of logs completed.
+type DurationPercentile struct {
+	Percent    float64 `json:"percent"`
+	DurationMs int64   `json:"duration_ms"`
+}
+
+// Stats summarizes the logs matching a set of filters.
+type Stats struct {
+	Total         int                  `json:"total"`
+	ByStatus      []StatusCount        `json:"by_status"`       // Every status present, most frequent first
+	AvgDurationMs float64              `json:"avg_duration_ms"` // Over logs that have a duration
+	MaxDurationMs int64                `json:"max_duration_ms"`
+	Percentiles   []DurationPercentile `json:"percentiles"` // Nearest-rank percentiles; empty without durations
+	RequestBytes  int64                `json:"request_bytes"`
+	ResponseBytes int64                `json:"response_bytes"`
+	TopTools      []TopValue           `json:"top_tools"`      // Most called tools, with their byte totals
+	WindowMinutes float64              `json:"window_minutes"` // Length of the window ToolRates are averaged over
+	ToolRates     []ToolRate           `json:"tool_rates"`     // Busiest tools by call rate, highest first
+}
+
+// ToolRate is how often one tool was called over the stats window.
+type ToolRate struct {
+	Tool           string  `json:"tool"`
+	Calls          int     `json:"calls"`
+	CallsPerMinute float64 `json:"calls_per_minute"` // Calls divided by the window's length in minutes
+	PeakPerMinute  int     `json:"peak_per_minute"`  // Most calls within a single clock minute
+}
+
+// GetStats computes aggregate numbers over the logs matching filters.
+func GetStats(filters LogQueryFilters) (*Stats, error) {
+	if DB == nil {
+		return nil, errors.New("localstore: database not initialized")
+	}
+
+	whereStr, queryArgs := buildFilterClause(filters)
+	stats := &Stats{ByStatus: []StatusCount{}, Percentiles: []DurationPercentile{}}
+
+	var durationCount int
+	var avgDuration float64
+	err := DB.QueryRow(fmt.Sprintf(
+		"SELECT COUNT(*), COUNT(duration_ms), COALESCE(AVG(duration_ms), 0), COALESCE(MAX(duration_ms), 0), "+
+			"COALESCE(SUM(request_bytes), 0), COALESCE(SUM(response_bytes), 0) FROM %s WHERE %s", logsTableName, whereStr),
+		queryArgs...).Scan(&stats.Total, &durationCount, &avgDuration, &stats.MaxDurationMs, &stats.RequestBytes, &stats.ResponseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to compute log totals: %w", err)
+	}
+	stats.AvgDurationMs = avgDuration
+
+	rows, err := DB.Query(fmt.Sprintf(
+		"SELECT status, COUNT(*) AS cnt FROM %s WHERE %s GROUP BY status ORDER BY cnt DESC, status", logsTableName, whereStr),
+		queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to count logs by status: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sc StatusCount
+		if err := rows.Scan(&sc.Status, &sc.Count); err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan status count row: %w", err)
+		}
+		stats.ByStatus = append(stats.ByStatus, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating status count rows: %w", err)
+	}
+
+	// Nearest-rank percentiles: the ceil(p/100 * n)-th smallest duration, read with OFFSET so
+	// that only one row per percentile leaves the database.
+	percentileQuery := fmt.Sprintf(
+		"SELECT duration_ms FROM %s WHERE %s AND duration_ms IS NOT NULL ORDER BY duration_ms LIMIT 1 OFFSET ?", logsTableName, whereStr)
+	for _, p := range statsPercentiles {
+		if durationCount == 0 {
+			break
+		}
+		rank := int(math.Ceil(p / 100 * float64(durationCount)))
+		if rank < 1 {
+			rank = 1
+		}
+		var duration int64
+		if err := DB.QueryRow(percentileQuery, append(append([]interface{}{}, queryArgs...), rank-1)...).Scan(&duration); err != nil {
+			return nil, fmt.Errorf("localstore: failed to compute p%g duration: %w", p, err)
+		}
+		stats.Percentiles = append(stats.Percentiles, DurationPercentile{Percent: p, DurationMs: duration})
+	}
+
+	stats.TopTools, err = TopValues(TopByTool, filters, statsTopTools)
+	if err != nil {
+		return nil, err
+	}
+	if stats.WindowMinutes, err = statsWindowMinutes(filters, whereStr, queryArgs); err != nil {
+		return nil, err
+	}
+	if stats.ToolRates, err = toolRates(whereStr, queryArgs, stats.WindowMinutes, statsTopTools); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// statsWindowMinutes returns the length of the time window the stats cover, at least a minute:
+// from the filters' lower bound (or the first matching log) to their upper bound (or now when
+// only a lower bound is set, otherwise the last matching log).
+func statsWindowMinutes(filters LogQueryFilters, whereStr string, queryArgs []interface{}) (float64, error) {
+	var first, last sql.NullFloat64
+	err := DB.QueryRow(fmt.Sprintf("SELECT MIN(julianday(timestamp)), MAX(julianday(timestamp)) FROM %s WHERE %s", logsTableName, whereStr),
+		queryArgs...).Scan(&first, &last)
+	if err != nil {
+		return 0, fmt.Errorf("localstore: failed to compute stats window: %w", err)
+	}
+	if !first.Valid {
+		return 1, nil
+	}
+	start, end := julianDayTime(first.Float64), julianDayTime(last.Float64)
+
+	lowerBound := filters.Since
+	if t, err := time.Parse(time.RFC3339, filters.StartTime); err == nil && t.After(lowerBound) {
+		lowerBound = t
+	}
+	if !lowerBound.IsZero() {
+		start, end = lowerBound, time.Now()
+	}
+	if t, err := time.Parse(time.RFC3339, filters.EndTime); err == nil {
+		end = t
+	}
+	return math.Max(end.Sub(start).Minutes(), 1), nil
+}
+
+// julianDayTime converts a SQLite julian day number to a time.
+func julianDayTime(day float64) time.Time {
+	const unixEpochJulianDay = 2440587.5
+	return time.Unix(0, int64((day-unixEpochJulianDay)*24*float64(time.Hour))).UTC()
+}
+
+// toolRates counts each tool's calls per clock minute and returns the limit busiest tools
+// with their average rate over windowMinutes and their busiest minute.
+func toolRates(whereStr string, queryArgs []interface{}, windowMinutes float64, limit int) ([]ToolRate, error) {
+	rows, err := DB.Query(fmt.Sprintf(`
+		SELECT tool_name, SUM(cnt) AS calls, MAX(cnt) FROM (
+			SELECT tool_name, strftime('%%Y-%%m-%%dT%%H:%%M', timestamp) AS minute, COUNT(*) AS cnt
+			FROM %s WHERE %s AND tool_name IS NOT NULL AND tool_name != ''
+			GROUP BY tool_name, minute
+		) GROUP BY tool_name ORDER BY calls DESC, tool_name LIMIT ?`, logsTableName, whereStr),
+		append(append([]interface{}{}, queryArgs...), limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("localstore: failed to compute tool rates: %w", err)
+	}
+	defer rows.Close()
+	rates := []ToolRate{}
+	for rows.Next() {
+		var rate ToolRate
+		if err := rows.Scan(&rate.Tool, &rate.Calls, &rate.PeakPerMinute); err != nil {
+			return nil, fmt.Errorf("localstore: failed to scan tool rate row: %w", err)
+		}
+		rate.CallsPerMinute = float64(rate.Calls) / windowMinutes
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("localstore: error iterating tool rate rows: %w", err)
+	}
+	return rates, nil
+}
@@ -0,0 +1,34 @@
+package localstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// TestTopValuesByErrorSkipsSuccesses checks that logs without an error, whose error_details
+// SaveBatch stores as 'null', are not ranked as an error.
+func TestTopValuesByErrorSkipsSuccesses(t *testing.T) {
+	if err := InitDB(t.TempDir() + "/logs.db"); err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	defer DB.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	batch := []types.AuditRecord{
+		{ID: "ok", Status: types.StatusSuccess, Timestamp: now},
+		{ID: "failed", Status: types.StatusFailure, Timestamp: now, ErrorDetails: map[string]interface{}{"message": "Boom Error"}},
+	}
+	if err := SaveBatch(batch); err != nil {
+		t.Fatalf("SaveBatch: %v", err)
+	}
+
+	values, err := TopValues(TopByError, LogQueryFilters{}, 10)
+	if err != nil {
+		t.Fatalf("TopValues: %v", err)
+	}
+	if len(values) != 1 || values[0].Value != "Boom Error" || values[0].Count != 1 {
+		t.Fatalf("TopValues(error) = %+v, want only \"Boom Error\" once", values)
+	}
+}
@@ -6,17 +6,25 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"text/tabwriter" 
+	"time"
 
 	"github.com/fatih/color"
 
 	"github.com/ithena-one/Ithena/packages/cli/auth"
 	"github.com/ithena-one/Ithena/packages/cli/config"
+	"github.com/ithena-one/Ithena/packages/cli/httpclient"
 	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
 	"github.com/ithena-one/Ithena/packages/cli/placeholder"
 	"github.com/ithena-one/Ithena/packages/cli/wrapper"
-	"github.com/ithena-one/Ithena/packages/cli/cmd/logs" 
+	"github.com/ithena-one/Ithena/packages/cli/cmd/configcmd"
+	"github.com/ithena-one/Ithena/packages/cli/cmd/logs"
+	"github.com/ithena-one/Ithena/packages/cli/cmd/ping" 
+	"github.com/ithena-one/Ithena/packages/cli/cmd/shim"
 )
 
 
@@ -42,6 +50,70 @@ var (
 	// Version flag
 	showVersion bool
 
+	// Session metadata flag
+	probeVersion bool
+	captureEnv   bool
+
+	// Reload transform rules when the wrapper config file changes
+	watchConfig bool
+
+	// Log the routing decision for every record
+	traceDecisions bool
+
+	// Append the positional arguments to the profile's args instead of wrapping them as a command
+	appendArgs bool
+
+	// Encoding of observability uploads (json or msgpack)
+	wireFormat string
+
+	// Session summary written when the wrapper exits
+	reportFile string
+
+	// Local log database path
+	logDB string
+
+	// Tenant routing header for uploads
+	orgID string
+
+	// Fraction of successful records kept
+	sampleRate string
+
+	// Largest preview stored whole, in bytes
+	maxPreviewBytes string
+
+	// Exit with a distinct status when records were lost
+	failOnLogLoss bool
+
+	// Suppress the session summary printed on exit
+	quiet bool
+
+	// Print what would be run instead of running it
+	dryRun bool
+
+	// Save every record locally before queueing it for upload
+	durable bool
+
+	// Record JSON-RPC notifications, not just calls
+	recordNotifications bool
+
+	// Timeout of requests to the platform (0 means ITHENA_HTTP_TIMEOUT or 30s)
+	httpTimeout time.Duration
+
+	// Print the JSON schema of the wrapper config and exit
+	printConfigSchema bool
+
+	// JSON file describing the command to wrap (alternative to a profile or a direct command)
+	commandSpecFile string
+
+	// Suppress the "Storing logs locally" banner
+	quietLocalNotice bool
+
+	// Backend used for authentication (empty means ITHENA_BACKEND_URL or the production backend)
+	backendUrl string
+
+	// Grace period for the wrapped command after a forwarded signal (0 means ITHENA_SHUTDOWN_GRACE or 5s)
+	shutdownGrace time.Duration
+
 	// New logs command flags
 	logsShowPort int // Flag for 'logs show --port'
 )
@@ -49,6 +121,8 @@ var (
 // Command-level flag sets, accessible globally within the main package for printUsage
 var authCmd *flag.FlagSet
 var logsCmd *flag.FlagSet
+var configCmd *flag.FlagSet
+var shimCmd *flag.FlagSet
 
 // --- main function ---
 func main() {
@@ -61,18 +135,46 @@ func main() {
 
 	// === Subcommand definitions ===
 	authCmd = flag.NewFlagSet("auth", flag.ExitOnError)
-	authCmd.Usage = func() { printCommandUsage(authCmd, "auth", "Manage authentication. Available subcommands: login, status, deauth (logout)") }
+	authCmd.Usage = func() { printCommandUsage(authCmd, "auth", "Manage authentication. Available subcommands: login, status, whoami, deauth (logout)") }
 
 	logsCmd = flag.NewFlagSet("logs", flag.ExitOnError)
 	logsCmd.IntVar(&logsShowPort, "port", 8675, "Port for the local logs web UI (only for 'show' subcommand)")
-	logsCmd.Usage = func() { printCommandUsage(logsCmd, "logs", "Interact with local logs. Available subcommands: show, clear") }
+	logsCmd.Usage = func() { printCommandUsage(logsCmd, "logs", "Interact with local logs. Available subcommands: show, clear, export, push, top, stats, tail, search, get, replay-file, pause, resume, reconcile") }
+
+	configCmd = flag.NewFlagSet("config", flag.ExitOnError)
+	configCmd.Usage = func() { printCommandUsage(configCmd, "config", "Inspect the wrapper configuration file. Available subcommands: validate, list, schema") }
+
+	shimCmd = flag.NewFlagSet("shim", flag.ExitOnError)
+	shimCmd.Usage = func() { printCommandUsage(shimCmd, "shim", "Generate scripts that run a command under ithena-cli. Available subcommands: generate") }
 
 	// Global flags
 	flag.StringVar(&wrapperProfile, "wrapper-profile", "", "Name of the wrapper profile to use from the config file")
-	flag.StringVar(&wrapperConfigFile, "wrapper-config-file", defaultWrapperConfigFile, "Path to the wrapper configuration file (YAML)")
+	flag.StringVar(&wrapperConfigFile, "wrapper-config-file", defaultWrapperConfigFile, "Path to the wrapper configuration file (YAML, or JSON if the name ends in .json)")
+	flag.BoolVar(&appendArgs, "append-args", false, "With --wrapper-profile, append the arguments after '--' to the profile's args for this run")
 	flag.StringVar(&observeUrl, "observe-url", defaultObserveUrl, "URL for the observability API endpoint")
+	flag.StringVar(&commandSpecFile, "command-spec-file", "", "Path to a JSON file describing the command to wrap (command, args, env, cwd)")
 	flag.BoolVar(&verbose, "verbose", false, "Enable verbose logging output")
 	flag.BoolVar(&showVersion, "version", false, "Print version information and exit")
+	flag.BoolVar(&probeVersion, "probe-version", false, "Run the wrapped command once with --version and record the output in session metadata")
+	flag.BoolVar(&captureEnv, "capture-env", false, "Record OS/arch, a hash of PATH and allowlisted non-secret environment variables in session metadata")
+	flag.BoolVar(&watchConfig, "watch-config", false, "Reload the config's transform rules when the wrapper config file changes (profile mode only)")
+	flag.StringVar(&backendUrl, "backend-url", "", "Base URL of the Ithena backend used for authentication (default: $ITHENA_BACKEND_URL or https://ithena.one)")
+	flag.BoolVar(&quietLocalNotice, "quiet-local-notice", false, "Don't print the \"Storing logs locally\" notice when not authenticated (or set ITHENA_QUIET_LOCAL_NOTICE=true)")
+	flag.BoolVar(&traceDecisions, "trace-decisions", false, "Log where each record is routed (local, remote, dropped, dead-lettered) and why")
+	flag.DurationVar(&shutdownGrace, "shutdown-grace", 0, "How long the wrapped command may take to exit after a forwarded Ctrl+C/SIGTERM before it is killed (default: $ITHENA_SHUTDOWN_GRACE or 5s)")
+	flag.StringVar(&wireFormat, "wire-format", "", "Encoding of log uploads to the platform: json or msgpack (default: $ITHENA_WIRE_FORMAT or json)")
+	flag.StringVar(&reportFile, "report-file", "", "Write a JSON summary of the session (calls per status, per-tool durations, errors, exit code) to this file when the wrapped command exits")
+	flag.StringVar(&logDB, "log-db", "", "Path of the local log database (default: $ITHENA_LOG_DB or ithena-cli/local_logs.v1.db in the user config directory)")
+	flag.StringVar(&orgID, "org-id", "", "Organization ID sent with log uploads for multi-tenant backends (default: $ITHENA_ORG_ID)")
+	flag.StringVar(&sampleRate, "sample-rate", "", "Fraction of successful MCP calls to record, between 0 and 1; failures are always kept (default: $ITHENA_SAMPLE_RATE or 1)")
+	flag.StringVar(&maxPreviewBytes, "max-preview-bytes", "", "Largest request/response preview or tool arguments kept whole, in bytes of JSON; larger ones are truncated, 0 keeps everything (default: $ITHENA_MAX_PREVIEW_BYTES or 1048576)")
+	flag.DurationVar(&httpTimeout, "http-timeout", 0, "Timeout of each request to the Ithena platform: log uploads, ping and auth (default: $ITHENA_HTTP_TIMEOUT or 30s)")
+	flag.BoolVar(&failOnLogLoss, "fail-on-log-loss", false, "Exit with status 75 instead of 0 if any log record was dropped, failed to upload or could not be stored")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the command, arguments and environment (secrets masked) that would be run, after resolving placeholders, instead of running it")
+	flag.BoolVar(&quiet, "quiet", false, "Don't print the one-line session summary (requests, failures, average duration, logs uploaded/stored) when the wrapper exits")
+	flag.BoolVar(&durable, "durable", false, "Save every record to the local store before queueing it, so none is lost if the process is killed before upload")
+	flag.BoolVar(&recordNotifications, "record-notifications", false, "Record JSON-RPC notifications in both directions with status \"notification\" (or set record_notifications on the profile)")
+	flag.BoolVar(&printConfigSchema, "config-schema", false, "Print a JSON schema for the wrapper configuration file and exit (same as 'config schema')")
 	flag.Usage = printMainUsage
 
 	flag.Parse()
@@ -88,13 +190,61 @@ func main() {
 		}
 		os.Exit(0)
 	}
+	if printConfigSchema {
+		configcmd.HandleConfigSchemaCommand(nil)
+		os.Exit(0)
+	}
 
 	observability.SetVerbose(verbose)
+	observability.SetTraceDecisions(traceDecisions)
+	if err := observability.SetWireFormat(wireFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := observability.SetSampleRate(sampleRate); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := observability.SetMaxPreviewBytes(maxPreviewBytes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := observability.SetOrgID(orgID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := httpclient.SetTimeout(httpTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	auth.SetBackendBaseUrl(backendUrl)
+	observability.SetQuietLocalNotice(quietLocalNotice)
+	observability.SetDurable(durable)
 	wrapper.SetVerbose(verbose)
+	wrapper.SetProbeVersion(probeVersion)
+	wrapper.SetCaptureEnvironment(captureEnv)
+	wrapper.SetReportFile(reportFile)
+	wrapper.SetQuiet(quiet)
+	wrapper.SetDryRun(dryRun)
+	wrapper.SetShutdownGrace(shutdownGrace)
+	wrapper.SetFailOnLogLoss(failOnLogLoss)
+	wrapper.SetRecordNotifications(recordNotifications)
+	localstore.SetLogDBPath(logDB)
 	// localstore.SetVerbose(verbose) // Will be set if localstore is initialized
 
 	args := flag.Args() // Get all non-flag arguments
 
+	// With --append-args, the positional arguments extend the profile's args rather than naming a command to wrap.
+	var extraProfileArgs []string
+	if appendArgs {
+		if wrapperProfile == "" {
+			fmt.Fprintln(os.Stderr, "Error: --append-args can only be used with --wrapper-profile.")
+			exitWithError(1)
+		}
+		extraProfileArgs = args
+		args = nil
+	}
+
 	if len(args) > 0 {
 		command := args[0]
 		switch command {
@@ -109,6 +259,9 @@ func main() {
 				case "status":
 					if verbose { log.Println("Handling 'auth status' subcommand...") }
 					auth.HandleAuthStatusCommand()
+				case "whoami":
+					if verbose { log.Println("Handling 'auth whoami' subcommand...") }
+					auth.HandleWhoamiCommand(authCmd.Args()[1:])
 				case "deauth", "logout": // Allow 'logout' as an alias for 'deauth'
 					if verbose { log.Println("Handling 'auth deauth/logout' subcommand...") }
 					auth.HandleDeauthCommand()
@@ -132,11 +285,55 @@ func main() {
 					if verbose { log.Printf("Handling 'logs show' subcommand with port: %d", logsShowPort) }
 					// Pass the version, commit, and date variables to the logs show command
 					// Note: 'version' variable is populated by ldflags during build.
-					logs.HandleLogsShowCommand(verbose, logsShowPort, version)
+					logs.HandleLogsShowCommand(verbose, logsShowPort, version, logsCmd.Args()[1:])
 					return
 				case "clear":
 					if verbose { log.Println("Handling 'logs clear' subcommand...") }
-					logs.HandleLogsClearCommand(verbose)
+					logs.HandleLogsClearCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "export":
+					if verbose { log.Println("Handling 'logs export' subcommand...") }
+					logs.HandleLogsExportCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "push":
+					if verbose { log.Println("Handling 'logs push' subcommand...") }
+					logs.HandleLogsPushCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "top":
+					if verbose { log.Println("Handling 'logs top' subcommand...") }
+					logs.HandleLogsTopCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "stats":
+					if verbose { log.Println("Handling 'logs stats' subcommand...") }
+					logs.HandleLogsStatsCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "tail":
+					if verbose { log.Println("Handling 'logs tail' subcommand...") }
+					logs.HandleLogsTailCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "search":
+					if verbose { log.Println("Handling 'logs search' subcommand...") }
+					logs.HandleLogsSearchCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "get":
+					if verbose { log.Println("Handling 'logs get' subcommand...") }
+					logs.HandleLogsGetCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "replay-file":
+					if verbose { log.Println("Handling 'logs replay-file' subcommand...") }
+					logs.HandleLogsReplayFileCommand(verbose, wrapperConfigFile, logsCmd.Args()[1:])
+					return
+				case "pause":
+					if verbose { log.Println("Handling 'logs pause' subcommand...") }
+					logs.HandleLogsPauseCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "resume":
+					if verbose { log.Println("Handling 'logs resume' subcommand...") }
+					logs.HandleLogsResumeCommand(verbose, logsCmd.Args()[1:])
+					return
+				case "reconcile":
+					if verbose { log.Println("Handling 'logs reconcile' subcommand...") }
+					logs.HandleLogsReconcileCommand(verbose, observeUrl, wrapperConfigFile, logsCmd.Args()[1:])
 					return
 				default:
 					fmt.Fprintf(os.Stderr, "Error: Unknown subcommand for 'logs': %s\n", logsSubCommand)
@@ -147,8 +344,57 @@ func main() {
 				logsCmd.Usage() // Show help for 'logs' if no subcommand given
 				return
 			}
+		case "config":
+			configCmd.Parse(args[1:])
+			if configCmd.NArg() == 0 {
+				configCmd.Usage()
+				return
+			}
+			switch configCmd.Arg(0) {
+			case "validate":
+				if verbose { log.Println("Handling 'config validate' subcommand...") }
+				configcmd.HandleConfigValidateCommand(verbose, wrapperConfigFile, configCmd.Args()[1:])
+				return
+			case "list":
+				if verbose { log.Println("Handling 'config list' subcommand...") }
+				configcmd.HandleConfigListCommand(verbose, wrapperConfigFile, configCmd.Args()[1:])
+				return
+			case "schema":
+				if verbose { log.Println("Handling 'config schema' subcommand...") }
+				configcmd.HandleConfigSchemaCommand(configCmd.Args()[1:])
+				return
+			default:
+				fmt.Fprintf(os.Stderr, "Error: Unknown subcommand for 'config': %s\n", configCmd.Arg(0))
+				configCmd.Usage()
+				exitWithError(1)
+			}
+		case "shim":
+			shimCmd.Parse(args[1:])
+			if shimCmd.NArg() == 0 {
+				shimCmd.Usage()
+				return
+			}
+			switch shimCmd.Arg(0) {
+			case "generate":
+				if verbose { log.Println("Handling 'shim generate' subcommand...") }
+				shim.HandleShimGenerateCommand(verbose, shimCmd.Args()[1:])
+				return
+			default:
+				fmt.Fprintf(os.Stderr, "Error: Unknown subcommand for 'shim': %s\n", shimCmd.Arg(0))
+				shimCmd.Usage()
+				exitWithError(1)
+			}
+		case "ping":
+			if verbose { log.Println("Handling 'ping' command...") }
+			ping.HandlePingCommand(verbose, observeUrl, args[1:])
+			return
 		default:
-			// Not 'auth' or 'logs'. This is a command to wrap directly.
+			// Not 'auth', 'logs', 'config', 'shim' or 'ping'. This is a command to wrap directly.
+			if commandSpecFile != "" {
+				fmt.Fprintf(os.Stderr, "Error: Cannot specify a direct command ('%s') when --command-spec-file is also provided.\n", command)
+				printMainUsage()
+				exitWithError(1)
+			}
 			if wrapperProfile != "" {
 				fmt.Fprintf(os.Stderr,
 					"Error: Cannot specify a direct command ('%s') when --wrapper-profile ('%s') is also provided.\n"+
@@ -169,11 +415,20 @@ func main() {
 			// For direct wrapping, use empty env map and command itself as alias.
 			// This means the wrapped command won't inherit the parent environment directly through this map.
 			// If os.Environ() inheritance is desired, this part needs to be adjusted.
-			wrapper.Run(commandToWrap, commandArgs, make(map[string]string), commandToWrap, observeUrl)
+			if !dryRun { observability.ResendPending() }
+			wrapper.Run(commandToWrap, commandArgs, make(map[string]string), commandToWrap, observeUrl, wrapper.Options{})
 			return
 		}
 	} else {
 		// No positional arguments were given (e.g., `ithena-cli --wrapper-profile foo` or just `ithena-cli`)
+		if commandSpecFile != "" {
+			if wrapperProfile != "" {
+				fmt.Fprintln(os.Stderr, "Error: Cannot use --command-spec-file together with --wrapper-profile.")
+				exitWithError(1)
+			}
+			runCommandSpec(commandSpecFile)
+			return
+		}
 		if wrapperProfile == "" {
 			fmt.Fprintln(os.Stderr, "Error: No command or --wrapper-profile specified. Run 'ithena-cli --help' for usage.")
 			printMainUsage()
@@ -187,21 +442,233 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error loading wrapper config '%s': %v\n", wrapperConfigFile, err)
 			exitWithError(1)
 		}
+		observability.SetLocalOnlyMethods(wrapperConf.LocalOnlyMethods)
+		observability.SetToolExtraction(toolExtractionRulesFromConfig(wrapperConf.ToolExtraction, wrapperConf.ToolNameFields))
+		observability.SetTransformRules(transformRulesFromConfig(wrapperConf.Transforms))
+		localstore.SetIndexedFields(indexedFieldsFromConfig(wrapperConf.IndexedFields))
 		profile, found := wrapperConf.Wrappers[wrapperProfile]
 		if !found {
 			fmt.Fprintf(os.Stderr, "Error: Wrapper profile '%s' not found in config file '%s'\n", wrapperProfile, wrapperConfigFile)
 			exitWithError(1)
 		}
-		resolvedEnv, err := placeholder.ResolvePlaceholders(profile.Env)
+		fileProfile := profile // As written in the file, for --watch-config's change detection
+		otherProfiles := make([]string, 0, len(wrapperConf.Wrappers))
+		for name := range wrapperConf.Wrappers {
+			otherProfiles = append(otherProfiles, name)
+		}
+		applied, err := config.ApplyProfileOverrides(wrapperProfile, &profile, os.Environ(), otherProfiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error applying environment overrides to profile '%s': %v\n", wrapperProfile, err)
+			exitWithError(1)
+		}
+		for _, name := range applied {
+			log.Printf("Wrapper: Profile '%s' field overridden by %s", wrapperProfile, name)
+		}
+		if len(profile.Backends) > 0 && len(extraProfileArgs) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --append-args can't be used with profile '%s', which has several backends.\n", wrapperProfile)
+			exitWithError(1)
+		}
+		if len(profile.Backends) > 0 && profile.Command != "" {
+			fmt.Fprintf(os.Stderr, "Error: Wrapper profile '%s' sets both 'command' and 'backends'\n", wrapperProfile)
+			exitWithError(1)
+		}
+		if len(extraProfileArgs) > 0 {
+			profile.Args = append(append([]string{}, profile.Args...), extraProfileArgs...)
+			if verbose { log.Printf("Wrapper mode: Appended %d argument(s) to profile '%s': %v", len(extraProfileArgs), wrapperProfile, extraProfileArgs) }
+		}
+		if profile.Transport == wrapper.TransportSSE && profile.URL == "" {
+			fmt.Fprintf(os.Stderr, "Error: Wrapper profile '%s' uses transport 'sse' but has no 'url' set\n", wrapperProfile)
+			exitWithError(1)
+		}
+		resolvedEnv, resolutions, err := placeholder.ResolvePlaceholdersWithReport(profile.Env)
+		if verbose || dryRun { logPlaceholderResolutions(resolutions) }
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error resolving environment variable placeholders for profile '%s': %v\n", wrapperProfile, err)
 			exitWithError(1)
 		}
-		wrapper.Run(profile.Command, profile.Args, resolvedEnv, profile.Alias, observeUrl)
+		if watchConfig {
+			stopWatching := watchTransformRules(wrapperConfigFile, wrapperProfile, fileProfile)
+			defer stopWatching()
+		}
+		profileObserveUrl := observeUrl
+		if profile.ObserveURL != "" {
+			profileObserveUrl = profile.ObserveURL
+		}
+		observability.SetOffline(profile.Offline)
+		if verbose && profile.Offline { log.Printf("Profile '%s' is offline: logs are stored locally only.", wrapperProfile) }
+		if !dryRun { observability.ResendPending() }
+		opts := wrapper.Options{
+			Transport: profile.Transport,
+			URL:       profile.URL,
+			Framing:   profile.Framing,
+			Restart: wrapper.RestartPolicy{
+				Mode:           profile.Restart,
+				MaxRestarts:    profile.MaxRestarts,
+				InitialBackoff: profile.RestartBackoff.Initial,
+				MaxBackoff:     profile.RestartBackoff.Max,
+			},
+			CaptureStderr:  profile.CaptureStderr,
+			RequestTimeout: profile.RequestTimeout,
+			Streaming: wrapper.StreamingPolicy{
+				Methods:     profile.Streaming.Methods,
+				DonePointer: profile.Streaming.DonePointer,
+			},
+			RecordNotifications: profile.RecordNotifications,
+			MaskedEnvKeys:       placeholderKeys(resolutions),
+		}
+		if len(profile.Backends) > 0 {
+			wrapper.RunBackends(resolveBackends(wrapperProfile, profile.Backends, resolvedEnv, opts.MaskedEnvKeys), profileObserveUrl, opts)
+			return
+		}
+		wrapper.Run(profile.Command, profile.Args, resolvedEnv, profile.Alias, profileObserveUrl, opts)
 		return
 	}
 }
 
+// resolveBackends resolves the env placeholders of each backend of a profile and applies its
+// env over the profile's resolved env. profileMasked lists the profile's env keys set from
+// placeholders.
+func resolveBackends(profileName string, backends []config.BackendConfig, profileEnv map[string]string, profileMasked []string) []wrapper.Backend {
+	resolved := make([]wrapper.Backend, 0, len(backends))
+	for _, backend := range backends {
+		backendEnv, resolutions, err := placeholder.ResolvePlaceholdersWithReport(backend.Env)
+		if verbose || dryRun { logPlaceholderResolutions(resolutions) }
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving environment variable placeholders for backend '%s' of profile '%s': %v\n", backend.Alias, profileName, err)
+			exitWithError(1)
+		}
+		env := make(map[string]string, len(profileEnv)+len(backendEnv))
+		for key, value := range profileEnv {
+			env[key] = value
+		}
+		for key, value := range backendEnv {
+			env[key] = value
+		}
+		resolved = append(resolved, wrapper.Backend{
+			Alias:   backend.Alias,
+			Command: backend.Command,
+			Args:    backend.Args,
+			Env:     env,
+			Methods: backend.Methods,
+
+			MaskedEnvKeys: append(append([]string{}, profileMasked...), placeholderKeys(resolutions)...),
+		})
+	}
+	return resolved
+}
+
+// runCommandSpec wraps the command described by a JSON command spec file.
+func runCommandSpec(specFile string) {
+	if verbose { log.Printf("Wrapper mode: Using command spec '%s'", specFile) }
+	spec, err := config.LoadCommandSpec(specFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading command spec: %v\n", err)
+		exitWithError(1)
+	}
+	resolvedEnv, resolutions, err := placeholder.ResolvePlaceholdersWithReport(spec.Env)
+	if verbose || dryRun { logPlaceholderResolutions(resolutions) }
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving environment variable placeholders in command spec '%s': %v\n", specFile, err)
+		exitWithError(1)
+	}
+	if !dryRun { observability.ResendPending() }
+	wrapper.Run(spec.Command, spec.Args, resolvedEnv, spec.Alias, observeUrl, wrapper.Options{
+		Framing:       spec.Framing,
+		Dir:           spec.Cwd,
+		CaptureStderr: spec.CaptureStderr,
+		MaskedEnvKeys: placeholderKeys(resolutions),
+	})
+}
+
+// logPlaceholderResolutions prints the source type each env placeholder was resolved from,
+// and flags the ones that fell back to their default. It never prints the placeholder as
+// written, which may hold a default value or an exec command line; failures are reported by
+// the caller.
+func logPlaceholderResolutions(resolutions []placeholder.Resolution) {
+	for _, r := range resolutions {
+		switch {
+		case r.Err != nil:
+			log.Printf("Wrapper: env %s: %s placeholder could not be resolved", r.Key, r.Source)
+		case r.UsedDefault:
+			log.Printf("Wrapper Warning: env %s: the variable is unset or empty, so the placeholder's default was used", r.Key)
+		default:
+			log.Printf("Wrapper: env %s: resolved from %s", r.Key, r.Source)
+		}
+	}
+}
+
+// placeholderKeys returns the env keys whose values contain a placeholder, so --dry-run can
+// hide the resolved values.
+func placeholderKeys(resolutions []placeholder.Resolution) []string {
+	var keys []string
+	for i, r := range resolutions {
+		if i == 0 || r.Key != resolutions[i-1].Key {
+			keys = append(keys, r.Key)
+		}
+	}
+	return keys
+}
+
+// configWatchInterval is how often --watch-config checks the config file for changes.
+const configWatchInterval = 2 * time.Second
+
+// transformRulesFromConfig converts the config file's transforms section.
+func transformRulesFromConfig(tc config.TransformConfig) observability.TransformRules {
+	return observability.TransformRules{
+		SanitizeHomeDir:   tc.SanitizePaths,
+		StripPathPrefixes: tc.StripPathPrefixes,
+		RedactKeys:        tc.RedactKeys,
+		HashKeys:          tc.HashKeys,
+	}
+}
+
+// toolExtractionRulesFromConfig converts the config file's tool_extraction section. The
+// deprecated tool_name_fields map is converted into rules that set only the tool name,
+// sorted by method and placed after tool_extraction, which therefore wins where both match.
+func toolExtractionRulesFromConfig(rules []config.ToolExtractionRule, nameFields map[string]string) []observability.ToolExtractionRule {
+	converted := make([]observability.ToolExtractionRule, len(rules), len(rules)+len(nameFields))
+	for i, rule := range rules {
+		converted[i] = observability.ToolExtractionRule{Method: rule.Method, ToolName: rule.ToolName, ToolArgs: rule.ToolArgs}
+	}
+	if len(nameFields) == 0 {
+		return converted
+	}
+	methods := make([]string, 0, len(nameFields))
+	for method := range nameFields {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	log.Printf("Warning: tool_name_fields is deprecated; use tool_extraction instead (e.g. '- method: %s' with 'tool_name: /%s'). Its entries are applied after tool_extraction.", methods[0], strings.ReplaceAll(nameFields[methods[0]], ".", "/"))
+	for _, method := range methods {
+		converted = append(converted, observability.ToolExtractionRule{Method: method, ToolName: nameFields[method]})
+	}
+	return converted
+}
+
+func indexedFieldsFromConfig(fields []config.IndexedField) []localstore.IndexedField {
+	if fields == nil {
+		return nil
+	}
+	converted := make([]localstore.IndexedField, len(fields))
+	for i, field := range fields {
+		converted[i] = localstore.IndexedField{Name: field.Name, Source: field.Source, Pointer: field.Pointer}
+	}
+	return converted
+}
+
+// watchTransformRules reloads transform rules whenever the config file changes. Only the
+// transforms apply live; changes to the running profile are reported as needing a restart.
+func watchTransformRules(configFile, profileName string, running config.WrapperProfile) (stop func()) {
+	if verbose { log.Printf("Watching '%s' for transform rule changes...", configFile) }
+	return config.WatchWrapperConfig(configFile, configWatchInterval, func(conf *config.WrapperConfig) {
+		observability.SetTransformRules(transformRulesFromConfig(conf.Transforms))
+		log.Printf("Reloaded transform rules from '%s'.", configFile)
+		if updated, ok := conf.Wrappers[profileName]; ok && !reflect.DeepEqual(updated, running) {
+			log.Printf("Warning: Profile '%s' changed in '%s'; command, args, env and transport changes take effect after a restart.", profileName, configFile)
+		}
+	})
+}
+
 // exitWithError ensures observability shutdown before exiting with an error code.
 func exitWithError(code int) {
 	observability.ShutdownObservability() // Call shutdown explicitly
@@ -232,19 +699,60 @@ func printMainUsage() {
 	header.Fprintln(w, "Available Commands:")
 	fmt.Fprintf(w, "  %s\t\tManage authentication. Use 'ithena-cli auth <subcommand> --help' for details.\n", commandStyle.Sprint("auth"))
 	fmt.Fprintf(w, "  %s\t\tInteract with local logs. Use 'ithena-cli logs <subcommand> --help' for details.\n", commandStyle.Sprint("logs"))
+	fmt.Fprintf(w, "  %s\t\tCheck or list the profiles of the wrapper config. Use 'ithena-cli config <subcommand> --help' for details.\n", commandStyle.Sprint("config"))
+	fmt.Fprintf(w, "  %s\t\tGenerate a script that runs a command under ithena-cli, to put ahead of it on PATH.\n", commandStyle.Sprint("shim"))
+	fmt.Fprintf(w, "  %s\t\tSend a test record to the observe URL to verify connectivity and authentication.\n", commandStyle.Sprint("ping"))
 	fmt.Fprintln(w)
 
 	header.Fprintln(w, "Global Flags (applicable to wrapper modes and some commands):")
 	globalFlags := flag.NewFlagSet("global", flag.ContinueOnError) // Temporary set to iterate
 	// Re-declare global flags here for iteration purposes ONLY, do not assign to the actual variables.
 	// Their actual values are parsed from flag.CommandLine.
-	var tempWrapperProfile, tempWrapperConfigFile, tempObserveUrl string
-	var tempVerbose, tempShowVersion bool
+	var tempWrapperProfile, tempWrapperConfigFile, tempObserveUrl, tempBackendUrl, tempCommandSpecFile string
+	var tempVerbose, tempShowVersion, tempProbeVersion, tempWatchConfig, tempTraceDecisions, tempQuietLocalNotice bool
 	globalFlags.StringVar(&tempWrapperProfile, "wrapper-profile", "", "Name of the wrapper profile to use from the config file")
-	globalFlags.StringVar(&tempWrapperConfigFile, "wrapper-config-file", defaultWrapperConfigFile, "Path to the wrapper configuration file (YAML)")
+	globalFlags.StringVar(&tempWrapperConfigFile, "wrapper-config-file", defaultWrapperConfigFile, "Path to the wrapper configuration file (YAML, or JSON if the name ends in .json)")
+	var tempAppendArgs bool
+	globalFlags.BoolVar(&tempAppendArgs, "append-args", false, "With --wrapper-profile, append the arguments after '--' to the profile's args for this run")
 	globalFlags.StringVar(&tempObserveUrl, "observe-url", defaultObserveUrl, "URL for the observability API endpoint")
+	globalFlags.StringVar(&tempCommandSpecFile, "command-spec-file", "", "Path to a JSON file describing the command to wrap (command, args, env, cwd)")
 	globalFlags.BoolVar(&tempVerbose, "verbose", false, "Enable verbose logging output")
 	globalFlags.BoolVar(&tempShowVersion, "version", false, "Print version information and exit") // Added for help text
+	globalFlags.BoolVar(&tempProbeVersion, "probe-version", false, "Run the wrapped command once with --version and record the output in session metadata")
+	globalFlags.BoolVar(&tempWatchConfig, "watch-config", false, "Reload the config's transform rules when the wrapper config file changes (profile mode only)")
+	globalFlags.StringVar(&tempBackendUrl, "backend-url", "", "Base URL of the Ithena backend used for authentication (default: $ITHENA_BACKEND_URL or https://ithena.one)")
+	globalFlags.BoolVar(&tempQuietLocalNotice, "quiet-local-notice", false, "Don't print the \"Storing logs locally\" notice when not authenticated (or set ITHENA_QUIET_LOCAL_NOTICE=true)")
+	globalFlags.BoolVar(&tempTraceDecisions, "trace-decisions", false, "Log where each record is routed (local, remote, dropped, dead-lettered) and why")
+	var tempCaptureEnv bool
+	var tempWireFormat string
+	globalFlags.StringVar(&tempWireFormat, "wire-format", "", "Encoding of log uploads to the platform: json or msgpack (default: $ITHENA_WIRE_FORMAT or json)")
+	globalFlags.BoolVar(&tempCaptureEnv, "capture-env", false, "Record OS/arch, a hash of PATH and allowlisted non-secret environment variables in session metadata")
+	var tempReportFile string
+	globalFlags.StringVar(&tempReportFile, "report-file", "", "Write a JSON summary of the session (calls per status, per-tool durations, errors, exit code) to this file when the wrapped command exits")
+	var tempLogDB string
+	globalFlags.StringVar(&tempLogDB, "log-db", "", "Path of the local log database (default: $ITHENA_LOG_DB or ithena-cli/local_logs.v1.db in the user config directory)")
+	var tempOrgID string
+	globalFlags.StringVar(&tempOrgID, "org-id", "", "Organization ID sent with log uploads for multi-tenant backends (default: $ITHENA_ORG_ID)")
+	var tempSampleRate string
+	globalFlags.StringVar(&tempSampleRate, "sample-rate", "", "Fraction of successful MCP calls to record, between 0 and 1; failures are always kept (default: $ITHENA_SAMPLE_RATE or 1)")
+	var tempMaxPreviewBytes string
+	globalFlags.StringVar(&tempMaxPreviewBytes, "max-preview-bytes", "", "Largest request/response preview or tool arguments kept whole, in bytes of JSON; larger ones are truncated, 0 keeps everything (default: $ITHENA_MAX_PREVIEW_BYTES or 1048576)")
+	var tempHTTPTimeout time.Duration
+	globalFlags.DurationVar(&tempHTTPTimeout, "http-timeout", 0, "Timeout of each request to the Ithena platform: log uploads, ping and auth (default: $ITHENA_HTTP_TIMEOUT or 30s)")
+	var tempFailOnLogLoss bool
+	globalFlags.BoolVar(&tempFailOnLogLoss, "fail-on-log-loss", false, "Exit with status 75 instead of 0 if any log record was dropped, failed to upload or could not be stored")
+	var tempDryRun bool
+	globalFlags.BoolVar(&tempDryRun, "dry-run", false, "Print the command, arguments and environment (secrets masked) that would be run, after resolving placeholders, instead of running it")
+	var tempQuiet bool
+	globalFlags.BoolVar(&tempQuiet, "quiet", false, "Don't print the one-line session summary (requests, failures, average duration, logs uploaded/stored) when the wrapper exits")
+	var tempDurable bool
+	globalFlags.BoolVar(&tempDurable, "durable", false, "Save every record to the local store before queueing it, so none is lost if the process is killed before upload")
+	var tempConfigSchema bool
+	globalFlags.BoolVar(&tempConfigSchema, "config-schema", false, "Print a JSON schema for the wrapper configuration file and exit (same as 'config schema')")
+	var tempRecordNotifications bool
+	globalFlags.BoolVar(&tempRecordNotifications, "record-notifications", false, "Record JSON-RPC notifications in both directions with status \"notification\" (or set record_notifications on the profile)")
+	var tempShutdownGrace time.Duration
+	globalFlags.DurationVar(&tempShutdownGrace, "shutdown-grace", 0, "How long the wrapped command may take to exit after a forwarded Ctrl+C/SIGTERM before it is killed (default: $ITHENA_SHUTDOWN_GRACE or 5s)")
 	
 	globalFlags.VisitAll(func(f *flag.Flag) {
 		// Fetch the actual global flag from the main flag set to get its properties
@@ -267,13 +775,35 @@ func printCommandUsage(cmd *flag.FlagSet, name string, description string) {
 
 	if name == "logs" { 
 		fmt.Fprintln(os.Stderr, "Available subcommands for logs:")
-		fmt.Fprintln(os.Stderr, "  show\tDisplays locally stored MCP logs in a web interface.")
-		fmt.Fprintln(os.Stderr, "  clear\tDeletes all locally stored MCP logs.")
+		fmt.Fprintln(os.Stderr, "  show\tDisplays locally stored MCP logs in a web interface (--port <n>, --print-ready).")
+		fmt.Fprintln(os.Stderr, "  clear\tDeletes all locally stored MCP logs, or only those matching filters (--status <s>, --tool <t>, ...); --compact-only shrinks the file.")
+		fmt.Fprintln(os.Stderr, "  export\tWrites locally stored logs to stdout or a file (--format ndjson|csv|loki, --output <file>).")
+		fmt.Fprintln(os.Stderr, "  push\tSends locally stored logs to a Grafana Loki push endpoint (--loki-url <url>).")
+		fmt.Fprintln(os.Stderr, "  top\tRanks the most frequent errors, tools, methods or statuses (--by error|tool|method|status, --since 24h).")
+		fmt.Fprintln(os.Stderr, "  stats\tSummarizes logs: counts by status, duration percentiles, bytes and top tools (--since 24h).")
+		fmt.Fprintln(os.Stderr, "  tail\tPrints new logs as they are stored, like tail -f (--filter-status failure).")
+		fmt.Fprintln(os.Stderr, "  search\tFinds logs mentioning some text, best matches first (search [--limit N] \"query\").")
+		fmt.Fprintln(os.Stderr, "  get\tPrints one log with its payloads as indented JSON (get [--json] <id>).")
+		fmt.Fprintln(os.Stderr, "  replay-file\tReplays the requests of an exported log bundle against a profile (replay-file <bundle> --wrapper-profile <name> [--diff]).")
+		fmt.Fprintln(os.Stderr, "  pause\tStops recording in every running wrapper until 'logs resume'; the MCP traffic is unaffected.")
+		fmt.Fprintln(os.Stderr, "  resume\tResumes recording after 'logs pause'.")
+		fmt.Fprintln(os.Stderr, "  reconcile\tLists local logs the platform has not received; --upload sends them again.")
+		fmt.Fprintln(os.Stderr)
+	} else if name == "config" {
+		fmt.Fprintln(os.Stderr, "Available subcommands for config:")
+		fmt.Fprintln(os.Stderr, "  validate\tChecks every profile of the wrapper config without running anything (--file <path>).")
+		fmt.Fprintln(os.Stderr, "  list\tLists the profiles with their command, args, alias and env keys, never env values (--json).")
+		fmt.Fprintln(os.Stderr, "  schema\tPrints a JSON schema for the wrapper config, for editor completion and checks.")
+		fmt.Fprintln(os.Stderr)
+	} else if name == "shim" {
+		fmt.Fprintln(os.Stderr, "Available subcommands for shim:")
+		fmt.Fprintln(os.Stderr, "  generate\tPrints or writes (--output <path>) a script running <command> under ithena-cli with all its args.")
 		fmt.Fprintln(os.Stderr)
 	} else if name == "auth" {
 		fmt.Fprintln(os.Stderr, "Available subcommands for auth:")
 		fmt.Fprintln(os.Stderr, "  login\tInitiate the device authorization flow to log in.")
 		fmt.Fprintln(os.Stderr, "  status\tCheck the current authentication status.")
+		fmt.Fprintln(os.Stderr, "  whoami\tAsk the backend for the logged-in account: email, org and token scopes (--json).")
 		fmt.Fprintln(os.Stderr, "  deauth\tLog out and remove locally stored authentication token.")
 		fmt.Fprintln(os.Stderr, "  logout\tAlias for 'deauth'.")
 		fmt.Fprintln(os.Stderr)
@@ -289,7 +819,7 @@ func printCommandUsage(cmd *flag.FlagSet, name string, description string) {
 		cmd.PrintDefaults() // Use the command's PrintDefaults for its specific flags
 		w.Flush()
 		fmt.Fprintln(os.Stderr)
-	} else if name != "logs" && name != "auth" { // Only print if no flags AND not a command group like 'logs'
+	} else if name != "logs" && name != "auth" && name != "config" && name != "shim" { // Only print if no flags AND not a command group like 'logs'
 		fmt.Fprintln(os.Stderr, "This command takes no flags.")
 	}
 }
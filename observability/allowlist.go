@@ -0,0 +1,83 @@
+package observability
+
+import (
+	"bufio"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// allowedObserveUrlsEnv holds a comma-separated list of observe URLs that uploads may target.
+// When set, it takes precedence over the system allowlist file.
+const allowedObserveUrlsEnv = "ITHENA_ALLOWED_OBSERVE_URLS"
+
+// systemAllowlistFile is an optional, admin-managed file with one allowed observe URL per line.
+// Blank lines and lines starting with '#' are ignored.
+var systemAllowlistFile = "/etc/ithena-cli/allowed-observe-urls"
+
+var (
+	allowlistOnce      sync.Once
+	allowedObserveUrls []*url.URL // nil means no allowlist is configured (everything allowed)
+	allowlistWarnOnce  sync.Once
+)
+
+// loadAllowlist reads the allowlist from the environment or the system file.
+func loadAllowlist() {
+	var entries []string
+	source := ""
+	if envVal, ok := os.LookupEnv(allowedObserveUrlsEnv); ok {
+		entries = strings.Split(envVal, ",")
+		source = allowedObserveUrlsEnv
+	} else if f, err := os.Open(systemAllowlistFile); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			entries = append(entries, scanner.Text())
+		}
+		f.Close()
+		source = systemAllowlistFile
+	} else {
+		return // No allowlist configured
+	}
+
+	allowedObserveUrls = []*url.URL{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		parsed, err := url.Parse(entry)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			log.Printf("Observability Warning: Ignoring invalid allowlist entry '%s' from %s", entry, source)
+			continue
+		}
+		allowedObserveUrls = append(allowedObserveUrls, parsed)
+	}
+	if verbose { log.Printf("Observability: Loaded %d allowed observe URL(s) from %s", len(allowedObserveUrls), source) }
+}
+
+// isObserveUrlAllowed reports whether uploads to observeUrl are permitted by the allowlist.
+// An entry matches when scheme and host are equal and the target path starts with the entry's path.
+// If no allowlist is configured, every URL is allowed.
+func isObserveUrlAllowed(observeUrl string) bool {
+	allowlistOnce.Do(loadAllowlist)
+	if allowedObserveUrls == nil {
+		return true
+	}
+
+	target, err := url.Parse(observeUrl)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range allowedObserveUrls {
+		if !strings.EqualFold(allowed.Scheme, target.Scheme) || !strings.EqualFold(allowed.Host, target.Host) {
+			continue
+		}
+		allowedPath := strings.TrimSuffix(allowed.Path, "/")
+		if allowedPath == "" || target.Path == allowedPath || strings.HasPrefix(target.Path, allowedPath+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package observability
+
+import (
+	"bytes"
+	"compress/gzip"
+	"log"
+	"os"
+	"strings"
+)
+
+// disableGzipEnv turns off payload compression, for backends that do not accept gzip bodies.
+const disableGzipEnv = "ITHENA_DISABLE_GZIP"
+
+// gzipMinBytes is the smallest payload worth compressing; below it gzip's overhead dominates.
+const gzipMinBytes = 1024
+
+// compressPayload gzips payload when it is large enough and compression is not disabled.
+// It returns the bytes to send and the Content-Encoding to declare ("" for uncompressed).
+func compressPayload(payload []byte) ([]byte, string) {
+	if len(payload) < gzipMinBytes || strings.EqualFold(strings.TrimSpace(os.Getenv(disableGzipEnv)), "true") {
+		return payload, ""
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		log.Printf("Observability Warning: Failed to gzip payload, sending uncompressed: %v", err)
+		return payload, ""
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("Observability Warning: Failed to gzip payload, sending uncompressed: %v", err)
+		return payload, ""
+	}
+	if verbose { log.Printf("Observability: Compressed payload from %d to %d bytes", len(payload), buf.Len()) }
+	return buf.Bytes(), "gzip"
+}
@@ -0,0 +1,25 @@
+package observability
+
+import "sync/atomic"
+
+// uploadedRecords counts records of this session delivered to the platform.
+var uploadedRecords atomic.Int64
+
+// storedRecords counts records of this session saved to the local store, including records
+// kept locally after a failed upload.
+var storedRecords atomic.Int64
+
+// LogDelivery counts where the records of the session ended up.
+type LogDelivery struct {
+	Uploaded      int64 // Delivered to the platform
+	StoredLocally int64 // Saved to the local store
+}
+
+// SessionLogDelivery returns the record deliveries since InitObservability. Call it after
+// ShutdownObservability to include the final flush.
+func SessionLogDelivery() LogDelivery {
+	return LogDelivery{
+		Uploaded:      uploadedRecords.Load(),
+		StoredLocally: storedRecords.Load(),
+	}
+}
@@ -0,0 +1,63 @@
+package observability
+
+import (
+	"log"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// durable makes SendLog write every record to the local store before queueing it; see SetDurable.
+var durable bool
+
+// SetDurable enables durable mode: each record is saved to the local store synchronously, before
+// it is queued, and records bound for the platform are also journaled in the pending upload
+// queue. Uploading stays asynchronous; a delivered record loses its pending_upload flag. A record
+// is thus never lost once SendLog returns, even if the process is killed before the worker flushes
+// it, at the cost of one local write per record.
+func SetDurable(v bool) {
+	durable = v
+}
+
+// saveDurably stores record locally before it is queued. Records that would be uploaded when
+// authenticated are flagged pending_upload and journaled, so a later run re-sends them if this
+// one never does. It reports whether the record was saved; on failure the record still goes
+// through the queue as usual.
+func saveDurably(record types.AuditRecord, observeUrl string) bool {
+	if !ensureLocalDB() {
+		return false
+	}
+	dest, _ := routeRecord(record, observeUrl, true)
+	if dest == destinationRemote {
+		if err := localstore.SavePending([]types.AuditRecord{record}, observeUrl, pendingClaimID); err != nil {
+			log.Printf("Observability Warning: Failed to journal Record ID %s before queueing: %v", record.ID, err)
+			return false
+		}
+		record.PendingUpload = true
+	}
+	if err := localstore.SaveBatch([]types.AuditRecord{record}); err != nil {
+		log.Printf("Observability Warning: Failed to save Record ID %s before queueing: %v", record.ID, err)
+		if dest == destinationRemote {
+			localstore.DeletePending([]string{record.ID})
+		}
+		return false
+	}
+	if verbose { log.Printf("Observability: Saved Record ID %s locally before queueing (durable mode).", record.ID) }
+	return true
+}
+
+// settleDurable updates the durable copies of records that were delivered, or that were routed
+// to the local store after all (e.g. when not authenticated): they leave the pending upload
+// queue and lose their pending_upload flag.
+func settleDurable(batch []types.AuditRecord) {
+	if !durable || len(batch) == 0 {
+		return
+	}
+	ids := recordIDs(batch)
+	if err := localstore.DeletePending(ids); err != nil {
+		log.Printf("Observability Warning: Failed to remove settled records from the pending queue: %v", err)
+	}
+	if err := localstore.MarkUploaded(ids); err != nil {
+		log.Printf("Observability Warning: Failed to clear pending_upload on settled records: %v", err)
+	}
+}
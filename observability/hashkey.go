@@ -0,0 +1,97 @@
+package observability
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// hashKeyEnv sets the key of hashed preview values, so several installs hash alike.
+const hashKeyEnv = "ITHENA_HASH_KEY"
+
+// hashKeyFileName holds the per-install key in the ithena-cli config directory.
+const hashKeyFileName = "hash.key"
+
+// hashKeyBytes is the size of a generated key.
+const hashKeyBytes = 32
+
+var (
+	hashKeyOnce sync.Once
+	hashKey     []byte
+)
+
+// previewHashKey returns the key hashValue uses: ITHENA_HASH_KEY, else the install's key,
+// created on first use. Without a config directory a key for this process alone is used, so
+// values can then only be correlated within one run.
+func previewHashKey() []byte {
+	hashKeyOnce.Do(func() {
+		if value := strings.TrimSpace(os.Getenv(hashKeyEnv)); value != "" {
+			hashKey = []byte(value)
+			return
+		}
+		key, err := loadInstallHashKey()
+		if err != nil {
+			log.Printf("Observability Warning: Failed to load the key for hashed values (%v); using one for this run only, so hashes won't match other runs.", err)
+			key = make([]byte, hashKeyBytes)
+			rand.Read(key)
+		}
+		hashKey = key
+	})
+	return hashKey
+}
+
+// loadInstallHashKey reads the install's key, creating it if there is none. A new key is
+// written to a temporary file and linked into place, so concurrent wrappers agree on one.
+func loadInstallHashKey() ([]byte, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	dir := filepath.Join(configDir, "ithena-cli")
+	path := filepath.Join(dir, hashKeyFileName)
+	if key, err := readHashKey(path); err == nil || !os.IsNotExist(err) {
+		return key, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	key := make([]byte, hashKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, hashKeyFileName+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(hex.EncodeToString(key))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Link(tmp.Name(), path); err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+	// Another process may have linked its key first; use whichever is in place.
+	return readHashKey(path)
+}
+
+func readHashKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid key in %s", path)
+	}
+	return key, nil
+}
@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// observeHeadersEnv adds headers to every upload, as comma-separated name=value pairs, e.g. for
+// an auth proxy in front of a self-hosted collector: ITHENA_OBSERVE_HEADERS="X-Team-Id=core".
+const observeHeadersEnv = "ITHENA_OBSERVE_HEADERS"
+
+// reservedObserveHeaders are set by sendPayload itself and cannot be overridden.
+var reservedObserveHeaders = []string{"Authorization", "Content-Type", "Content-Encoding", OrgIDHeader}
+
+// observeHeaders are the extra upload headers, read from observeHeadersEnv by InitObservability.
+var observeHeaders http.Header
+
+// observeHeadersFromEnv parses observeHeadersEnv. Malformed entries are skipped with a warning.
+func observeHeadersFromEnv() http.Header {
+	value := strings.TrimSpace(os.Getenv(observeHeadersEnv))
+	if value == "" {
+		return nil
+	}
+	headers := make(http.Header)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, headerValue, ok := strings.Cut(entry, "=")
+		name, headerValue = strings.TrimSpace(name), strings.TrimSpace(headerValue)
+		if !ok || !validHeaderName(name) || !validHeaderValue(headerValue) {
+			log.Printf("Observability Warning: Ignoring malformed %s entry '%s' (expected Name=value)", observeHeadersEnv, entry)
+			continue
+		}
+		if isReservedObserveHeader(name) {
+			log.Printf("Observability Warning: Ignoring %s entry '%s': %s is set by ithena-cli", observeHeadersEnv, entry, http.CanonicalHeaderKey(name))
+			continue
+		}
+		headers.Set(name, headerValue)
+	}
+	if verbose && len(headers) > 0 { log.Printf("Observability: Sending %d extra header(s) from %s with uploads", len(headers), observeHeadersEnv) }
+	return headers
+}
+
+// validHeaderName reports whether name is a non-empty HTTP token (RFC 9110, section 5.1).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > 0x7e || !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validHeaderValue rejects control characters, which would let a value split the header.
+func validHeaderValue(value string) bool {
+	for _, r := range value {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func isReservedObserveHeader(name string) bool {
+	for _, reserved := range reservedObserveHeaders {
+		if strings.EqualFold(name, reserved) {
+			return true
+		}
+	}
+	return false
+}
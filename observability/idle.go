@@ -0,0 +1,82 @@
+package observability
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// idleAfterEnv configures how long the log worker must see no new logs before it starts backing
+// off its flush ticker. Accepts a Go duration; "0" disables the backoff.
+const idleAfterEnv = "ITHENA_LOG_WORKER_IDLE_AFTER"
+
+const (
+	defaultIdleAfter    = 1 * time.Minute
+	maxIdleTickInterval = 5 * time.Minute
+)
+
+// tickerBackoff tracks the sender's ticker interval. Once nothing has been logged for
+// idleAfter and the buffer is empty, each tick doubles the interval up to max; the next
+// log restores the normal interval so batching cadence is unaffected under traffic.
+type tickerBackoff struct {
+	normal       time.Duration
+	max          time.Duration
+	idleAfter    time.Duration
+	current      time.Duration
+	lastActivity time.Time
+}
+
+func newTickerBackoff(normal time.Duration, now time.Time) *tickerBackoff {
+	maxInterval := maxIdleTickInterval
+	if maxInterval < normal {
+		maxInterval = normal
+	}
+	return &tickerBackoff{
+		normal:       normal,
+		max:          maxInterval,
+		idleAfter:    idleAfterFromEnv(),
+		current:      normal,
+		lastActivity: now,
+	}
+}
+
+// activity records a new log. It reports whether the ticker must be reset to b.current.
+func (b *tickerBackoff) activity(now time.Time) bool {
+	b.lastActivity = now
+	if b.current == b.normal {
+		return false
+	}
+	b.current = b.normal
+	return true
+}
+
+// tick is called on every ticker fire. It reports whether the ticker must be reset to b.current.
+func (b *tickerBackoff) tick(now time.Time, bufferEmpty bool) bool {
+	if b.idleAfter <= 0 || !bufferEmpty || now.Sub(b.lastActivity) < b.idleAfter {
+		return false
+	}
+	next := b.current * 2
+	if next > b.max {
+		next = b.max
+	}
+	if next == b.current {
+		return false
+	}
+	b.current = next
+	return true
+}
+
+// idleAfterFromEnv reads the idle threshold, falling back to the default on invalid input.
+func idleAfterFromEnv() time.Duration {
+	value := strings.TrimSpace(os.Getenv(idleAfterEnv))
+	if value == "" {
+		return defaultIdleAfter
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("Observability Warning: Ignoring invalid %s value '%s'", idleAfterEnv, value)
+		return defaultIdleAfter
+	}
+	return d
+}
@@ -0,0 +1,58 @@
+package observability
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTickerBackoff checks the interval stays normal until the worker has been idle for
+// idleAfter with an empty buffer, then doubles on each tick up to max, and that a new log
+// restores it.
+func TestTickerBackoff(t *testing.T) {
+	t.Setenv(idleAfterEnv, "1m")
+	start := time.Now()
+	b := newTickerBackoff(time.Minute, start)
+	if b.max != maxIdleTickInterval {
+		t.Fatalf("max = %s, want %s", b.max, maxIdleTickInterval)
+	}
+
+	if b.tick(start.Add(30*time.Second), true) || b.current != time.Minute {
+		t.Fatalf("backed off before idleAfter: current = %s", b.current)
+	}
+	idle := start.Add(2 * time.Minute)
+	if b.tick(idle, false) || b.current != time.Minute {
+		t.Fatalf("backed off with a non-empty buffer: current = %s", b.current)
+	}
+
+	for _, want := range []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		if !b.tick(idle, true) {
+			t.Fatalf("tick did not ask for a reset to %s", want)
+		}
+		if b.current != want {
+			t.Fatalf("current = %s, want %s", b.current, want)
+		}
+	}
+	if b.tick(idle, true) || b.current != maxIdleTickInterval {
+		t.Fatalf("went past max: current = %s", b.current)
+	}
+
+	if !b.activity(idle) || b.current != time.Minute {
+		t.Fatalf("activity did not restore the normal interval: current = %s", b.current)
+	}
+	if b.activity(idle) {
+		t.Fatal("activity asked for a reset while already at the normal interval")
+	}
+	if b.tick(idle.Add(30*time.Second), true) {
+		t.Fatal("backed off again before idleAfter since the last log")
+	}
+}
+
+// TestTickerBackoffDisabled checks that an idleAfter of 0 turns the backoff off.
+func TestTickerBackoffDisabled(t *testing.T) {
+	t.Setenv(idleAfterEnv, "0")
+	start := time.Now()
+	b := newTickerBackoff(time.Second, start)
+	if b.tick(start.Add(time.Hour), true) || b.current != time.Second {
+		t.Fatalf("backed off while disabled: current = %s", b.current)
+	}
+}
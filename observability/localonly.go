@@ -0,0 +1,65 @@
+package observability
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// localOnlyMethodsEnv lists MCP methods (comma-separated) whose records must never leave
+// this machine. An entry ending in "*" matches every method with that prefix, e.g. "resources/*".
+const localOnlyMethodsEnv = "ITHENA_LOCAL_ONLY_METHODS"
+
+// localOnlyRules is the compiled set of local-only method patterns.
+type localOnlyRules struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+var localOnly atomic.Pointer[localOnlyRules]
+
+// SetLocalOnlyMethods configures the methods whose records are always stored locally and never
+// uploaded, even when authenticated. They are added to any listed in ITHENA_LOCAL_ONLY_METHODS.
+func SetLocalOnlyMethods(methods []string) {
+	all := append(splitMethodList(os.Getenv(localOnlyMethodsEnv)), methods...)
+	rules := &localOnlyRules{exact: make(map[string]bool)}
+	for _, method := range all {
+		method = strings.TrimSpace(method)
+		switch {
+		case method == "":
+		case strings.HasSuffix(method, "*"):
+			rules.prefixes = append(rules.prefixes, strings.TrimSuffix(method, "*"))
+		default:
+			rules.exact[method] = true
+		}
+	}
+	localOnly.Store(rules)
+}
+
+// isLocalOnly reports whether record must not be uploaded.
+func isLocalOnly(record types.AuditRecord) bool {
+	rules := localOnly.Load()
+	if rules == nil || record.McpMethod == nil {
+		return false
+	}
+	method := *record.McpMethod
+	if rules.exact[method] {
+		return true
+	}
+	for _, prefix := range rules.prefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitMethodList splits a comma-separated method list.
+func splitMethodList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,32 @@
+package observability
+
+import "sync/atomic"
+
+// failedUploads counts records of this session whose upload failed for good. They are usually
+// kept locally, flagged pending_upload, but they did not reach the platform.
+var failedUploads atomic.Int64
+
+// unstoredRecords counts records meant for the local store that could not be saved.
+var unstoredRecords atomic.Int64
+
+// LogLoss counts the records of the session that did not reach their destination.
+type LogLoss struct {
+	Dropped       int64 // Discarded because the log channel was full or already closed
+	FailedUploads int64 // Upload failed; kept locally for a later run when possible
+	Unstored      int64 // Meant for the local store, which could not save them
+}
+
+// Total returns the number of records affected.
+func (l LogLoss) Total() int64 {
+	return l.Dropped + l.FailedUploads + l.Unstored
+}
+
+// SessionLogLoss returns the log loss since InitObservability. Call it after
+// ShutdownObservability to include the final flush.
+func SessionLogLoss() LogLoss {
+	return LogLoss{
+		Dropped:       droppedRecords.Load(),
+		FailedUploads: failedUploads.Load(),
+		Unstored:      unstoredRecords.Load(),
+	}
+}
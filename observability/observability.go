@@ -3,18 +3,17 @@ package observability
 import (
 	"bytes"
 	// "crypto/tls" // Unused
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os" // For os.Stderr for info message
 	"sync" 
 	"time"
 
-	"github.com/fatih/color" // For colored output
 	"github.com/google/uuid"
 	"github.com/ithena-one/Ithena/packages/cli/auth" 
+	"github.com/ithena-one/Ithena/packages/cli/httpclient"
 	"github.com/ithena-one/Ithena/packages/cli/jsonrpc" 
 	"github.com/ithena-one/Ithena/packages/cli/localstore" // Import for local storage
 	"github.com/ithena-one/Ithena/packages/cli/types" // Import the new types package
@@ -38,7 +37,7 @@ var (
 )
 
 const (
-	logChannelBufferSize = 100 
+	logChannelBufferSize = 100 // Default capacity of the log channel, see ITHENA_LOG_BUFFER_SIZE
 	defaultBatchSize     = 20  
 	defaultBatchInterval = 15 * time.Second 
 )
@@ -52,7 +51,7 @@ var (
 	logChan      chan logJob      
 	wg           sync.WaitGroup 
 	bufferMutex  sync.Mutex     
-	logBuffer    []types.AuditRecord  // Use types.AuditRecord
+	logBuffer    []types.AuditRecord  // Records waiting to be flushed; not journaled (see journalPending)
 	lastSentTime time.Time      
 	batchSize     = defaultBatchSize
 	batchInterval = defaultBatchInterval
@@ -61,28 +60,75 @@ var (
 	// For local logging mode message and DB init
 	localLogInfoOnce sync.Once
 	localDBInitOnce  sync.Once
+	localDBInitErr   error
+
+	// sessionMetadata is attached to every record queued by this process, if set.
+	sessionMetadata *types.SessionMetadata
+
+	// recordListener, if set, sees every record passed to SendLog or SendFinalLog.
+	recordListener func(types.AuditRecord)
 )
 
+// SetSessionMetadata sets the session information attached to all subsequently queued records.
+func SetSessionMetadata(meta *types.SessionMetadata) {
+	applySampleRate(meta)
+	sessionMetadata = meta
+}
+
+// SetRecordListener registers fn to be called with every record passed to SendLog or
+// SendFinalLog, whether or not it is later uploaded, stored or dropped. fn runs on the
+// caller's goroutine and must be safe for concurrent use.
+func SetRecordListener(fn func(types.AuditRecord)) {
+	recordListener = fn
+}
+
 func InitObservability() {
-	logChan = make(chan logJob, logChannelBufferSize)
+	logChan = make(chan logJob, logBufferSizeFromEnv())
+	logChanClosed.Store(false)
+	shuttingDown = make(chan struct{})
+	shutdownStarted.Store(false)
+	droppedRecords.Store(0)
+	failedUploads.Store(0)
+	unstoredRecords.Store(0)
+	fullQueuePolicy = queueFullPolicyFromEnv()
+	observeHeaders = observeHeadersFromEnv()
+	syslogSink = syslogExporterFromEnv()
 	logBuffer = make([]types.AuditRecord, 0, batchSize) 
 	lastSentTime = time.Now()
+	SetTransformRules(TransformRules{})
+	SetLocalOnlyMethods(nil)
+	SetToolExtraction(nil)
 	wg.Add(1) 
 	go logSender()
 	// Don't initialize local DB here; do it on first actual need if not authenticated.
 	log.Println("Observability worker started.")
 }
 
+// ShutdownObservability closes the log channel, waits for the worker to flush what it holds
+// and for batches in flight to be sent or stored. Calling it again does nothing.
 func ShutdownObservability() {
+	if shutdownStarted.CompareAndSwap(false, true) {
+		close(shuttingDown)
+	}
+	sendMu.Lock()
+	if logChanClosed.Load() {
+		sendMu.Unlock()
+		return
+	}
 	log.Println("Observability: Shutting down...")
+	logChanClosed.Store(true)
 	close(logChan) 
+	sendMu.Unlock()
 	wg.Wait()      
+	closeSyslog()
 	log.Println("Observability worker stopped gracefully.")
+	reportDroppedRecords()
 }
 
 func logSender() {
 	defer wg.Done()
-	ticker := time.NewTicker(batchInterval / 2) 
+	backoff := newTickerBackoff(batchInterval/2, time.Now())
+	ticker := time.NewTicker(backoff.current) 
 	defer ticker.Stop()
 
 	for {
@@ -116,6 +162,11 @@ func logSender() {
 			bufferSize := len(logBuffer)
 			bufferMutex.Unlock()
 
+			if backoff.activity(time.Now()) {
+				if verbose { log.Printf("Observability: Traffic resumed, restoring flush ticker to %s", backoff.current) }
+				ticker.Reset(backoff.current)
+			}
+
 			if bufferSize >= batchSize {
 				if verbose { log.Printf("Observability: Buffer full (Size: %d >= %d), flushing...", bufferSize, batchSize) }
 				flushBuffer() // Will handle local save or remote send based on auth status
@@ -127,7 +178,13 @@ func logSender() {
 				if verbose { log.Printf("Observability: Batch interval reached (%s), flushing buffer (Size: %d)...", batchInterval, len(logBuffer)) }
 				flushBufferLocked() // Will handle local save or remote send based on auth status
 			}
+			bufferEmpty := len(logBuffer) == 0
 			bufferMutex.Unlock()
+
+			if backoff.tick(time.Now(), bufferEmpty) {
+				if verbose { log.Printf("Observability: Idle, backing off flush ticker to %s", backoff.current) }
+				ticker.Reset(backoff.current)
+			}
 		}
 	}
 }
@@ -160,48 +217,112 @@ func flushBufferLocked() {
 	}(sendingBuffer, sendUrl)
 }
 
-// sendOrStoreBatch decides whether to send the batch to the remote server or store it locally.
+// sendOrStoreBatch routes each record of a batch to the local store or the platform and
+// handles both parts. Without special routing rules the whole batch goes one way, as before.
 func sendOrStoreBatch(batch []types.AuditRecord, observeUrl string) {
 	if len(batch) == 0 {
 		return
 	}
 
-	authToken, authErr := auth.GetToken()
+	var authToken string
+	authenticated := false
+	if !offline {
+		var authErr error
+		authToken, authErr = auth.GetToken()
+		authenticated = authErr == nil && authToken != ""
+	}
 
-	if authErr != nil || authToken == "" { // Not authenticated or error fetching token
-		// Ensure local DB is initialized (only once)
-		localDBInitOnce.Do(func() {
-			if verbose { log.Println("Observability: First-time local save attempt, initializing local DB...") }
-			if err := localstore.InitDB(""); err != nil {
-				log.Printf("Observability CRITICAL: Failed to initialize local database: %v. Local logs will be lost.", err)
-				// If DB init fails, subsequent saves in this execution will also fail the DB check in localstore.SaveBatch
-			}
-		})
-
-		// Show local logging info message (only once)
-		localLogInfoOnce.Do(func() {
-			fmt.Fprintln(os.Stderr, color.YellowString("---------------------------------------------------------------------"))
-			fmt.Fprintln(os.Stderr, color.CyanString("INFO: Not authenticated. Storing logs locally."))
-			fmt.Fprintln(os.Stderr, color.CyanString("      Use 'ithena-cli logs show' to view them."))
-			fmt.Fprintln(os.Stderr, color.YellowString("---------------------------------------------------------------------"))
-		})
-
-		if verbose { log.Printf("Observability: Not authenticated. Saving batch of %d logs locally.", len(batch)) }
-		err := localstore.SaveBatch(batch)
-		if err != nil {
-			log.Printf("Observability Error: Failed to save batch locally (Size: %d): %v", len(batch), err)
+	if syslogSink != nil {
+		if records := exportable(batch); len(records) > 0 {
+			syslogSink.export(records)
 		}
-		return // Do not proceed to send to platform
 	}
 
-	// Authenticated: Proceed to send to the platform
-	if verbose { log.Printf("Observability: Authenticated. Sending batch (Size: %d) to %s", len(batch), observeUrl) }
+	local, remote := partitionBatch(batch, observeUrl, authenticated)
+	if len(local) > 0 {
+		if verbose { log.Printf("Observability: Saving %d of %d log(s) locally.", len(local), len(batch)) }
+		storeBatchLocally(local)
+		settleDurable(local)
+	}
+	if len(remote) > 0 {
+		uploadBatch(remote, observeUrl, authToken)
+	}
+}
+
+// StatusError reports a platform response outside the 2xx range.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("batch send failed with status %s", e.Status)
+}
+
+// encodeBatch marshals a batch in the configured wire format and compresses it when worthwhile,
+// returning the payload, its Content-Type and its Content-Encoding ("" when uncompressed).
+func encodeBatch(batch []types.AuditRecord) ([]byte, string, string, error) {
+	payloadBytes, contentType, err := marshalBatch(batch)
+	if err != nil {
+		return nil, "", "", err
+	}
+	payloadBytes, contentEncoding := compressPayload(payloadBytes)
+	return payloadBytes, contentType, contentEncoding, nil
+}
+
+// sendPayload makes a single upload attempt. A non-2xx response is returned as *StatusError.
+func sendPayload(client *http.Client, payloadBytes []byte, contentType string, contentEncoding string, observeUrl string, authToken string) error {
+	req, err := http.NewRequest("POST", observeUrl, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
+	}
+
+	for name, values := range observeHeaders {
+		req.Header[name] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.Header.Set("Content-Type", contentType)
+	if orgID != "" {
+		req.Header.Set(OrgIDHeader, orgID)
+	}
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	respBodyBytes, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(respBodyBytes)}
+	if readErr != nil {
+		statusErr.Body = fmt.Sprintf("(failed to read response body: %v)", readErr)
+	}
+	return statusErr
+}
 
-	client := &http.Client{Timeout: 30 * time.Second} 
+// postBatch sends a batch to the platform, retrying transient failures with exponential backoff.
+// It returns nil once the platform has accepted the batch.
+func postBatch(batch []types.AuditRecord, observeUrl string, authToken string) error {
+	client := httpclient.New()
 	maxRetries := 3
 	baseDelay := 1 * time.Second
 	var lastHttpErr error
 
+	// Marshal and compress once; every attempt sends a fresh reader over the same bytes.
+	payloadBytes, contentType, contentEncoding, err := encodeBatch(batch)
+	if err != nil {
+		log.Printf("Observability Error: Failed to marshal batch (Size: %d): %v. Batch not sent.", len(batch), err)
+		if len(batch) > 0 { log.Printf("  (First Record ID: %s)", batch[0].ID) }
+		return err
+	}
+
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
 			delay := baseDelay * time.Duration(1<<(attempt-1)) 
@@ -211,51 +332,31 @@ func sendOrStoreBatch(batch []types.AuditRecord, observeUrl string) {
 			// However, for CLI, token is usually long-lived or auth is re-triggered. For simplicity, using initially fetched token.
 		}
 
-		payloadBytes, err := json.Marshal(batch)
-		if err != nil {
-			log.Printf("Observability Error: Failed to marshal batch (Size: %d): %v. Batch not sent.", len(batch), err)
-			if len(batch) > 0 { log.Printf("  (First Record ID: %s)", batch[0].ID) }
-			return 
-		}
-
-		req, err := http.NewRequest("POST", observeUrl, bytes.NewBuffer(payloadBytes))
-		if err != nil {
-			log.Printf("Observability Error: Failed to create HTTP request for batch (Size: %d): %v. Batch not sent.", len(batch), err)
-			return 
-		}
-
-		req.Header.Set("Authorization", "Bearer "+authToken)
-		req.Header.Set("Content-Type", "application/json")
-
 		if verbose {
 			log.Printf("Observability: Sending batch HTTP request (Attempt %d, Size: %d)...", attempt, len(batch))
 		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Observability Error (Attempt %d): HTTP request failed for batch (Size: %d): %v", attempt, len(batch), err)
-			lastHttpErr = err
-			if attempt == maxRetries {
-				log.Printf("Observability Error: Max retries reached for batch send (Size: %d). Last error: %v. Batch not sent.", len(batch), lastHttpErr)
-			}
-			continue 
-		}
-
-		respBodyBytes, readErr := io.ReadAll(resp.Body)
-		resp.Body.Close() 
-
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			if verbose { log.Printf("Observability: Batch (Size: %d) sent successfully (Status: %s)", len(batch), resp.Status) }
-			return 
+		err := sendPayload(client, payloadBytes, contentType, contentEncoding, observeUrl, authToken)
+		if err == nil {
+			if verbose { log.Printf("Observability: Batch (Size: %d) sent successfully", len(batch)) }
+			return nil
 		}
 
-		log.Printf("Observability Error (Attempt %d): Batch send failed (Size: %d) with status %s.", attempt, len(batch), resp.Status)
-		if readErr != nil {
-			log.Printf("  Additionally, failed to read response body: %v", readErr)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			log.Printf("Observability Error (Attempt %d): Batch send failed (Size: %d) with status %s.", attempt, len(batch), statusErr.Status)
+			log.Printf("  Response Body: %s", statusErr.Body)
+			if statusErr.StatusCode == http.StatusUnsupportedMediaType && contentType != contentTypeJSON {
+				// The platform does not understand the binary format; re-encode as JSON for the next attempt.
+				fallBackToJSON()
+				if payloadBytes, contentType, contentEncoding, err = encodeBatch(batch); err != nil {
+					log.Printf("Observability Error: Failed to marshal batch (Size: %d): %v. Batch not sent.", len(batch), err)
+					return err
+				}
+			}
 		} else {
-			log.Printf("  Response Body: %s", string(respBodyBytes)) 
+			log.Printf("Observability Error (Attempt %d): HTTP request failed for batch (Size: %d): %v", attempt, len(batch), err)
 		}
-		lastHttpErr = fmt.Errorf("batch send failed with status %s", resp.Status)
+		lastHttpErr = err
 
 		if attempt == maxRetries {
 			log.Printf("Observability Error: Max retries reached for batch send (Size: %d). Last error: %v. Batch not sent.", len(batch), lastHttpErr)
@@ -264,11 +365,132 @@ func sendOrStoreBatch(batch []types.AuditRecord, observeUrl string) {
 	if verbose && lastHttpErr != nil { 
 		log.Printf("Observability: Failed to send batch (Size: %d) after %d retries to %s.", len(batch), maxRetries+1, observeUrl)
 	}
+	return lastHttpErr
+}
+
+// ensureLocalDB initializes the local SQLite store on first use and reports whether it is usable.
+func ensureLocalDB() bool {
+	localDBInitOnce.Do(func() {
+		if verbose { log.Println("Observability: First-time local DB use, initializing local DB...") }
+		if localDBInitErr = localstore.InitDB(""); localDBInitErr != nil {
+			log.Printf("Observability CRITICAL: Failed to initialize local database: %v. Local logs will be lost.", localDBInitErr)
+		}
+	})
+	return localDBInitErr == nil
 }
 
+// storeBatchLocally saves a batch to the local SQLite store, initializing the DB on first use.
+func storeBatchLocally(batch []types.AuditRecord) {
+	// If DB init fails, saves in this execution will also fail the DB check in localstore.SaveBatch
+	ensureLocalDB()
+
+	err := localstore.SaveBatch(batch)
+	if err != nil {
+		log.Printf("Observability Error: Failed to save batch locally (Size: %d): %v", len(batch), err)
+		unstoredRecords.Add(int64(len(batch)))
+		traceBatch(batch, "local", reasonLocalStoreFailed, "lost")
+		return
+	}
+	storedRecords.Add(int64(len(batch)))
+	traceBatch(batch, "local", reasonLocalStoreOK, "stored")
+}
 
-// SendLog queues an audit record to be processed by the observability worker.
+// SendLog queues an audit record to be processed by the observability worker. With a sample
+// rate below 1, some successful records are dropped instead (see SetSampleRate).
 func SendLog(record types.AuditRecord, observeUrl string) {
+	// Checked first, so paused records don't reach the record listener (the session report) either.
+	if paused() {
+		pausedRecords.Add(1)
+		traceRecord(record, "dropped", reasonPaused, "discard")
+		return
+	}
+	prepareRecord(&record)
+	if sampledOut(record) {
+		traceRecord(record, "dropped", reasonSampled, "discard")
+		return
+	}
+	saved := durable && saveDurably(record, observeUrl)
+
+	job := logJob{
+		record:     record,
+		observeUrl: observeUrl,
+	}
+
+	sendMu.RLock()
+	defer sendMu.RUnlock()
+	if logChanClosed.Load() {
+		if saved {
+			traceRecord(record, "local", reasonShutDown, "kept_durable_copy")
+			return
+		}
+		dropLateRecord(record)
+		return
+	}
+
+	// Try to send without blocking; a full channel is handled by the configured policy
+	select {
+	case logChan <- job:
+		if verbose { log.Printf("Observability: Queued log Record ID: %s", record.ID) }
+	default:
+		if enqueueWhenFull(job) {
+			if verbose { log.Printf("Observability: Queued log Record ID: %s after waiting for room", record.ID) }
+			return
+		}
+		if saved {
+			log.Printf("Observability Warning: Log channel full. Record ID %s was already saved locally and will be uploaded by a later run.", record.ID)
+			traceRecord(record, "local", reasonQueueFull, "kept_durable_copy")
+			return
+		}
+		// This case should ideally not be hit often if buffer size is adequate and worker is responsive.
+		droppedRecords.Add(1)
+		log.Printf("Observability Warning: Log channel full. Dropping log Record ID: %s. Consider raising %s, checking worker performance or setting %s=block.", record.ID, logBufferSizeEnv, queueFullPolicyEnv)
+		traceRecord(record, "dropped", reasonQueueFull, "discard")
+	}
+}
+
+// finalLogQueueTimeout bounds how long SendFinalLog waits for room in the log channel
+// before storing the record itself.
+const finalLogQueueTimeout = 5 * time.Second
+
+// SendFinalLog records the last audit record of a process that is about to exit and shuts
+// the observability worker down, so the record has been uploaded or stored locally by the
+// time it returns. Unlike SendLog it never drops the record: it waits for room in the
+// channel and, if the worker doesn't take it in time, writes it to the local store directly.
+// Use it instead of SendLog followed by ShutdownObservability. Once the worker has shut down,
+// the record is stored locally right away.
+func SendFinalLog(record types.AuditRecord, observeUrl string) {
+	if paused() {
+		traceRecord(record, "dropped", reasonPaused, "discard")
+		ShutdownObservability()
+		return
+	}
+	prepareRecord(&record)
+	if durable {
+		saveDurably(record, observeUrl)
+	}
+
+	sendMu.RLock()
+	if logChanClosed.Load() {
+		sendMu.RUnlock()
+		log.Printf("Observability Warning: The log worker has already shut down. Storing final log Record ID %s locally.", record.ID)
+		storeBatchLocally([]types.AuditRecord{record})
+		return
+	}
+	select {
+	case logChan <- logJob{record: record, observeUrl: observeUrl}:
+		sendMu.RUnlock()
+		if verbose { log.Printf("Observability: Queued final log Record ID: %s", record.ID) }
+		ShutdownObservability()
+	case <-time.After(finalLogQueueTimeout):
+		sendMu.RUnlock()
+		log.Printf("Observability Warning: Log channel still full after %s. Storing final log Record ID %s locally.", finalLogQueueTimeout, record.ID)
+		ShutdownObservability()
+		storeBatchLocally([]types.AuditRecord{record})
+	}
+}
+
+// prepareRecord fills in the fields every record carries but callers usually leave empty.
+func prepareRecord(record *types.AuditRecord) {
 	// Add proxy version to the record before sending
 	// This ensures it's set if the global var was updated after init
 	// However, AuditRecord.ProxyVersion is a pointer, so direct assignment works if it's set once globally.
@@ -280,6 +502,10 @@ func SendLog(record types.AuditRecord, observeUrl string) {
 		record.ProxyVersion = &versionStr
 	}
 
+	if record.Session == nil {
+		record.Session = sessionMetadata
+	}
+
 	// Generate UUID for the log entry if it's not already set
 	if record.ID == "" {
 		record.ID = uuid.New().String()
@@ -290,18 +516,10 @@ func SendLog(record types.AuditRecord, observeUrl string) {
 		record.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
 	}
 
-	job := logJob{
-		record:     record,
-		observeUrl: observeUrl,
-	}
+	truncatePreviews(record)
 
-	// Try to send, but don't block if the channel is full
-	select {
-	case logChan <- job:
-		if verbose { log.Printf("Observability: Queued log Record ID: %s", record.ID) }
-	default:
-		// This case should ideally not be hit often if buffer size is adequate and worker is responsive.
-		log.Printf("Observability Warning: Log channel full. Dropping log Record ID: %s. Consider increasing buffer or checking worker performance.", record.ID)
+	if recordListener != nil {
+		recordListener(*record)
 	}
 }
 
@@ -310,52 +528,98 @@ func RecordRpcCompletion(
 	resp jsonrpc.Response, // The JSON-RPC response object
 	duration time.Duration, // Total duration of the call
 	alias *string, // Alias for the target server from config
-	method *string, // The MCP method called (e.g., "tool/call")
+	method *string, // The MCP method called (e.g., "tools/call")
 	requestParams interface{}, // The parameters sent in the request
 	requestStartTime time.Time, // When the request was initiated
 	observeUrl string, // The URL for the observability API endpoint
+	requestBytes int64, // Raw size of the request message as read by the wrapper
+	responseBytes int64, // Raw size of the response message as read by the wrapper
+	firstByte time.Duration, // Time until the first progress notification or response byte arrived
 ) {
-	status := "success"
+	status := types.StatusSuccess
 	var responsePreview interface{}
 	var errorDetails interface{}
 
 	if resp.Error != nil {
-		status = "failure"
+		status = types.StatusFailure
 		errorDetails = resp.Error // Capture the full error object
 	} else {
 		responsePreview = resp.Result // Capture the result on success
 	}
 
-	// Ensure McpMethod and ToolName are correctly extracted or set based on context
-	// For generic RPC, method is primary. If it's a tool_call, details might be inside requestParams.
-	var toolNameExtract *string
-	if method != nil && *method == "tool/call" { // Example condition
-		// Attempt to extract tool_name from requestParams if it's a map
-		if paramsMap, ok := requestParams.(map[string]interface{}); ok {
-			if tn, ok := paramsMap["tool_name"].(string); ok {
-				toolNameExtract = &tn
-			}
-		}
-	}
-	if method == nil && toolNameExtract != nil {
-		// If only tool_name is found (e.g. it's a pure tool call not using mcp_method style)
-		// This logic might need refinement based on how you differentiate.
-	}
+	// For tool calls the tool name and arguments live in the params; where depends on the
+	// method (e.g. "/name" and "/arguments" for MCP's tools/call), see SetToolExtraction.
+	toolNameExtract, toolArgs := extractTool(method, requestParams)
 
 	durationMs := duration.Milliseconds()
+	firstByteMs := firstByte.Milliseconds()
 
 	record := types.AuditRecord{
 		// ID will be generated by SendLog
 		Timestamp:         requestStartTime.UTC().Format(time.RFC3339Nano),
 		McpMethod:         method,
 		ToolName:          toolNameExtract, // Use extracted if available
+		ToolArgs:          transformPreview(toolArgs),
 		DurationMs:        &durationMs,
+		FirstByteMs:       &firstByteMs,
 		Status:            status,
 		// ProxyVersion will be set by SendLog
 		TargetServerAlias: alias,
-		RequestPreview:    requestParams,
-		ResponsePreview:   responsePreview,
-		ErrorDetails:      errorDetails,
+		RequestPreview:    transformPreview(requestParams),
+		ResponsePreview:   transformPreview(responsePreview),
+		ErrorDetails:      transformPreview(errorDetails),
+		RequestBytes:      &requestBytes,
+		ResponseBytes:     &responseBytes,
+	}
+
+	SendLog(record, observeUrl)
+}
+
+// RecordRpcInterrupted records a request that will never get a response, e.g. because the
+// backend restarted while it was in flight. status is one of the types.Status* values and
+// reason is stored as the error message.
+func RecordRpcInterrupted(status string, reason string, alias *string, method *string, requestParams interface{}, requestStartTime time.Time, observeUrl string, requestBytes int64) {
+	toolNameExtract, toolArgs := extractTool(method, requestParams)
+	durationMs := time.Since(requestStartTime).Milliseconds()
+
+	record := types.AuditRecord{
+		Timestamp:         requestStartTime.UTC().Format(time.RFC3339Nano),
+		McpMethod:         method,
+		ToolName:          toolNameExtract,
+		ToolArgs:          transformPreview(toolArgs),
+		DurationMs:        &durationMs,
+		Status:            status,
+		TargetServerAlias: alias,
+		RequestPreview:    transformPreview(requestParams),
+		ErrorDetails:      map[string]string{"error": reason},
+		RequestBytes:      &requestBytes,
+	}
+
+	SendLog(record, observeUrl)
+}
+
+// RecordNotification records a JSON-RPC notification. fromClient tells its direction: params
+// are stored as the request preview of a client notification and as the response preview of
+// a server one, with the message size in the matching bytes field. Client notifications that
+// arrive once shutdown has started (e.g. notifications/cancelled after the backend exited) are
+// not recorded.
+func RecordNotification(fromClient bool, alias *string, method string, params interface{}, receivedAt time.Time, observeUrl string, messageBytes int64) {
+	if fromClient && shutdownStarted.Load() {
+		if verbose { log.Printf("Observability: Not recording client notification %s received during shutdown.", method) }
+		return
+	}
+	record := types.AuditRecord{
+		Timestamp:         receivedAt.UTC().Format(time.RFC3339Nano),
+		McpMethod:         &method,
+		Status:            types.StatusNotification,
+		TargetServerAlias: alias,
+	}
+	if fromClient {
+		record.RequestPreview = transformPreview(params)
+		record.RequestBytes = &messageBytes
+	} else {
+		record.ResponsePreview = transformPreview(params)
+		record.ResponseBytes = &messageBytes
 	}
 
 	SendLog(record, observeUrl)
@@ -365,7 +629,7 @@ func RecordRpcCompletion(
 // even before a full MCP interaction might have completed (e.g., connection error).
 func CreateAuditRecordForError(errMsg string, alias *string, method *string, correlationID *string) types.AuditRecord {
 	now := time.Now().UTC()
-	status := "failure"
+	status := types.StatusFailure
 	
 	// If a correlationID is provided (e.g., from an incoming request that failed early),
 	// use it. Otherwise, generate a new UUID.
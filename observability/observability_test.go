@@ -0,0 +1,33 @@
+package observability
+
+import (
+	"testing"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// TestSendFinalLogStoresFailure sends the record of a backend that exited non-zero, as the
+// wrapper does before exiting, and checks it is in the local store once SendFinalLog returns.
+func TestSendFinalLogStoresFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("ITHENA_LOG_DB", dir+"/logs.db")
+
+	InitObservability()
+	alias := "failing"
+	record := CreateAuditRecordForError("Backend command 'server' exited with non-zero status 3", &alias, nil, nil)
+	SendFinalLog(record, "http://localhost")
+
+	stored, err := localstore.GetLogByID(record.ID)
+	if err != nil {
+		t.Fatalf("GetLogByID(%s): %v", record.ID, err)
+	}
+	if stored.Status != types.StatusFailure {
+		t.Errorf("stored status = %q, want %q", stored.Status, types.StatusFailure)
+	}
+	if stored.TargetServerAlias == nil || *stored.TargetServerAlias != alias {
+		t.Errorf("stored alias = %v, want %s", stored.TargetServerAlias, alias)
+	}
+}
@@ -0,0 +1,37 @@
+package observability
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const orgIDEnv = "ITHENA_ORG_ID"
+
+// OrgIDHeader carries the configured organization ID on every upload. It only tells a
+// multi-tenant backend where the records belong; the bearer token still authenticates them.
+const OrgIDHeader = "X-Ithena-Org-Id"
+
+// orgID is sent as OrgIDHeader when non-empty.
+var orgID string
+
+// SetOrgID sets the organization ID sent with uploads. An empty value uses ITHENA_ORG_ID;
+// if that is empty too, no header is sent.
+func SetOrgID(id string) error {
+	if id == "" {
+		id = os.Getenv(orgIDEnv)
+	}
+	id = strings.TrimSpace(id)
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return fmt.Errorf("invalid organization ID '%s': only printable ASCII characters without spaces are allowed", id)
+		}
+	}
+	orgID = id
+	return nil
+}
+
+// OrgID returns the organization ID sent with uploads, or "" if none is configured.
+func OrgID() string {
+	return orgID
+}
@@ -0,0 +1,89 @@
+package observability
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pauseFileEnv overrides the control file that pauses capture while it exists.
+const pauseFileEnv = "ITHENA_PAUSE_FILE"
+
+// pauseFileName is the control file in the ithena-cli config directory.
+const pauseFileName = "capture.paused"
+
+// pausePollInterval is how often the control file is checked.
+const pausePollInterval = time.Second
+
+var (
+	capturePaused  atomic.Bool
+	pausedRecords  atomic.Int64 // Records skipped since capture was last paused
+	pauseWatchOnce sync.Once
+)
+
+// PauseFilePath returns the control file that pauses capture in every running wrapper while it
+// exists: ITHENA_PAUSE_FILE, or capture.paused in the ithena-cli config directory. It has no
+// fallback under the shared temporary directory, where another user could pause capture.
+func PauseFilePath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv(pauseFileEnv)); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w (set %s instead)", err, pauseFileEnv)
+	}
+	return filepath.Join(configDir, "ithena-cli", pauseFileName), nil
+}
+
+// paused reports whether capture is paused. The first call checks the control file and starts
+// polling it, printing each change of state.
+func paused() bool {
+	pauseWatchOnce.Do(func() {
+		path, err := PauseFilePath()
+		if err != nil {
+			if verbose { log.Printf("Observability: Not watching for a pause file: %v", err) }
+			return
+		}
+		updatePaused(path)
+		go func() {
+			ticker := time.NewTicker(pausePollInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				updatePaused(path)
+			}
+		}()
+	})
+	return capturePaused.Load()
+}
+
+// CapturePaused reports whether capture is paused. The wrapper notes it when a request
+// arrives, so a call that started while paused is skipped even if capture resumed before its
+// response.
+func CapturePaused() bool {
+	return paused()
+}
+
+// SkipPausedCall counts a call that is not recorded because capture was paused when its
+// request arrived.
+func SkipPausedCall() {
+	pausedRecords.Add(1)
+}
+
+// updatePaused pauses capture if the control file at path exists and resumes it otherwise.
+func updatePaused(path string) {
+	_, err := os.Stat(path)
+	now := err == nil
+	if capturePaused.Swap(now) == now {
+		return
+	}
+	if now {
+		log.Printf("Observability: Capture paused (%s exists). Records are not stored, uploaded or exported until it is removed.", path)
+	} else {
+		log.Printf("Observability: Capture resumed; %d record(s) skipped while paused.", pausedRecords.Swap(0))
+	}
+}
@@ -0,0 +1,162 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ithena-one/Ithena/packages/cli/auth"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// pendingClaimStaleAfter is how long another process's claim on pending records is honoured
+// before this process assumes it exited mid-send and takes the records over.
+const pendingClaimStaleAfter = 10 * time.Minute
+
+// pendingClaimID identifies this process's claims in the pending upload journal.
+var pendingClaimID = uuid.New().String()
+
+// journalPending records a batch in the pending upload journal before it is sent.
+// It reports whether the batch was journaled; failures only cost crash safety, not delivery.
+// Records are journaled when their batch is flushed, not when they enter logBuffer, so the
+// ones still buffered are lost if the process is killed; durable mode (see SetDurable)
+// journals each record before it is even queued.
+func journalPending(batch []types.AuditRecord, observeUrl string) bool {
+	if !ensureLocalDB() {
+		return false
+	}
+	if err := localstore.SavePending(batch, observeUrl, pendingClaimID); err != nil {
+		log.Printf("Observability Warning: Failed to journal batch before sending: %v", err)
+		return false
+	}
+	return true
+}
+
+// ResendPending starts re-sending, in the background, records that earlier runs journaled
+// but never confirmed as delivered. It does nothing when not authenticated. Call it once,
+// after InitObservability, in modes that upload logs; ShutdownObservability waits for it.
+func ResendPending() {
+	wg.Add(1)
+	go resendPending()
+}
+
+func resendPending() {
+	defer wg.Done()
+
+	if offline {
+		return
+	}
+
+	authToken, err := auth.GetToken()
+	if err != nil || authToken == "" {
+		return
+	}
+	if !ensureLocalDB() {
+		return
+	}
+	pending, err := localstore.ClaimPending(pendingClaimID, pendingClaimStaleAfter)
+	if err != nil {
+		log.Printf("Observability Warning: Failed to load pending records: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if verbose { log.Printf("Observability: Recovered %d pending record(s) from a previous run, re-sending...", len(pending)) }
+	for _, p := range pending {
+		traceRecord(p.Record, "recovered", reasonRecoveredFromQueue, "reroute")
+	}
+
+	// Group by destination, keeping the journal's order.
+	var urls []string
+	batches := make(map[string][]types.AuditRecord)
+	for _, p := range pending {
+		if _, ok := batches[p.ObserveUrl]; !ok {
+			urls = append(urls, p.ObserveUrl)
+		}
+		batches[p.ObserveUrl] = append(batches[p.ObserveUrl], p.Record)
+	}
+
+	for _, url := range urls {
+		records := batches[url]
+		for start := 0; start < len(records); start += batchSize {
+			end := start + batchSize
+			if end > len(records) {
+				end = len(records)
+			}
+			local, chunk := partitionBatch(records[start:end], url, true)
+			if len(local) > 0 {
+				// Routing rules changed since they were journaled; honour them now.
+				storeBatchLocally(local)
+				localstore.DeletePending(recordIDs(local))
+			}
+			if len(chunk) == 0 {
+				continue
+			}
+			ids := recordIDs(chunk)
+
+			if err := postBatch(chunk, url, authToken); err != nil {
+				localstore.ReleasePending(ids)
+				deadLetter(chunk)
+				continue
+			}
+			if err := localstore.DeletePending(ids); err != nil {
+				log.Printf("Observability Warning: Failed to remove re-sent records from the pending queue: %v", err)
+			}
+			if err := localstore.MarkUploaded(ids); err != nil {
+				log.Printf("Observability Warning: Failed to clear pending_upload on re-sent records: %v", err)
+			}
+			traceBatch(chunk, "remote", reasonUploaded, "delivered")
+			if verbose { log.Printf("Observability: Re-sent %d pending record(s) to %s", len(chunk), url) }
+		}
+	}
+}
+
+// recordIDs returns the IDs of a batch of records.
+func recordIDs(batch []types.AuditRecord) []string {
+	ids := make([]string, len(batch))
+	for i, record := range batch {
+		ids[i] = record.ID
+	}
+	return ids
+}
+
+// deadLetter keeps a local copy of records whose upload failed, flagged pending_upload so they
+// stay visible in 'logs show' until a later run delivers them. If the local store is unusable
+// too, the records are written to a JSON file in the temp directory rather than dropped.
+func deadLetter(batch []types.AuditRecord) {
+	local := make([]types.AuditRecord, len(batch))
+	for i, record := range batch {
+		record.PendingUpload = true
+		local[i] = record
+	}
+
+	if ensureLocalDB() {
+		err := localstore.SaveBatch(local)
+		if err == nil {
+			storedRecords.Add(int64(len(local)))
+			if verbose { log.Printf("Observability: Saved %d undelivered record(s) locally, flagged for upload.", len(local)) }
+			traceBatch(local, "dead_lettered", reasonUploadFailed, "stored_local_pending_upload")
+			return
+		}
+		log.Printf("Observability Error: Failed to save undelivered records locally: %v", err)
+	}
+
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("ithena-cli-undelivered-%d-%d.json", time.Now().Unix(), os.Getpid()))
+	data, err := json.MarshalIndent(local, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		log.Printf("Observability CRITICAL: Could not store %d undelivered record(s) anywhere; they are lost: %v", len(local), err)
+		traceBatch(local, "dead_lettered", reasonLocalStoreFailed, "lost")
+		return
+	}
+	log.Printf("Observability Warning: Local store unavailable; wrote %d undelivered record(s) to %s", len(local), path)
+	traceBatch(local, "dead_lettered", reasonLocalStoreFailed, "wrote_file:"+path)
+}
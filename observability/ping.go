@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ithena-one/Ithena/packages/cli/httpclient"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// pingMethod marks the synthetic record sent by Ping so the platform can tell it apart.
+const pingMethod = "ithena/ping"
+
+// Ping uploads a single synthetic audit record to observeUrl using the same encoding and
+// request code as regular batches, without retries (except once as JSON if the platform
+// rejects MessagePack), and returns the round-trip latency of the last attempt.
+// A non-2xx response is returned as *StatusError.
+func Ping(observeUrl string, authToken string) (time.Duration, error) {
+	if !isObserveUrlAllowed(observeUrl) {
+		return 0, fmt.Errorf("observe URL %s is not in the configured allowlist", observeUrl)
+	}
+
+	method := pingMethod
+	alias := "ithena-cli ping"
+	versionStr := ProxyVersion
+	record := types.AuditRecord{
+		ID:                uuid.NewString(),
+		McpMethod:         &method,
+		Status:            types.StatusSuccess,
+		ProxyVersion:      &versionStr,
+		TargetServerAlias: &alias,
+		Timestamp:         time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	payloadBytes, contentType, contentEncoding, err := encodeBatch([]types.AuditRecord{record})
+	if err != nil {
+		return 0, err
+	}
+	client := httpclient.New()
+	start := time.Now()
+	err = sendPayload(client, payloadBytes, contentType, contentEncoding, observeUrl, authToken)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnsupportedMediaType && contentType != contentTypeJSON {
+		// As for regular batches, retry once as JSON when the binary format is rejected.
+		fallBackToJSON()
+		if payloadBytes, contentType, contentEncoding, err = encodeBatch([]types.AuditRecord{record}); err != nil {
+			return 0, err
+		}
+		start = time.Now()
+		err = sendPayload(client, payloadBytes, contentType, contentEncoding, observeUrl, authToken)
+	}
+	return time.Since(start), err
+}
@@ -0,0 +1,124 @@
+package observability
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// logBufferSizeEnv overrides how many records the log channel holds before the queue-full
+// policy applies (default logChannelBufferSize).
+const logBufferSizeEnv = "ITHENA_LOG_BUFFER_SIZE"
+
+// droppedRecords counts the records SendLog discarded because the log channel was full, or
+// because the worker had already shut down.
+var droppedRecords atomic.Int64
+
+// DroppedRecords returns how many records were dropped because the log channel was full
+// since InitObservability.
+func DroppedRecords() int64 {
+	return droppedRecords.Load()
+}
+
+// logBufferSizeFromEnv returns the log channel capacity from ITHENA_LOG_BUFFER_SIZE.
+func logBufferSizeFromEnv() int {
+	value := strings.TrimSpace(os.Getenv(logBufferSizeEnv))
+	if value == "" {
+		return logChannelBufferSize
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		log.Printf("Observability Warning: Ignoring invalid %s value '%s', using %d", logBufferSizeEnv, value, logChannelBufferSize)
+		return logChannelBufferSize
+	}
+	return size
+}
+
+// reportDroppedRecords prints how many records were dropped during the session, if any.
+func reportDroppedRecords() {
+	if dropped := droppedRecords.Load(); dropped > 0 {
+		log.Printf("Observability Warning: %d log(s) dropped due to a full log channel (capacity %d). Raise %s or set %s=%s to keep them.",
+			dropped, cap(logChan), logBufferSizeEnv, queueFullPolicyEnv, queueFullBlock)
+	}
+}
+
+// queueFullPolicyEnv selects what SendLog does when the log channel is full:
+// "drop" (default) discards the record so the proxied stream never waits, "block" waits
+// until the worker makes room, and "block-with-timeout" waits up to queueFullTimeoutEnv
+// before dropping it.
+const queueFullPolicyEnv = "ITHENA_QUEUE_FULL_POLICY"
+
+// queueFullTimeoutEnv bounds the wait of the "block-with-timeout" policy (a Go duration).
+const queueFullTimeoutEnv = "ITHENA_QUEUE_FULL_TIMEOUT"
+
+const (
+	queueFullDrop             = "drop"
+	queueFullBlock            = "block"
+	queueFullBlockWithTimeout = "block-with-timeout"
+
+	defaultQueueFullTimeout = 1 * time.Second
+)
+
+// queueFullPolicy is the configured behavior of SendLog when the log channel is full.
+type queueFullPolicy struct {
+	mode    string
+	timeout time.Duration // Only used by queueFullBlockWithTimeout
+}
+
+// fullQueuePolicy is read from the environment by InitObservability.
+var fullQueuePolicy = queueFullPolicy{mode: queueFullDrop}
+
+// queueFullPolicyFromEnv reads ITHENA_QUEUE_FULL_POLICY and ITHENA_QUEUE_FULL_TIMEOUT,
+// falling back to dropping records on invalid values.
+func queueFullPolicyFromEnv() queueFullPolicy {
+	policy := queueFullPolicy{mode: queueFullDrop, timeout: defaultQueueFullTimeout}
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv(queueFullPolicyEnv))); mode {
+	case "", queueFullDrop:
+	case queueFullBlock, queueFullBlockWithTimeout:
+		policy.mode = mode
+	default:
+		log.Printf("Observability Warning: Ignoring invalid %s value '%s', using '%s'", queueFullPolicyEnv, mode, queueFullDrop)
+	}
+	if value := strings.TrimSpace(os.Getenv(queueFullTimeoutEnv)); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			log.Printf("Observability Warning: Ignoring invalid %s value '%s', using %s", queueFullTimeoutEnv, value, defaultQueueFullTimeout)
+		} else {
+			policy.timeout = timeout
+		}
+	}
+	return policy
+}
+
+// enqueueWhenFull applies the queue-full policy to a job that didn't fit in the log channel
+// and reports whether it was eventually queued. Waiting ends when shutdown starts, and the
+// caller counts the job as dropped. It runs with sendMu held for reading.
+func enqueueWhenFull(job logJob) bool {
+	switch fullQueuePolicy.mode {
+	case queueFullBlock:
+		start := time.Now()
+		select {
+		case logChan <- job:
+		case <-shuttingDown:
+			return false
+		}
+		if verbose { log.Printf("Observability: Waited %s for room in the log channel for Record ID: %s", time.Since(start), job.record.ID) }
+		return true
+	case queueFullBlockWithTimeout:
+		timer := time.NewTimer(fullQueuePolicy.timeout)
+		defer timer.Stop()
+		select {
+		case logChan <- job:
+			return true
+		case <-timer.C:
+			return false
+		case <-shuttingDown:
+			return false
+		}
+	default:
+		return false
+	}
+}
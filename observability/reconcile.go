@@ -0,0 +1,141 @@
+package observability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/httpclient"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// knownIDsPath is appended to the observe URL to list the record IDs the platform has received.
+const knownIDsPath = "/ids"
+
+// ErrKnownIDsUnsupported is returned by KnownRecordIDs when the platform does not offer the
+// endpoint listing received record IDs.
+var ErrKnownIDsUnsupported = errors.New("the platform does not report received record IDs")
+
+// knownIDsPage is one page of the known IDs endpoint's response.
+type knownIDsPage struct {
+	IDs        []string `json:"ids"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// KnownIDsUrl returns the endpoint that lists the record IDs received through observeUrl.
+func KnownIDsUrl(observeUrl string) string {
+	return strings.TrimSuffix(observeUrl, "/") + knownIDsPath
+}
+
+// KnownRecordIDs asks the platform which records it has received with timestamps between start
+// and end, following next_cursor until the last page. A 404, 405 or 501 response yields
+// ErrKnownIDsUnsupported; any other non-2xx response is returned as *StatusError.
+func KnownRecordIDs(observeUrl string, authToken string, start, end time.Time) (map[string]bool, error) {
+	if !isObserveUrlAllowed(observeUrl) {
+		return nil, fmt.Errorf("observe URL %s is not in the configured allowlist", observeUrl)
+	}
+
+	client := httpclient.New()
+	known := make(map[string]bool)
+	cursor := ""
+	for {
+		query := url.Values{}
+		query.Set("start", start.UTC().Format(time.RFC3339Nano))
+		query.Set("end", end.UTC().Format(time.RFC3339Nano))
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		page, err := fetchKnownIDs(client, KnownIDsUrl(observeUrl)+"?"+query.Encode(), authToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range page.IDs {
+			known[id] = true
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return known, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// fetchKnownIDs requests one page of known record IDs.
+func fetchKnownIDs(client *http.Client, endpoint string, authToken string) (*knownIDsPage, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range observeHeaders {
+		req.Header[name] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.Header.Set("Accept", "application/json")
+	if orgID != "" {
+		req.Header.Set(OrgIDHeader, orgID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", endpoint, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusMethodNotAllowed, resp.StatusCode == http.StatusNotImplemented:
+		return nil, ErrKnownIDsUnsupported
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	var page knownIDsPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("unexpected response from %s: %w", endpoint, err)
+	}
+	return &page, nil
+}
+
+// Reupload sends locally stored records to observeUrl in batches, with the usual retries, and
+// clears their pending_upload flag and journal entries once delivered. Records the routing rules
+// keep local are skipped. It returns how many records were delivered and the first error.
+func Reupload(records []types.AuditRecord, observeUrl string, authToken string) (int, error) {
+	delivered := 0
+	var firstErr error
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		local, chunk := partitionBatch(records[start:end], observeUrl, true)
+		if len(local) > 0 {
+			if verbose { log.Printf("Observability: Not re-uploading %d record(s) that routing rules keep local.", len(local)) }
+		}
+		if len(chunk) == 0 {
+			continue
+		}
+		if err := postBatch(chunk, observeUrl, authToken); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		ids := recordIDs(chunk)
+		if err := localstore.DeletePending(ids); err != nil {
+			log.Printf("Observability Warning: Failed to remove re-uploaded records from the pending queue: %v", err)
+		}
+		if err := localstore.MarkUploaded(ids); err != nil {
+			log.Printf("Observability Warning: Failed to clear pending_upload on re-uploaded records: %v", err)
+		}
+		delivered += len(chunk)
+	}
+	return delivered, firstErr
+}
@@ -0,0 +1,128 @@
+package observability
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// quietLocalNoticeEnv suppresses the one-time "Storing logs locally" banner when set to "true".
+const quietLocalNoticeEnv = "ITHENA_QUIET_LOCAL_NOTICE"
+
+// quietLocalNotice is set from the --quiet-local-notice flag.
+var quietLocalNotice bool
+
+// SetQuietLocalNotice suppresses the local-logging banner shown to unauthenticated users.
+// Errors and warnings are unaffected.
+func SetQuietLocalNotice(quiet bool) {
+	quietLocalNotice = quiet
+}
+
+// showLocalNotice reports whether the local-logging banner should be printed.
+func showLocalNotice() bool {
+	return !quietLocalNotice && !strings.EqualFold(strings.TrimSpace(os.Getenv(quietLocalNoticeEnv)), "true")
+}
+
+// offline keeps every record local; see SetOffline.
+var offline bool
+
+// SetOffline makes the platform unreachable by design for this process: records are always
+// stored locally, and no token lookup, upload or pending-upload recovery happens, even when
+// authenticated. Used for profiles marked offline.
+func SetOffline(v bool) {
+	offline = v
+}
+
+// destination is where a flushed record ends up.
+type destination int
+
+const (
+	destinationLocal  destination = iota // The local SQLite store
+	destinationRemote                    // The platform's observe endpoint
+)
+
+// routeRecord decides where a single record goes and why. Records are routed individually so
+// that data-governance rules (such as local-only methods) can split a batch.
+func routeRecord(record types.AuditRecord, observeUrl string, authenticated bool) (destination, string) {
+	if offline {
+		return destinationLocal, reasonOffline
+	}
+	if !authenticated {
+		// Show local logging info message (only once)
+		localLogInfoOnce.Do(func() {
+			if !showLocalNotice() {
+				return
+			}
+			fmt.Fprintln(os.Stderr, color.YellowString("---------------------------------------------------------------------"))
+			fmt.Fprintln(os.Stderr, color.CyanString("INFO: Not authenticated. Storing logs locally."))
+			fmt.Fprintln(os.Stderr, color.CyanString("      Use 'ithena-cli logs show' to view them."))
+			fmt.Fprintln(os.Stderr, color.YellowString("---------------------------------------------------------------------"))
+		})
+		return destinationLocal, reasonNotAuthenticated
+	}
+	if isLocalOnly(record) {
+		return destinationLocal, reasonLocalOnlyMethod
+	}
+	if !isObserveUrlAllowed(observeUrl) {
+		allowlistWarnOnce.Do(func() {
+			log.Printf("Observability Warning: Observe URL '%s' is not in the allowed list. Refusing to upload; storing logs locally instead.", observeUrl)
+		})
+		return destinationLocal, reasonUrlNotAllowlisted
+	}
+	return destinationRemote, reasonAuthenticated
+}
+
+// KeptLocal reports whether the routing rules keep record in the local store even when
+// authenticated, e.g. because its method is local-only, so the platform is not expected to
+// have it.
+func KeptLocal(record types.AuditRecord, observeUrl string) bool {
+	dest, _ := routeRecord(record, observeUrl, true)
+	return dest == destinationLocal
+}
+
+// partitionBatch splits a batch by destination, preserving record order within each part.
+func partitionBatch(batch []types.AuditRecord, observeUrl string, authenticated bool) (local, remote []types.AuditRecord) {
+	for _, record := range batch {
+		dest, reason := routeRecord(record, observeUrl, authenticated)
+		if dest == destinationRemote {
+			traceRecord(record, "remote", reason, "upload")
+			remote = append(remote, record)
+		} else {
+			traceRecord(record, "local", reason, "store")
+			local = append(local, record)
+		}
+	}
+	return local, remote
+}
+
+// uploadBatch delivers records to the platform. The batch is journaled first so it survives
+// the process exiting before delivery is confirmed; if every retry fails, it is kept locally.
+func uploadBatch(batch []types.AuditRecord, observeUrl string, authToken string) {
+	if verbose { log.Printf("Observability: Authenticated. Sending batch (Size: %d) to %s", len(batch), observeUrl) }
+
+	journaled := journalPending(batch, observeUrl)
+	if err := postBatch(batch, observeUrl, authToken); err != nil {
+		if journaled {
+			if releaseErr := localstore.ReleasePending(recordIDs(batch)); releaseErr != nil {
+				log.Printf("Observability Warning: Failed to release pending records: %v", releaseErr)
+			}
+			log.Printf("Observability: Kept %d record(s) in the pending queue; they will be re-sent on a later run.", len(batch))
+		}
+		failedUploads.Add(int64(len(batch)))
+		deadLetter(batch)
+		return
+	}
+	uploadedRecords.Add(int64(len(batch)))
+	traceBatch(batch, "remote", reasonUploaded, "delivered")
+	if journaled {
+		if err := localstore.DeletePending(recordIDs(batch)); err != nil {
+			log.Printf("Observability Warning: Failed to remove delivered records from the pending queue: %v", err)
+		}
+	}
+	settleDurable(batch)
+}
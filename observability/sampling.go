@@ -0,0 +1,57 @@
+package observability
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+const sampleRateEnv = "ITHENA_SAMPLE_RATE"
+
+// sampleRate is the fraction of successful records SendLog keeps, between 0 and 1. Records
+// with any other status are always kept.
+var sampleRate = 1.0
+
+// SetSampleRate sets the fraction of successful records to keep, e.g. "0.1". An empty value
+// uses ITHENA_SAMPLE_RATE, then 1 (keep everything).
+func SetSampleRate(value string) error {
+	source := "--sample-rate"
+	if value == "" {
+		value, source = strings.TrimSpace(os.Getenv(sampleRateEnv)), sampleRateEnv
+	}
+	if value == "" {
+		sampleRate = 1
+		return nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(rate) || rate < 0 || rate > 1 {
+		return fmt.Errorf("invalid %s value '%s' (expected a number between 0 and 1)", source, value)
+	}
+	sampleRate = rate
+	if verbose { log.Printf("Observability: Keeping %.4g of successful records (sample rate from %s); failures are always kept.", rate, source) }
+	return nil
+}
+
+// sampledOut decides whether SendLog drops record. Only successful records and notifications
+// are sampled, so every failure, timeout and error is still recorded.
+func sampledOut(record types.AuditRecord) bool {
+	if sampleRate >= 1 || (record.Status != types.StatusSuccess && record.Status != types.StatusNotification) {
+		return false
+	}
+	return rand.Float64() >= sampleRate
+}
+
+// applySampleRate marks session metadata with the sample rate in effect, so record counts
+// derived from it can be recognized as sampled.
+func applySampleRate(meta *types.SessionMetadata) {
+	if meta != nil && sampleRate < 1 {
+		rate := sampleRate
+		meta.SampleRate = &rate
+	}
+}
@@ -0,0 +1,32 @@
+package observability
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// sendMu is held for reading around every send on logChan and for writing while
+// ShutdownObservability closes it, so a send never races with the close.
+var sendMu sync.RWMutex
+
+// logChanClosed is set once ShutdownObservability has closed logChan. Records sent later,
+// e.g. by a request timeout sweep or a client notification arriving during shutdown, are
+// dropped instead of panicking on the closed channel.
+var logChanClosed atomic.Bool
+
+// shuttingDown is closed when ShutdownObservability starts, before it waits for sendMu, so
+// producers blocked on a full log channel by the "block" policies give up and let it close.
+var shuttingDown chan struct{}
+
+// shutdownStarted guards the close of shuttingDown.
+var shutdownStarted atomic.Bool
+
+// dropLateRecord counts a record sent after the worker shut down as dropped.
+func dropLateRecord(record types.AuditRecord) {
+	droppedRecords.Add(1)
+	log.Printf("Observability Warning: Dropping log Record ID %s: the log worker has already shut down.", record.ID)
+	traceRecord(record, "dropped", reasonShutDown, "discard")
+}
@@ -0,0 +1,250 @@
+package observability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+const (
+	// syslogAddrEnv enables the syslog exporter: "udp://host:514", "tcp://host:601" or "host:port" (UDP).
+	syslogAddrEnv = "ITHENA_SYSLOG_ADDR"
+	// syslogFacilityEnv names the facility of exported records, e.g. "local0" (default "user").
+	syslogFacilityEnv = "ITHENA_SYSLOG_FACILITY"
+	// syslogSeveritiesEnv overrides the severity per status, as "status=severity" pairs separated by commas.
+	syslogSeveritiesEnv = "ITHENA_SYSLOG_SEVERITIES"
+)
+
+// syslogDialTimeout bounds connecting to the syslog server, and each write over TCP so a
+// server that stops reading can't stall the log worker.
+const syslogDialTimeout = 5 * time.Second
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogSeverityNames = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// defaultSyslogSeverities maps statuses to severities; other statuses are notice.
+var defaultSyslogSeverities = map[string]int{
+	types.StatusSuccess:        6, // info
+	types.StatusFailure:        3, // err
+	types.StatusRPCError:       3,
+	types.StatusTransportError: 3,
+	types.StatusTimeout:        4, // warning
+	types.StatusCancelled:      4,
+	types.StatusRestarted:      4,
+}
+
+// syslogTimeFormat is RFC 3339 with at most the six fractional digits RFC 5424 allows.
+const syslogTimeFormat = "2006-01-02T15:04:05.999999Z07:00"
+
+// syslogNoticeSeverity is used for statuses without a mapping.
+const syslogNoticeSeverity = 5
+
+// syslogExporter sends every flushed record to a syslog server as an RFC 5424 message whose
+// MSG is the record as JSON. It is independent of where the record is stored or uploaded.
+type syslogExporter struct {
+	network    string // "udp" or "tcp"
+	addr       string
+	facility   int
+	severities map[string]int
+	hostname   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// syslogSink is the exporter configured by ITHENA_SYSLOG_ADDR, or nil.
+var syslogSink *syslogExporter
+
+// syslogExporterFromEnv builds the exporter from the environment; nil when ITHENA_SYSLOG_ADDR
+// is unset or invalid.
+func syslogExporterFromEnv() *syslogExporter {
+	value := strings.TrimSpace(os.Getenv(syslogAddrEnv))
+	if value == "" {
+		return nil
+	}
+	network, addr := "udp", value
+	if scheme, rest, ok := strings.Cut(value, "://"); ok {
+		network, addr = strings.ToLower(scheme), rest
+	}
+	if network != "udp" && network != "tcp" {
+		log.Printf("Observability Warning: Ignoring invalid %s value '%s': the scheme must be udp or tcp", syslogAddrEnv, value)
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		log.Printf("Observability Warning: Ignoring invalid %s value '%s': %v", syslogAddrEnv, value, err)
+		return nil
+	}
+
+	facility := syslogFacilities["user"]
+	if name := strings.ToLower(strings.TrimSpace(os.Getenv(syslogFacilityEnv))); name != "" {
+		if f, ok := syslogFacilities[name]; ok {
+			facility = f
+		} else {
+			log.Printf("Observability Warning: Ignoring invalid %s value '%s', using user", syslogFacilityEnv, name)
+		}
+	}
+
+	severities := make(map[string]int, len(defaultSyslogSeverities))
+	for status, severity := range defaultSyslogSeverities {
+		severities[status] = severity
+	}
+	for _, pair := range strings.Split(os.Getenv(syslogSeveritiesEnv), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		status, name, _ := strings.Cut(pair, "=")
+		severity, ok := syslogSeverityNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok || strings.TrimSpace(status) == "" {
+			log.Printf("Observability Warning: Ignoring invalid %s entry '%s' (expected status=severity, e.g. rpc_error=warning)", syslogSeveritiesEnv, pair)
+			continue
+		}
+		severities[strings.TrimSpace(status)] = severity
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	if verbose { log.Printf("Observability: Exporting records to syslog at %s://%s (facility %d)", network, addr, facility) }
+	return &syslogExporter{network: network, addr: addr, facility: facility, severities: severities, hostname: hostname}
+}
+
+// exportable returns the records of batch that may be sent to syslog. Like uploads, the
+// export leaves the machine, so nothing is exported for an offline profile and records of
+// local-only methods are left out.
+func exportable(batch []types.AuditRecord) []types.AuditRecord {
+	if offline {
+		return nil
+	}
+	var records []types.AuditRecord
+	for _, record := range batch {
+		if !isLocalOnly(record) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// export sends a batch of records. Failures are logged; they don't affect storage or upload.
+// A record too large for a UDP datagram is skipped.
+func (s *syslogExporter) export(batch []types.AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, record := range batch {
+		message, err := s.format(record)
+		if err != nil {
+			log.Printf("Observability Warning: Failed to format Record ID %s for syslog: %v", record.ID, err)
+			continue
+		}
+		if err := s.write(message); errors.Is(err, syscall.EMSGSIZE) {
+			log.Printf("Observability Warning: Record ID %s (%d bytes) is too large for a syslog datagram; not exporting it. Use a tcp:// address for large records.", record.ID, len(message))
+			continue
+		} else if err != nil {
+			log.Printf("Observability Warning: Failed to send %d record(s) to syslog at %s: %v", len(batch)-i, s.addr, err)
+			return
+		}
+	}
+}
+
+// write sends one message, connecting first if needed. A TCP connection that fails is
+// replaced once, since servers close idle connections.
+func (s *syslogExporter) write(message []byte) error {
+	if s.network == "tcp" {
+		// RFC 6587 octet counting, so messages may contain newlines.
+		message = append([]byte(fmt.Sprintf("%d ", len(message))), message...)
+	}
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			conn, err := net.DialTimeout(s.network, s.addr, syslogDialTimeout)
+			if err != nil {
+				return err
+			}
+			s.conn = conn
+		}
+		if s.network == "tcp" {
+			s.conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+		}
+		_, err := s.conn.Write(message)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, syscall.EMSGSIZE) {
+			// The connection is fine; only this datagram can't be sent.
+			return err
+		}
+		s.conn.Close()
+		s.conn = nil
+		if s.network != "tcp" || attempt > 0 {
+			return err
+		}
+	}
+}
+
+// format renders a record as an RFC 5424 message: the MSGID is the record's status and the
+// MSG the record as JSON.
+func (s *syslogExporter) format(record types.AuditRecord) ([]byte, error) {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	severity, ok := s.severities[record.Status]
+	if !ok {
+		severity = syslogNoticeSeverity
+	}
+	at, err := time.Parse(time.RFC3339Nano, record.Timestamp)
+	if err != nil {
+		at = time.Now().UTC()
+	}
+	timestamp := at.Format(syslogTimeFormat)
+	header := fmt.Sprintf("<%d>1 %s %s ithena-cli %d %s - ",
+		s.facility*8+severity, timestamp, syslogHeaderField(s.hostname, 255), os.Getpid(), syslogHeaderField(record.Status, 32))
+	return append([]byte(header), body...), nil
+}
+
+// syslogHeaderField makes value a valid RFC 5424 header field: printable ASCII without
+// spaces, at most max characters, "-" when empty.
+func syslogHeaderField(value string, max int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 0x21 || r > 0x7e {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(field) > max {
+		field = field[:max]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
+
+// closeSyslog closes the exporter's connection, if any.
+func closeSyslog() {
+	if syslogSink == nil {
+		return
+	}
+	syslogSink.mu.Lock()
+	defer syslogSink.mu.Unlock()
+	if syslogSink.conn != nil {
+		syslogSink.conn.Close()
+		syslogSink.conn = nil
+	}
+}
@@ -0,0 +1,138 @@
+package observability
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// toolNameFieldsEnv maps MCP methods to the request param holding the tool name, as
+// comma-separated "method=param" pairs, e.g. "tools/call=name,custom/invoke=tool.id".
+// A param may be a dotted path into nested objects. It is checked after the configured
+// rules, so it only applies to methods they don't match.
+const toolNameFieldsEnv = "ITHENA_TOOL_NAME_FIELDS"
+
+// ToolExtractionRule says where a method's request params keep the tool name and arguments.
+// Method matches exactly, or by prefix when it ends in "*". ToolName and ToolArgs are JSON
+// pointers into the params (e.g. "/name", "/tool/args"); a path without a leading "/" is read
+// as dot-separated keys. An empty path extracts nothing.
+type ToolExtractionRule struct {
+	Method   string
+	ToolName string
+	ToolArgs string
+}
+
+// defaultToolExtractionRules cover MCP's tools/call and the older tool/call naming.
+var defaultToolExtractionRules = []ToolExtractionRule{
+	{Method: "tools/call", ToolName: "/name", ToolArgs: "/arguments"},
+	{Method: "tool/call", ToolName: "/tool_name", ToolArgs: "/arguments"},
+}
+
+// compiledToolRule is a ToolExtractionRule with its paths split into keys.
+type compiledToolRule struct {
+	method   string
+	prefix   bool
+	nameKeys []string
+	argsKeys []string
+}
+
+var toolExtractionRules atomic.Pointer[[]compiledToolRule]
+
+// SetToolExtraction configures how tool names and arguments are extracted from requests.
+// The first matching rule wins, checked in this order: rules (the config's tool_extraction,
+// followed by its deprecated tool_name_fields), then ITHENA_TOOL_NAME_FIELDS, then the
+// built-in defaults.
+func SetToolExtraction(rules []ToolExtractionRule) {
+	all := append([]ToolExtractionRule{}, rules...)
+	for _, pair := range strings.Split(os.Getenv(toolNameFieldsEnv), ",") {
+		if method, param, ok := strings.Cut(pair, "="); ok {
+			all = append(all, ToolExtractionRule{Method: method, ToolName: param})
+		}
+	}
+	all = append(all, defaultToolExtractionRules...)
+
+	compiled := make([]compiledToolRule, 0, len(all))
+	for _, rule := range all {
+		method := strings.TrimSpace(rule.Method)
+		if method == "" {
+			continue
+		}
+		c := compiledToolRule{
+			method:   strings.TrimSuffix(method, "*"),
+			prefix:   strings.HasSuffix(method, "*"),
+			nameKeys: splitParamPath(rule.ToolName),
+			argsKeys: splitParamPath(rule.ToolArgs),
+		}
+		if c.nameKeys == nil && c.argsKeys == nil {
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	toolExtractionRules.Store(&compiled)
+}
+
+// splitParamPath splits a JSON pointer ("/a/b", with ~1 and ~0 escapes) or a dotted path
+// ("a.b") into keys. It returns nil for an empty path.
+func splitParamPath(path string) []string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return strings.Split(path, ".")
+	}
+	keys := strings.Split(path[1:], "/")
+	for i, key := range keys {
+		keys[i] = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+	}
+	return keys
+}
+
+// extractTool returns the tool name and arguments from a request's params according to the
+// first rule matching method. Either may be nil when the rule or the params don't provide it.
+func extractTool(method *string, params interface{}) (name *string, args interface{}) {
+	if method == nil {
+		return nil, nil
+	}
+	rules := toolExtractionRules.Load()
+	if rules == nil {
+		SetToolExtraction(nil)
+		rules = toolExtractionRules.Load()
+	}
+	for _, rule := range *rules {
+		if *method != rule.method && !(rule.prefix && strings.HasPrefix(*method, rule.method)) {
+			continue
+		}
+		if value, ok := lookupParam(params, rule.nameKeys).(string); ok && value != "" {
+			name = &value
+		}
+		if rule.argsKeys != nil {
+			args = lookupParam(params, rule.argsKeys)
+		}
+		return name, args
+	}
+	return nil, nil
+}
+
+// lookupParam walks keys through nested objects and arrays, returning nil if any step is missing.
+func lookupParam(value interface{}, keys []string) interface{} {
+	if keys == nil {
+		return nil
+	}
+	for _, key := range keys {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
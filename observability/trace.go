@@ -0,0 +1,58 @@
+package observability
+
+import (
+	"log"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// traceDecisions enables one structured line per routing decision and outcome, independent
+// of verbose so it can be turned on without the rest of the debug output.
+var traceDecisions bool
+
+// SetTraceDecisions enables or disables decision tracing for the observability package.
+func SetTraceDecisions(enabled bool) {
+	traceDecisions = enabled
+}
+
+// Routing reasons reported by decision traces.
+const (
+	reasonNotAuthenticated   = "not_authenticated"
+	reasonOffline            = "offline"
+	reasonLocalOnlyMethod    = "local_only_method"
+	reasonUrlNotAllowlisted  = "observe_url_not_allowlisted"
+	reasonAuthenticated      = "authenticated"
+	reasonQueueFull          = "queue_full"
+	reasonUploadFailed       = "upload_failed"
+	reasonUploaded           = "upload_accepted"
+	reasonLocalStoreFailed   = "local_store_failed"
+	reasonLocalStoreOK       = "local_store_ok"
+	reasonRecoveredFromQueue = "recovered_pending"
+	reasonSampled            = "sampled_out"
+	reasonPaused             = "capture_paused"
+	reasonShutDown           = "worker_shut_down"
+)
+
+// traceRecord logs a single decision for a record as logfmt-style key=value pairs:
+//
+//	Observability Trace: record=<id> method=<method> decision=<decision> reason=<reason> action=<action>
+func traceRecord(record types.AuditRecord, decision, reason, action string) {
+	if !traceDecisions {
+		return
+	}
+	method := ""
+	if record.McpMethod != nil {
+		method = *record.McpMethod
+	}
+	log.Printf("Observability Trace: record=%s method=%q decision=%s reason=%s action=%s", record.ID, method, decision, reason, action)
+}
+
+// traceBatch logs the same decision for every record in a batch.
+func traceBatch(batch []types.AuditRecord, decision, reason, action string) {
+	if !traceDecisions {
+		return
+	}
+	for _, record := range batch {
+		traceRecord(record, decision, reason, action)
+	}
+}
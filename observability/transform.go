@@ -0,0 +1,223 @@
+package observability
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Environment variables that configure preview transforms.
+const (
+	sanitizePathsEnv        = "ITHENA_SANITIZE_PATHS"         // "true" rewrites the user's home directory to "~"
+	sanitizePathPrefixesEnv = "ITHENA_SANITIZE_PATH_PREFIXES" // Comma-separated path prefixes to strip
+)
+
+// redactedValue replaces the values of keys listed in TransformRules.RedactKeys.
+const redactedValue = "[REDACTED]"
+
+// TransformRules configures how request/response previews are rewritten before they are
+// stored locally or uploaded. The zero value leaves previews untouched.
+type TransformRules struct {
+	SanitizeHomeDir   bool     // Replace the user's home directory with "~"
+	StripPathPrefixes []string // Remove these path prefixes wherever they appear
+	RedactKeys        []string // Replace values of object keys with these names (case-insensitive) by "[REDACTED]"
+	HashKeys          []string // Replace values of object keys with these names (case-insensitive) by a keyed hash (see hashValue)
+}
+
+// transformState is the compiled form of TransformRules used on the hot path.
+type transformState struct {
+	homeDir    string
+	prefixes   []string
+	redactKeys map[string]bool
+	hashKeys   map[string]bool
+}
+
+func (t *transformState) empty() bool {
+	return t.homeDir == "" && len(t.prefixes) == 0 && len(t.redactKeys) == 0 && len(t.hashKeys) == 0
+}
+
+var activeTransforms atomic.Pointer[transformState]
+
+// SetTransformRules replaces the active transform rules; rules from the ITHENA_SANITIZE_*
+// environment variables are always applied in addition. The swap is atomic, so it is safe to
+// call while records are flowing: each record sees either the old or the new rules.
+func SetTransformRules(rules TransformRules) {
+	envRules := transformRulesFromEnv()
+	rules.SanitizeHomeDir = rules.SanitizeHomeDir || envRules.SanitizeHomeDir
+	rules.StripPathPrefixes = append(append([]string{}, rules.StripPathPrefixes...), envRules.StripPathPrefixes...)
+
+	state := &transformState{
+		redactKeys: keySet(rules.RedactKeys),
+		hashKeys:   keySet(rules.HashKeys),
+	}
+	if rules.SanitizeHomeDir {
+		if home, err := os.UserHomeDir(); err == nil && home != "" && home != string(filepath.Separator) {
+			state.homeDir = filepath.Clean(home)
+		}
+	}
+	for _, prefix := range rules.StripPathPrefixes {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		state.prefixes = append(state.prefixes, prefix)
+	}
+	// Strip the most specific prefixes first so nested prefixes don't leave fragments behind.
+	sort.Slice(state.prefixes, func(i, j int) bool { return len(state.prefixes[i]) > len(state.prefixes[j]) })
+	activeTransforms.Store(state)
+}
+
+// transformRulesFromEnv reads the transform rules configured through environment variables.
+func transformRulesFromEnv() TransformRules {
+	rules := TransformRules{
+		SanitizeHomeDir: strings.EqualFold(strings.TrimSpace(os.Getenv(sanitizePathsEnv)), "true"),
+	}
+	if prefixes := os.Getenv(sanitizePathPrefixesEnv); prefixes != "" {
+		rules.StripPathPrefixes = strings.Split(prefixes, ",")
+	}
+	return rules
+}
+
+// transformPreview applies the active transforms to every string value in a decoded JSON
+// preview. Map keys are left as-is. The input is not modified; a rewritten copy is returned.
+func transformPreview(value interface{}) interface{} {
+	state := activeTransforms.Load()
+	if state == nil || state.empty() || value == nil {
+		return value
+	}
+	return state.apply(value)
+}
+
+func (t *transformState) apply(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return t.sanitizePaths(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = t.applyToKey(key, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = t.apply(item)
+		}
+		return out
+	case map[string]string: // Error details built by CreateAuditRecordForError
+		out := make(map[string]string, len(v))
+		for key, item := range v {
+			out[key], _ = t.applyToKey(key, item).(string)
+		}
+		return out
+	case bool, float64, int, int64, nil:
+		return value
+	default:
+		// Typed values such as *jsonrpc.Error are normalized to their JSON form first.
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return value
+		}
+		var normalized interface{}
+		if err := json.Unmarshal(encoded, &normalized); err != nil {
+			return value
+		}
+		return t.apply(normalized)
+	}
+}
+
+// applyToKey transforms the value stored under an object key, redacting or hashing it whole
+// when the key is listed, and otherwise transforming it recursively.
+func (t *transformState) applyToKey(key string, value interface{}) interface{} {
+	lower := strings.ToLower(key)
+	if t.redactKeys[lower] {
+		return redactedValue
+	}
+	if t.hashKeys[lower] {
+		return hashValue(value)
+	}
+	return t.apply(value)
+}
+
+// hashedValuePrefix labels values replaced by hashValue.
+const hashedValuePrefix = "hmac-sha256-64:"
+
+// hashValue returns the first 64 bits of an HMAC-SHA256 of value's JSON form, keyed per
+// install (see previewHashKey), so equal values can still be correlated across records
+// without revealing them. A plain digest could be reversed by hashing likely values.
+func hashValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return redactedValue
+	}
+	mac := hmac.New(sha256.New, previewHashKey())
+	mac.Write(encoded)
+	return hashedValuePrefix + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// keySet lowercases keys into a set; nil when there are none.
+func keySet(keys []string) map[string]bool {
+	var set map[string]bool
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[key] = true
+	}
+	return set
+}
+
+// sanitizePaths strips configured prefixes and replaces the home directory with "~", both
+// only where they start a path (see replaceAtPathBoundary).
+func (t *transformState) sanitizePaths(s string) string {
+	for _, prefix := range t.prefixes {
+		s = replaceAtPathBoundary(s, prefix, "")
+	}
+	if t.homeDir != "" {
+		s = replaceAtPathBoundary(s, t.homeDir, "~")
+	}
+	return s
+}
+
+// replaceAtPathBoundary replaces occurrences of path that are not part of a longer path
+// component, so a home of "/home/al" leaves "/home/alice" and "/srv/home/al" alone but
+// rewrites "file:///home/al/x". A path ending in "/" (a prefix) may be followed by anything.
+func replaceAtPathBoundary(s, path, replacement string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, path)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(path)
+		startsAtBoundary := i == 0 || !isPathNameChar(s[i-1])
+		endsAtBoundary := end == len(s) || strings.HasSuffix(path, "/") || !isPathNameChar(s[end])
+		if startsAtBoundary && endsAtBoundary {
+			b.WriteString(s[:i])
+			b.WriteString(replacement)
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+}
+
+// isPathNameChar reports whether c can continue a path component: [A-Za-z0-9._-].
+func isPathNameChar(c byte) bool {
+	return c == '.' || c == '_' || c == '-' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,97 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+const maxPreviewBytesEnv = "ITHENA_MAX_PREVIEW_BYTES"
+
+// defaultMaxPreviewBytes is the largest preview kept whole unless configured otherwise.
+const defaultMaxPreviewBytes = 1 << 20
+
+// truncationMarkerBytes is roughly the size of a truncation marker without its preview text.
+const truncationMarkerBytes = 64
+
+// maxPreviewBytes is the largest JSON size of a request preview, response preview or tool
+// arguments kept whole; larger ones are replaced by a truncation marker. 0 disables the limit.
+var maxPreviewBytes int64 = defaultMaxPreviewBytes
+
+// SetMaxPreviewBytes sets the largest preview kept whole, in bytes of JSON; "0" disables
+// truncation. An empty value uses ITHENA_MAX_PREVIEW_BYTES, then 1 MiB.
+func SetMaxPreviewBytes(value string) error {
+	source := "--max-preview-bytes"
+	if value == "" {
+		value, source = strings.TrimSpace(os.Getenv(maxPreviewBytesEnv)), maxPreviewBytesEnv
+	}
+	if value == "" {
+		maxPreviewBytes = defaultMaxPreviewBytes
+		return nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit < 0 {
+		return fmt.Errorf("invalid %s value '%s' (expected a number of bytes, or 0 for no limit)", source, value)
+	}
+	maxPreviewBytes = limit
+	if verbose { log.Printf("Observability: Truncating previews larger than %d bytes (from %s).", limit, source) }
+	return nil
+}
+
+// truncatePreviews replaces the previews of record that exceed maxPreviewBytes. The raw message
+// sizes bound the previews taken from them, so previews of small messages are not re-encoded.
+func truncatePreviews(record *types.AuditRecord) {
+	if maxPreviewBytes <= 0 {
+		return
+	}
+	record.RequestPreview = truncatePreview(record.RequestPreview, record.RequestBytes)
+	record.ToolArgs = truncatePreview(record.ToolArgs, record.RequestBytes)
+	record.ResponsePreview = truncatePreview(record.ResponsePreview, record.ResponseBytes)
+}
+
+// truncatePreview returns value, or a marker such as {"_truncated": true, "original_bytes": N,
+// "preview": "..."} if its JSON is larger than maxPreviewBytes. The marker is itself valid JSON,
+// with as much of the original JSON text in "preview" as keeps it within the limit.
+func truncatePreview(value interface{}, rawBytes *int64) interface{} {
+	if value == nil || (rawBytes != nil && *rawBytes > 0 && *rawBytes <= maxPreviewBytes) {
+		return value
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil || int64(len(encoded)) <= maxPreviewBytes {
+		return value
+	}
+	return map[string]interface{}{
+		"_truncated":     true,
+		"original_bytes": len(encoded),
+		"preview":        jsonStringPrefix(encoded, maxPreviewBytes-truncationMarkerBytes),
+	}
+}
+
+// jsonStringPrefix returns the longest prefix of b, cut at a character boundary, whose
+// encoding as a JSON string takes at most budget bytes.
+func jsonStringPrefix(b []byte, budget int64) string {
+	var used int64
+	end := 0
+	for end < len(b) {
+		r, size := utf8.DecodeRune(b[end:])
+		cost := int64(size)
+		switch {
+		case r == '"' || r == '\\':
+			cost = 2
+		case r < 0x20 || r == '<' || r == '>' || r == '&' || r == '\u2028' || r == '\u2029' || r == utf8.RuneError:
+			cost = 6 // Escaped as \uXXXX by encoding/json
+		}
+		if used+cost > budget {
+			break
+		}
+		used += cost
+		end += size
+	}
+	return string(b[:end])
+}
@@ -0,0 +1,329 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Wire formats accepted by SetWireFormat and ITHENA_WIRE_FORMAT.
+const (
+	WireFormatJSON    = "json"
+	WireFormatMsgpack = "msgpack"
+)
+
+const (
+	wireFormatEnv = "ITHENA_WIRE_FORMAT"
+
+	contentTypeJSON    = "application/json"
+	contentTypeMsgpack = "application/msgpack"
+)
+
+// wireFormat is the encoding used for uploads. It starts as configured and falls back to
+// JSON for the rest of the process when the platform rejects the binary format.
+var wireFormat atomic.Value // string
+
+// SetWireFormat selects the upload encoding. An empty value uses ITHENA_WIRE_FORMAT, then JSON.
+func SetWireFormat(format string) error {
+	if format == "" {
+		format = strings.TrimSpace(os.Getenv(wireFormatEnv))
+	}
+	switch strings.ToLower(format) {
+	case "", WireFormatJSON:
+		wireFormat.Store(WireFormatJSON)
+	case WireFormatMsgpack:
+		wireFormat.Store(WireFormatMsgpack)
+	default:
+		return fmt.Errorf("unknown wire format '%s' (expected '%s' or '%s')", format, WireFormatJSON, WireFormatMsgpack)
+	}
+	return nil
+}
+
+func currentWireFormat() string {
+	if format, ok := wireFormat.Load().(string); ok {
+		return format
+	}
+	return WireFormatJSON
+}
+
+// fallBackToJSON switches uploads to JSON after the platform answered 415 Unsupported Media Type.
+func fallBackToJSON() {
+	if wireFormat.Swap(WireFormatJSON) != WireFormatJSON {
+		log.Printf("Observability Warning: Platform does not accept %s uploads, falling back to JSON.", contentTypeMsgpack)
+	}
+}
+
+// marshalBatch encodes a batch in the current wire format and returns its Content-Type.
+//
+// The MessagePack schema mirrors the JSON one: the batch is an array of maps keyed by the
+// msgpack tags of AuditRecord, which match its JSON field names, with omitted fields left out,
+// so a backend can decode either format into the same structure. Records are encoded
+// directly rather than through their JSON form.
+func marshalBatch(batch interface{}) ([]byte, string, error) {
+	if currentWireFormat() != WireFormatMsgpack {
+		jsonBytes, err := json.Marshal(batch)
+		if err != nil {
+			return nil, "", err
+		}
+		return jsonBytes, contentTypeJSON, nil
+	}
+
+	var buf bytes.Buffer
+	if err := writeMsgpackValue(&buf, reflect.ValueOf(batch)); err != nil {
+		return nil, "", err
+	}
+	if verbose { log.Printf("Observability: Encoded batch as MessagePack (%d bytes)", buf.Len()) }
+	return buf.Bytes(), contentTypeMsgpack, nil
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// writeMsgpackValue encodes v as MessagePack. Structs are maps keyed by their msgpack tags
+// (with omitempty honoured), maps with string keys are written with sorted keys, and whole
+// numbers stored as floats, as in decoded previews, are written as integers. Values with
+// their own JSON encoding, and structs without msgpack tags, are encoded from their JSON form.
+func writeMsgpackValue(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteByte(0xc0)
+		return nil
+	}
+	if (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && v.IsNil() {
+		buf.WriteByte(0xc0)
+		return nil
+	}
+	if v.Kind() != reflect.Interface && v.Type().Implements(jsonMarshalerType) {
+		return writeMsgpackJSON(buf, v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return writeMsgpackValue(buf, v.Elem())
+	case reflect.Bool:
+		return writeMsgpack(buf, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeMsgpackInt(buf, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			buf.WriteByte(0xcf)
+			binary.Write(buf, binary.BigEndian, v.Uint())
+			return nil
+		}
+		writeMsgpackInt(buf, int64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			writeMsgpackInt(buf, int64(f))
+			return nil
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("msgpack: unsupported value %v", f)
+		}
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case reflect.String:
+		return writeMsgpack(buf, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// Bytes are a base64 string in JSON; keep that shape.
+			return writeMsgpackJSON(buf, v.Interface())
+		}
+		writeMsgpackHeader(buf, v.Len(), 0x90, 16, 0, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if err := writeMsgpackValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return writeMsgpackJSON(buf, v.Interface())
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		writeMsgpackHeader(buf, len(keys), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			writeMsgpack(buf, key)
+			if err := writeMsgpackValue(buf, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return writeMsgpackStruct(buf, v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// writeMsgpackStruct writes the fields of v that have a msgpack tag as a map, keys sorted.
+func writeMsgpackStruct(buf *bytes.Buffer, v reflect.Value) error {
+	type field struct {
+		name  string
+		value reflect.Value
+	}
+	var fields []field
+	tagged := false
+	for i := 0; i < v.NumField(); i++ {
+		tag, ok := v.Type().Field(i).Tag.Lookup("msgpack")
+		if !ok || tag == "-" || !v.Type().Field(i).IsExported() {
+			continue
+		}
+		tagged = true
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = v.Type().Field(i).Name
+		}
+		if options == "omitempty" && isEmptyMsgpackValue(v.Field(i)) {
+			continue
+		}
+		fields = append(fields, field{name, v.Field(i)})
+	}
+	if !tagged {
+		return writeMsgpackJSON(buf, v.Interface())
+	}
+	// Sorted like map keys, so a record encodes the same as its JSON form did.
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	writeMsgpackHeader(buf, len(fields), 0x80, 16, 0, 0xde, 0xdf)
+	for _, f := range fields {
+		writeMsgpack(buf, f.name)
+		if err := writeMsgpackValue(buf, f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmptyMsgpackValue reports whether omitempty leaves v out, using the rules of encoding/json.
+func isEmptyMsgpackValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// writeMsgpackJSON encodes v through its JSON form, for values that define their own.
+func writeMsgpackJSON(buf *bytes.Buffer, v interface{}) error {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+	return writeMsgpack(buf, generic)
+}
+
+// writeMsgpack encodes a value produced by json.Decoder (with UseNumber) as MessagePack.
+func writeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			writeMsgpackInt(buf, i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case string:
+		writeMsgpackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []interface{}:
+		writeMsgpackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeMsgpackHeader(buf, len(keys), 0x80, 16, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			writeMsgpack(buf, k)
+			if err := writeMsgpack(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+// writeMsgpackHeader writes a length-prefixed type header: the fix form when n < fixLimit,
+// otherwise the 8-bit (if the type has one), 16-bit or 32-bit form.
+func writeMsgpackHeader(buf *bytes.Buffer, n int, fix byte, fixLimit int, code8, code16, code32 byte) {
+	switch {
+	case n < fixLimit:
+		buf.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(code8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(code32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// writeMsgpackInt writes i in the smallest MessagePack integer form.
+func writeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127, i < 0 && i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(i))
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, uint64(i))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
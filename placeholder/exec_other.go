@@ -0,0 +1,14 @@
+//go:build !unix
+
+package placeholder
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves cmd as is: there are no process groups to kill here, so
+// cancelling kills only the command itself, and WaitDelay stops waiting for its children.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
+
+// killProcessGroup does nothing; see killProcessGroupOnCancel.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return nil
+}
@@ -0,0 +1,67 @@
+package placeholder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LintIssue is a problem found by LintPlaceholders. Warnings flag references that will
+// probably fail to resolve on this machine; everything else is a syntax error.
+type LintIssue struct {
+	Message string
+	Warning bool
+}
+
+// LintPlaceholders checks the {{type:value}} placeholders in value without resolving them:
+// no keyring lookups, file reads or exec commands are performed.
+func LintPlaceholders(value string) []LintIssue {
+	var issues []LintIssue
+	rest := value
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return issues
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return append(issues, LintIssue{Message: fmt.Sprintf("unterminated placeholder '%s'", rest[start:])})
+		}
+		match := rest[start : start+end+2]
+		rest = rest[start+end+2:]
+
+		parts := placeholderRegex.FindStringSubmatch(match)
+		if len(parts) != 3 || parts[0] != match {
+			issues = append(issues, LintIssue{Message: fmt.Sprintf("invalid placeholder '%s', expected {{env|keyring|file|exec:value}}", match)})
+			continue
+		}
+		if issue, ok := lintPlaceholder(strings.TrimSpace(parts[1]), strings.TrimSpace(parts[2])); ok {
+			issues = append(issues, issue)
+		}
+	}
+}
+
+// lintPlaceholder checks a single well-formed placeholder.
+func lintPlaceholder(placeholderType, placeholderValue string) (LintIssue, bool) {
+	switch placeholderType {
+	case "env":
+		name, _, hasDefault := strings.Cut(placeholderValue, ":-")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return LintIssue{Message: "env placeholder has no variable name"}, true
+		}
+		if _, found := os.LookupEnv(name); !found && !hasDefault {
+			return LintIssue{Message: fmt.Sprintf("environment variable '%s' is not set", name), Warning: true}, true
+		}
+	case "keyring":
+		krParts := strings.SplitN(placeholderValue, ":", 2)
+		if len(krParts) != 2 || krParts[0] == "" || krParts[1] == "" {
+			return LintIssue{Message: fmt.Sprintf("invalid keyring format '%s', expected 'service:account'", placeholderValue)}, true
+		}
+	case "file":
+		if _, err := os.Stat(placeholderValue); err != nil {
+			return LintIssue{Message: fmt.Sprintf("file '%s' is not readable: %v", placeholderValue, err), Warning: true}, true
+		}
+	}
+	return LintIssue{}, false
+}
@@ -1,25 +1,73 @@
 package placeholder
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/zalando/go-keyring"
 )
 
 // Regular expression to find placeholders like {{type:value}}
-var placeholderRegex = regexp.MustCompile(`{{\s*(env|keyring|file)\s*:\s*([^}]+)\s*}}`)
+var placeholderRegex = regexp.MustCompile(`{{\s*(env|keyring|file|exec)\s*:\s*([^}]+)\s*}}`)
+
+// execPlaceholderTimeout bounds how long an {{exec:...}} command may run.
+const execPlaceholderTimeout = 30 * time.Second
+
+// execPlaceholderWaitDelay bounds how long an {{exec:...}} command's output is still read
+// after it was killed or exited, in case a process it started keeps the pipes open.
+const execPlaceholderWaitDelay = 2 * time.Second
+
+// Resolution describes how one placeholder was resolved, without its value, so it can be
+// shown even when the value is a secret.
+type Resolution struct {
+	Key         string // Env key whose value contains the placeholder
+	Placeholder string // The placeholder as written, e.g. "{{env:TOKEN:-none}}"
+	Source      string // env, keyring, file or exec
+	From        string // Variable name, service:account, file path or command
+	UsedDefault bool   // An {{env:NAME:-fallback}} whose variable was unset or empty
+	Err         error  // Why it could not be resolved, nil on success
+}
 
 // ResolvePlaceholders takes a map representing environment variables (potentially with placeholders)
 // and returns a new map with placeholders resolved.
 func ResolvePlaceholders(envMap map[string]string) (map[string]string, error) {
+	resolvedEnv, _, err := resolvePlaceholders(envMap, false)
+	return resolvedEnv, err
+}
+
+// ResolvePlaceholdersWithReport is ResolvePlaceholders that also reports where each placeholder
+// was resolved from, sorted by key, e.g. to check that a secret came from the intended source.
+// Placeholders after a failed one in the same value are not attempted or reported.
+func ResolvePlaceholdersWithReport(envMap map[string]string) (map[string]string, []Resolution, error) {
+	return resolvePlaceholders(envMap, true)
+}
+
+func resolvePlaceholders(envMap map[string]string, withReport bool) (map[string]string, []Resolution, error) {
 	resolvedEnv := make(map[string]string)
 	var firstError error // Variable to capture the first error encountered
+	var report []Resolution
+
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	for key, value := range envMap {
-		resolvedValue, err := resolveValue(value)
+	for _, key := range keys {
+		value := envMap[key]
+		var resolutions *[]Resolution
+		if withReport {
+			resolutions = &report
+		}
+		resolvedValue, err := resolveValue(key, value, resolutions)
 		if err != nil {
 			// Capture the first error and add context
 			if firstError == nil {
@@ -33,12 +81,13 @@ func ResolvePlaceholders(envMap map[string]string) (map[string]string, error) {
 	}
 
 	// Return the first error encountered during resolution, if any
-	return resolvedEnv, firstError
+	return resolvedEnv, report, firstError
 }
 
-// resolveValue processes a single string value, resolving any placeholders within it.
-// It returns the potentially modified string and an error if resolution fails.
-func resolveValue(value string) (string, error) {
+// resolveValue processes a single string value of key, resolving any placeholders within it.
+// It returns the potentially modified string and an error if resolution fails. When report is
+// non-nil, a Resolution is appended to it for every placeholder attempted.
+func resolveValue(key string, value string, report *[]Resolution) (string, error) {
 	var firstResolutionError error
 
 	resolved := placeholderRegex.ReplaceAllStringFunc(value, func(match string) string {
@@ -46,6 +95,13 @@ func resolveValue(value string) (string, error) {
 		if firstResolutionError != nil {
 			return match
 		}
+		resolution := Resolution{Key: key, Placeholder: match}
+		if report != nil {
+			defer func() {
+				resolution.Err = firstResolutionError
+				*report = append(*report, resolution)
+			}()
+		}
 
 		parts := placeholderRegex.FindStringSubmatch(match)
 		if len(parts) != 3 {
@@ -55,12 +111,22 @@ func resolveValue(value string) (string, error) {
 
 		placeholderType := strings.TrimSpace(parts[1])
 		placeholderValue := strings.TrimSpace(parts[2])
+		resolution.Source, resolution.From = placeholderType, placeholderValue
 
 		switch placeholderType {
 		case "env":
-			envVal, found := os.LookupEnv(placeholderValue)
+			// Shell-style default: {{env:NAME:-fallback}} uses fallback when NAME is unset or
+			// empty. Only the first ":-" separates the name, so the fallback may contain ":-".
+			name, fallback, hasDefault := strings.Cut(placeholderValue, ":-")
+			name = strings.TrimSpace(name)
+			resolution.From = name
+			envVal, found := os.LookupEnv(name)
+			if hasDefault && envVal == "" {
+				resolution.UsedDefault = true
+				return fallback
+			}
 			if !found {
-				firstResolutionError = fmt.Errorf("environment variable '%s' not found", placeholderValue)
+				firstResolutionError = fmt.Errorf("environment variable '%s' not found", name)
 				return match
 			}
 			return envVal
@@ -85,6 +151,13 @@ func resolveValue(value string) (string, error) {
 				return match
 			}
 			return strings.TrimSpace(string(contentBytes))
+		case "exec":
+			output, err := runExecPlaceholder(placeholderValue)
+			if err != nil {
+				firstResolutionError = fmt.Errorf("exec placeholder '%s' failed: %w", match, err)
+				return match
+			}
+			return output
 		default:
 			// Should not happen with the current regex
 			firstResolutionError = fmt.Errorf("unknown placeholder type '%s'", placeholderType)
@@ -94,4 +167,37 @@ func resolveValue(value string) (string, error) {
 
 	// Return the processed string and the first error encountered during ReplaceAllStringFunc
 	return resolved, firstResolutionError
-} 
\ No newline at end of file
+}
+
+// runExecPlaceholder runs command through the shell and returns its trimmed stdout.
+// A non-zero exit or timeout is an error that includes the command's stderr. The command
+// runs in its own process group, which is killed as a whole on timeout, so processes it
+// started don't outlive it.
+func runExecPlaceholder(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), execPlaceholderTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = execPlaceholderWaitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// The command exited successfully, but a process it started in the background kept
+		// its output open. Its output is complete; stop the leftovers.
+		killProcessGroup(cmd)
+		err = nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("timed out after %s", execPlaceholderTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -1,19 +1,55 @@
 package types
 
+// Known AuditRecord.Status values. Status is an open set: any other non-empty string is stored,
+// filtered and grouped the same way, so new classifications need no schema or filter changes.
+const (
+	StatusSuccess        = "success"         // The call completed normally
+	StatusFailure        = "failure"         // Generic failure (e.g. a wrapper-side error)
+	StatusRPCError       = "rpc_error"       // The server answered with a JSON-RPC error
+	StatusTimeout        = "timeout"         // No response arrived in time
+	StatusCancelled      = "cancelled"       // The client cancelled the request
+	StatusTransportError = "transport_error" // The connection to the server failed
+	StatusRestarted      = "restarted"       // The server process restarted before responding
+	StatusBinaryStream   = "binary_stream"   // Note that the server wrote bytes that are not valid UTF-8
+	StatusNotification   = "notification"    // A JSON-RPC notification, recorded when enabled
+)
+
+// KnownStatuses lists the built-in status values, in display order.
+var KnownStatuses = []string{
+	StatusSuccess, StatusFailure, StatusRPCError, StatusTimeout, StatusCancelled, StatusTransportError, StatusRestarted, StatusBinaryStream, StatusNotification,
+}
+
 // AuditRecord defines the structure for a log entry that can be sent to the platform
 // or stored locally.
 // Note: Fields that are pointers can be omitted (omitempty) if nil when marshalled to JSON.
 // For SQLite storage, these will need to be handled as sql.NullString, sql.NullInt64 etc.
 type AuditRecord struct {
-	ID                string      `json:"id"`
-	McpMethod         *string     `json:"mcp_method,omitempty"`
-	ToolName          *string     `json:"tool_name,omitempty"`
-	DurationMs        *int64      `json:"duration_ms,omitempty"`
-	Status            string      `json:"status"` // "success" or "failure"
-	ProxyVersion      *string     `json:"proxy_version,omitempty"`
-	TargetServerAlias *string     `json:"target_server_alias,omitempty"`
-	RequestPreview    interface{} `json:"request_preview,omitempty"`
-	ResponsePreview   interface{} `json:"response_preview,omitempty"`
-	ErrorDetails      interface{} `json:"error_details,omitempty"`
-	Timestamp         string      `json:"timestamp"` // ISO 8601 format string
-} 
\ No newline at end of file
+	ID                string           `json:"id" msgpack:"id"`
+	McpMethod         *string          `json:"mcp_method,omitempty" msgpack:"mcp_method,omitempty"`
+	ToolName          *string          `json:"tool_name,omitempty" msgpack:"tool_name,omitempty"`
+	ToolArgs          interface{}      `json:"tool_args,omitempty" msgpack:"tool_args,omitempty"` // Arguments of a tool call, extracted from the request params
+	DurationMs        *int64           `json:"duration_ms,omitempty" msgpack:"duration_ms,omitempty"`
+	FirstByteMs       *int64           `json:"first_byte_ms,omitempty" msgpack:"first_byte_ms,omitempty"` // Time until the first progress notification or response byte; DurationMs minus this is transfer time
+	Status            string           `json:"status" msgpack:"status"`                                   // One of the Status* constants or a custom value
+	ProxyVersion      *string          `json:"proxy_version,omitempty" msgpack:"proxy_version,omitempty"`
+	TargetServerAlias *string          `json:"target_server_alias,omitempty" msgpack:"target_server_alias,omitempty"`
+	RequestPreview    interface{}      `json:"request_preview,omitempty" msgpack:"request_preview,omitempty"`
+	ResponsePreview   interface{}      `json:"response_preview,omitempty" msgpack:"response_preview,omitempty"`
+	ErrorDetails      interface{}      `json:"error_details,omitempty" msgpack:"error_details,omitempty"`
+	Timestamp         string           `json:"timestamp" msgpack:"timestamp"`                               // ISO 8601 format string
+	Session           *SessionMetadata `json:"session,omitempty" msgpack:"session,omitempty"`               // Describes the wrapper session that produced the record
+	RequestBytes      *int64           `json:"request_bytes,omitempty" msgpack:"request_bytes,omitempty"`   // Raw size of the request message on the wire
+	ResponseBytes     *int64           `json:"response_bytes,omitempty" msgpack:"response_bytes,omitempty"` // Raw size of the response message on the wire
+	PendingUpload     bool             `json:"pending_upload,omitempty" msgpack:"pending_upload,omitempty"` // Local copy of a record whose upload failed and is queued for retry
+}
+
+// SessionMetadata identifies the wrapper process and wrapped command behind a set of records.
+// It is attached to every record produced during one wrapper invocation.
+type SessionMetadata struct {
+	SessionID      string            `json:"session_id" msgpack:"session_id"`
+	WrapperPID     int               `json:"wrapper_pid" msgpack:"wrapper_pid"`
+	CommandPath    string            `json:"command_path,omitempty" msgpack:"command_path,omitempty"`       // Resolved absolute path of the wrapped command
+	CommandVersion string            `json:"command_version,omitempty" msgpack:"command_version,omitempty"` // First line of `<command> --version`, only when probing is enabled
+	Environment    map[string]string `json:"environment,omitempty" msgpack:"environment,omitempty"`         // Allowlisted, non-secret environment snapshot, only when --capture-env is set
+	SampleRate     *float64          `json:"sample_rate,omitempty" msgpack:"sample_rate,omitempty"`         // Fraction of successful records kept, only when sampling is enabled
+}
@@ -1,6 +1,7 @@
 /* eslint-disable @typescript-eslint/no-explicit-any */
 import { useState, useEffect } from 'react';
 import ithenaLogo from '@/assets/ithena-logo.svg';
+import { apiUrl } from '@/lib/api';
 import { Button } from '@/components/ui/button';
 import { Badge } from '@/components/ui/badge';
 import {
@@ -53,7 +54,7 @@ export default function Header() {
     const fetchAuth = async () => {
       setIsLoadingAuth(true);
       try {
-        const response = await fetch('/api/auth/status');
+        const response = await fetch(apiUrl('/api/auth/status'));
         if (!response.ok) {
           throw new Error(`HTTP error! status: ${response.status}`);
         }
@@ -73,7 +74,7 @@ export default function Header() {
     const fetchCliVersion = async () => {
       setIsLoadingVersion(true);
       try {
-        const response = await fetch('/api/version');
+        const response = await fetch(apiUrl('/api/version'));
         if (!response.ok) {
           throw new Error(`HTTP error! status: ${response.status}`);
         }
@@ -1,8 +1,21 @@
 /* eslint-disable @typescript-eslint/no-explicit-any */
 import { useState, useEffect } from 'react';
 import ReactJson from 'react-json-view';
+import { apiUrl } from '@/lib/api';
 import { type LogEntry } from './types'; 
 
+// Formats a message size like `logs stats` does, e.g. "512 B" or "1.4 KiB".
+function formatBytes(n: number): string {
+  const units = ['B', 'KiB', 'MiB', 'GiB'];
+  let value = n;
+  let unit = 0;
+  while (value >= 1024 && unit < units.length - 1) {
+    value /= 1024;
+    unit++;
+  }
+  return unit === 0 ? `${n} B` : `${value.toFixed(1)} ${units[unit]}`;
+}
+
 interface JsonDetailModalProps {
   isOpen: boolean;
   onClose: () => void;
@@ -21,7 +34,7 @@ export default function JsonDetailModal({ isOpen, onClose, logId }: JsonDetailMo
         setError(null);
         setLogDetail(null); 
         try {
-          const response = await fetch(`/api/logs/${logId}`);
+          const response = await fetch(apiUrl(`/api/logs/${logId}`));
           if (!response.ok) {
             const errorData = await response.json().catch(() => ({ message: response.statusText }));
             throw new Error(errorData.error || `API Error: ${response.status}`);
@@ -79,6 +92,19 @@ export default function JsonDetailModal({ isOpen, onClose, logId }: JsonDetailMo
           {error && <p className="text-red-600 text-center py-4">Error loading details: {error.message}</p>}
           {logDetail && !isLoading && !error && (
             <div className="bg-gray-50 p-3 sm:p-4 rounded text-xs sm:text-sm">
+              {[logDetail.request_bytes, logDetail.response_bytes, logDetail.duration_ms].some(v => v !== undefined && v !== null) && (
+                <dl className="flex flex-wrap gap-x-6 gap-y-1 mb-3 text-gray-700">
+                  {logDetail.request_bytes !== undefined && logDetail.request_bytes !== null && (
+                    <div><dt className="inline text-gray-500">Request size: </dt><dd className="inline font-medium">{formatBytes(logDetail.request_bytes)}</dd></div>
+                  )}
+                  {logDetail.response_bytes !== undefined && logDetail.response_bytes !== null && (
+                    <div><dt className="inline text-gray-500">Response size: </dt><dd className="inline font-medium">{formatBytes(logDetail.response_bytes)}</dd></div>
+                  )}
+                  {logDetail.duration_ms !== undefined && logDetail.duration_ms !== null && (
+                    <div><dt className="inline text-gray-500">Duration: </dt><dd className="inline font-medium">{logDetail.duration_ms}ms</dd></div>
+                  )}
+                </dl>
+              )}
               <ReactJson
                 src={logDetail as object}
                 name={null}
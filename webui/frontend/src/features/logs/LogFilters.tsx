@@ -1,4 +1,4 @@
-import React from 'react';
+import React, { useState } from 'react';
 import { type ColumnVisibilityState } from './types';
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
@@ -9,6 +9,7 @@ import { Checkbox } from '@/components/ui/checkbox';
 interface LogFiltersProps {
   statusFilter: string;
   onStatusFilterChange: (value: string) => void;
+  statusOptions: string[];
   toolNameFilter: string;
   onToolNameFilterChange: (value: string) => void;
   mcpMethodFilter: string;
@@ -22,10 +23,18 @@ interface LogFiltersProps {
 }
 
 const SELECT_ALL_STATUSES_VALUE = "__all__"; // Special value for the "All Statuses" option
+const SELECT_CUSTOM_STATUS_VALUE = "__custom__"; // Special value for the free-text "Custom..." option
+
+// toStatusLabel turns a status value such as "transport_error" into "Transport error".
+const toStatusLabel = (status: string) => {
+  const spaced = status.replace(/_/g, ' ');
+  return spaced.charAt(0).toUpperCase() + spaced.slice(1);
+};
 
 export default function LogFilters({
   statusFilter,
   onStatusFilterChange,
+  statusOptions,
   toolNameFilter,
   onToolNameFilterChange,
   mcpMethodFilter,
@@ -36,6 +45,8 @@ export default function LogFilters({
   onColumnVisibilityChange,
   availableColumns,
 }: LogFiltersProps) {
+  const [isCustomStatus, setIsCustomStatus] = useState(false);
+  const showCustomStatus = isCustomStatus || (statusFilter !== "" && !statusOptions.includes(statusFilter));
 
   // const handleKeyPress = (event: React.KeyboardEvent<HTMLInputElement>) => {
   //   if (event.key === 'Enter') {
@@ -52,8 +63,13 @@ export default function LogFilters({
           <div className="space-y-1.5">
             <Label htmlFor="status-filter">Status</Label>
             <Select 
-              value={statusFilter === "" ? SELECT_ALL_STATUSES_VALUE : statusFilter} 
+              value={showCustomStatus ? SELECT_CUSTOM_STATUS_VALUE : statusFilter === "" ? SELECT_ALL_STATUSES_VALUE : statusFilter} 
               onValueChange={(value) => {
+                if (value === SELECT_CUSTOM_STATUS_VALUE) {
+                  setIsCustomStatus(true);
+                  return;
+                }
+                setIsCustomStatus(false);
                 if (value === SELECT_ALL_STATUSES_VALUE) {
                   onStatusFilterChange("");
                 } else {
@@ -66,10 +82,21 @@ export default function LogFilters({
               </SelectTrigger>
               <SelectContent>
                 <SelectItem value={SELECT_ALL_STATUSES_VALUE}>All Statuses</SelectItem>
-                <SelectItem value="success">Success</SelectItem>
-                <SelectItem value="failure">Failure</SelectItem>
+                {statusOptions.map((status) => (
+                  <SelectItem key={status} value={status}>{toStatusLabel(status)}</SelectItem>
+                ))}
+                <SelectItem value={SELECT_CUSTOM_STATUS_VALUE}>Custom...</SelectItem>
               </SelectContent>
             </Select>
+            {showCustomStatus && (
+              <Input
+                type="text"
+                id="custom-status-filter"
+                placeholder="Exact status, e.g. rate_limited"
+                value={statusFilter}
+                onChange={(e: React.ChangeEvent<HTMLInputElement>) => onStatusFilterChange(e.target.value.trim())}
+              />
+            )}
           </div>
 
           {/* Tool Name Filter */}
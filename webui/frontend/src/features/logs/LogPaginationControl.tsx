@@ -2,6 +2,8 @@
 interface LogPaginationControlsProps {
   currentPage: number;
   totalPages: number;
+  hasNext: boolean;
+  hasPrev: boolean;
   isLoading: boolean;
   onPageChange: (page: number) => void;
   // prefetchPage?: (page: number) => void; // Optional for prefetching
@@ -10,19 +12,21 @@ interface LogPaginationControlsProps {
 export default function LogPaginationControls({
   currentPage,
   totalPages,
+  hasNext,
+  hasPrev,
   isLoading,
   onPageChange,
   // prefetchPage
 }: LogPaginationControlsProps) {
   
   const handlePrevious = () => {
-    if (currentPage > 1) {
+    if (hasPrev) {
       onPageChange(currentPage - 1);
     }
   };
 
   const handleNext = () => {
-    if (currentPage < totalPages) {
+    if (hasNext) {
       onPageChange(currentPage + 1);
     }
   };
@@ -36,14 +40,14 @@ export default function LogPaginationControls({
       <div className="flex-1 flex justify-between sm:hidden">
         <button
           onClick={handlePrevious}
-          disabled={isLoading || currentPage <= 1}
+          disabled={isLoading || !hasPrev}
           className="relative inline-flex items-center px-4 py-2 border border-gray-300 text-sm font-medium rounded-md text-gray-700 bg-white hover:bg-gray-50 disabled:opacity-50"
         >
           Previous
         </button>
         <button
           onClick={handleNext}
-          disabled={isLoading || currentPage >= totalPages}
+          disabled={isLoading || !hasNext}
           className="ml-3 relative inline-flex items-center px-4 py-2 border border-gray-300 text-sm font-medium rounded-md text-gray-700 bg-white hover:bg-gray-50 disabled:opacity-50"
         >
           Next
@@ -59,7 +63,7 @@ export default function LogPaginationControls({
           <nav className="relative z-0 inline-flex rounded-md shadow-sm -space-x-px" aria-label="Pagination">
             <button
               onClick={handlePrevious}
-              disabled={isLoading || currentPage <= 1}
+              disabled={isLoading || !hasPrev}
               className="relative inline-flex items-center px-2 py-2 rounded-l-md border border-gray-300 bg-white text-sm font-medium text-gray-500 hover:bg-gray-50 disabled:opacity-50"
             >
               <span className="sr-only">Previous</span>
@@ -75,7 +79,7 @@ export default function LogPaginationControls({
             </span>
             <button
               onClick={handleNext}
-              disabled={isLoading || currentPage >= totalPages}
+              disabled={isLoading || !hasNext}
               className="relative inline-flex items-center px-2 py-2 rounded-r-md border border-gray-300 bg-white text-sm font-medium text-gray-500 hover:bg-gray-50 disabled:opacity-50"
             >
               <span className="sr-only">Next</span>
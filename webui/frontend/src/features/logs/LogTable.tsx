@@ -27,21 +27,28 @@ export default function LogTable(props: LogTableContainerProps) {
     logs,
     currentPage,
     totalPages,
+    hasNext,
+    hasPrev,
     limit,
     isLoading,
     error,
     statusFilter,
+    statusOptions,
     toolNameFilter,
     mcpMethodFilter,
     globalSearchTerm,
     columnVisibility,
+    sortBy,
+    sortOrder,
     handlePageChange,
     handleLimitChange, // Added from hook
+    deleteLog,
     setStatusFilter,
     setToolNameFilter,
     setMcpMethodFilter,
     setGlobalSearchTerm,
     setColumnVisibility,
+    toggleSort,
     // applyFilters, // Can be used if LogFilters has an explicit apply button
     // refreshData // Can be used for a manual refresh button
   } = useLogTableState(props);
@@ -76,6 +83,7 @@ export default function LogTable(props: LogTableContainerProps) {
       <LogFilters
         statusFilter={statusFilter}
         onStatusFilterChange={setStatusFilter}
+        statusOptions={statusOptions}
         toolNameFilter={toolNameFilter}
         onToolNameFilterChange={setToolNameFilter}
         mcpMethodFilter={mcpMethodFilter}
@@ -92,7 +100,11 @@ export default function LogTable(props: LogTableContainerProps) {
         isLoading={isLoading}
         // limit={limit} // Pass limit if LogTableContent uses it
         columnVisibility={columnVisibility}
+        sortBy={sortBy}
+        sortOrder={sortOrder}
+        onSort={toggleSort}
         onOpenModal={openModal}
+        onDeleteLog={deleteLog}
         error={error} // Pass error to table content as well for specific rendering there
       />
 
@@ -100,6 +112,8 @@ export default function LogTable(props: LogTableContainerProps) {
         <LogPaginationControls
           currentPage={currentPage}
           totalPages={totalPages}
+          hasNext={hasNext}
+          hasPrev={hasPrev}
           isLoading={isLoading}
           onPageChange={handlePageChange}
         />
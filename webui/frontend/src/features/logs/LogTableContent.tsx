@@ -1,4 +1,4 @@
-import { type LogEntry, type ColumnVisibilityState } from './types';
+import { type LogEntry, type ColumnVisibilityState, type SortOrder, ERROR_STATUSES, WARNING_STATUSES } from './types';
 
 // Helper to safely escape HTML content (can be moved to a utils file)
 function escapeHtml(unsafe: unknown): string {
@@ -16,7 +16,11 @@ interface LogTableContentProps {
   logs: LogEntry[];
   isLoading: boolean;
   columnVisibility: ColumnVisibilityState;
+  sortBy?: string;
+  sortOrder?: SortOrder;
+  onSort?: (column: string) => void; // Called with the column whose header was clicked
   onOpenModal: (logId: string) => void;
+  onDeleteLog: (logId: string) => void;
   error?: Error | null; // Optional error display
 }
 
@@ -24,7 +28,11 @@ export default function LogTableContent({
   logs,
   isLoading,
   columnVisibility,
+  sortBy,
+  sortOrder,
+  onSort,
   onOpenModal,
+  onDeleteLog,
   error,
 }: LogTableContentProps) {
 
@@ -36,20 +44,34 @@ export default function LogTableContent({
     }
   };
 
+  // Header of a column the backend can sort by; clicking it sorts by that column.
+  const sortableHeader = (key: string, column: string, label: string) => {
+    const active = sortBy === column;
+    return (
+      <th key={key} className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider" aria-sort={active ? (sortOrder === 'asc' ? 'ascending' : 'descending') : undefined}>
+        {onSort ? (
+          <button type="button" className="uppercase tracking-wider hover:text-gray-700" onClick={() => onSort(column)}>
+            {label}{active ? (sortOrder === 'asc' ? ' ▲' : ' ▼') : ''}
+          </button>
+        ) : label}
+      </th>
+    );
+  };
+
   const renderTableHeaders = () => {
     const headers = [];
-    if (columnVisibility.timestamp) headers.push(<th key="timestamp" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Timestamp</th>);
+    if (columnVisibility.timestamp) headers.push(sortableHeader("timestamp", "timestamp", "Timestamp"));
     
     if (columnVisibility.tool_name) {
-       headers.push(<th key="tool_name" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Tool</th>);
+       headers.push(sortableHeader("tool_name", "tool_name", "Tool"));
     }
     if (columnVisibility.mcp_method) {
-      headers.push(<th key="mcp_method" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Method</th>);
+      headers.push(sortableHeader("mcp_method", "mcp_method", "Method"));
     }
 
     if (columnVisibility.target_server_alias) headers.push(<th key="target_server_alias" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">MCP Host</th>);
-    if (columnVisibility.status) headers.push(<th key="status" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Status</th>);
-    if (columnVisibility.duration_ms) headers.push(<th key="duration" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Duration (ms)</th>);
+    if (columnVisibility.status) headers.push(sortableHeader("status", "status", "Status"));
+    if (columnVisibility.duration_ms) headers.push(sortableHeader("duration", "duration_ms", "Duration (ms)"));
     if (columnVisibility.id) headers.push(<th key="log_id" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Log ID</th>);
     headers.push(<th key="details" className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Details</th>);
     return <tr>{headers}</tr>;
@@ -88,10 +110,13 @@ export default function LogTableContent({
 
     return logs.map((log) => {
       let statusClass = 'text-gray-700';
-      if (log.status?.toLowerCase() === 'success') {
+      const status = log.status?.toLowerCase() ?? '';
+      if (status === 'success') {
         statusClass = 'text-green-600 font-semibold';
-      } else if (log.status?.toLowerCase() === 'failure') {
+      } else if (ERROR_STATUSES.includes(status)) {
         statusClass = 'text-red-600 font-semibold';
+      } else if (WARNING_STATUSES.includes(status)) {
+        statusClass = 'text-amber-600 font-semibold';
       }
 
       const cells = [];
@@ -105,8 +130,22 @@ export default function LogTableContent({
       }
 
       if (columnVisibility.target_server_alias) cells.push(<td key="target_server_alias" className="px-6 py-4 whitespace-nowrap text-sm text-gray-600">{escapeHtml(log.target_server_alias)}</td>);
-      if (columnVisibility.status) cells.push(<td key="status" className={`px-6 py-4 whitespace-nowrap text-sm ${statusClass}`}>{escapeHtml(log.status)}</td>);
-      if (columnVisibility.duration_ms) cells.push(<td key="duration" className="px-6 py-4 whitespace-nowrap text-sm text-gray-600 text-right">{log.duration_ms !== undefined && log.duration_ms !== null ? `${log.duration_ms}ms` : '-'}</td>);
+      if (columnVisibility.status) cells.push(
+        <td key="status" className={`px-6 py-4 whitespace-nowrap text-sm ${statusClass}`}>
+          {escapeHtml(log.status)}
+          {log.pending_upload && (
+            <span className="ml-2 px-2 py-0.5 rounded-full bg-yellow-100 text-yellow-800 text-xs font-medium" title="Upload to the Ithena Platform failed; it will be retried on a later run">pending upload</span>
+          )}
+        </td>
+      );
+      if (columnVisibility.duration_ms) cells.push(
+        <td key="duration" className="px-6 py-4 whitespace-nowrap text-sm text-gray-600 text-right">
+          {log.duration_ms !== undefined && log.duration_ms !== null ? `${log.duration_ms}ms` : '-'}
+          {log.first_byte_ms !== undefined && log.first_byte_ms !== null && (
+            <div className="text-xs text-gray-400" title="Time until the server's first progress update or response byte; the rest of the duration is transfer time">first byte {log.first_byte_ms}ms</div>
+          )}
+        </td>
+      );
       if (columnVisibility.id) cells.push(<td key="log_id" className="px-6 py-4 whitespace-nowrap text-sm text-gray-600">{escapeHtml(log.id)}</td>);
       
       cells.push(
@@ -117,6 +156,14 @@ export default function LogTableContent({
           >
             View
           </button>
+          <button
+            className="ml-2 bg-white hover:bg-red-50 text-red-600 border border-red-200 py-1 px-3 rounded-md text-xs font-medium transition duration-150 ease-in-out"
+            onClick={() => {
+              if (window.confirm('Delete this log entry? This cannot be undone.')) onDeleteLog(log.id);
+            }}
+          >
+            Delete
+          </button>
         </td>
       );
       return <tr key={log.id}>{cells}</tr>;
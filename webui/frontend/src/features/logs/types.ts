@@ -9,23 +9,47 @@ export interface LogEntry {
   sequence?: number; 
   step_type?: string; 
   tool_name?: string | null; 
+  tool_args?: any; 
   mcp_method?: string | null; 
   mcp_host?: string | null; 
   target_server_alias?: string | null; 
   status: string; 
   duration_ms?: number | null; 
+  first_byte_ms?: number | null; // Time until the first progress notification or response byte
+  request_bytes?: number | null; // Raw size of the request message on the wire
+  response_bytes?: number | null; // Raw size of the response message on the wire
   request_payload?: any; 
   response_payload?: any; 
   error_message?: string | null; 
+  pending_upload?: boolean; 
+}
+
+// Built-in status values; the backend may also report custom ones.
+export const KNOWN_STATUSES = ['success', 'failure', 'rpc_error', 'timeout', 'cancelled', 'transport_error', 'restarted', 'binary_stream'];
+
+// Statuses shown as failures (red) and as interrupted calls (amber) in the table.
+export const ERROR_STATUSES = ['failure', 'rpc_error', 'transport_error'];
+export const WARNING_STATUSES = ['timeout', 'cancelled', 'restarted', 'binary_stream'];
+
+export interface StatusesApiResponse {
+  statuses: string[];
 }
 
 export interface LogsApiResponse {
   logs: LogEntry[];
   total_count: number;
-  page: number;
+  page: number; // The page returned, clamped to the last page if a later one was requested
   limit: number;
+  total_pages: number;
+  has_next: boolean;
+  has_prev: boolean;
 }
 
+// Sort order of the log table; the backend defaults to timestamp, newest first.
+export type SortOrder = 'asc' | 'desc';
+export const DEFAULT_SORT_BY = 'timestamp';
+export const DEFAULT_SORT_ORDER: SortOrder = 'desc';
+
 export interface FetchLogApiParams {
   page?: number;
   limit?: number;
@@ -33,6 +57,8 @@ export interface FetchLogApiParams {
   tool_name?: string;
   mcp_method?: string;
   search?: string; 
+  sort?: string;
+  order?: SortOrder;
 }
 
 export interface ColumnVisibilityState {
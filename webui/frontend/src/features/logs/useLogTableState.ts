@@ -1,11 +1,17 @@
 /* eslint-disable @typescript-eslint/no-explicit-any */
 import { useState, useCallback, useEffect } from 'react';
+import { apiUrl } from '@/lib/api';
 import {
   type LogEntry,
   type LogsApiResponse,
   type FetchLogApiParams,
   type ColumnVisibilityState,
+  type StatusesApiResponse,
+  type SortOrder,
   DEFAULT_COLUMN_VISIBILITY,
+  DEFAULT_SORT_BY,
+  DEFAULT_SORT_ORDER,
+  KNOWN_STATUSES,
 } from './types';
 
 const DEFAULT_LIMIT = 20; 
@@ -31,10 +37,21 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
   const [mcpMethodFilter, setMcpMethodFilter] = useState<string>(props.initialMcpMethodFilter || '');
   const [globalSearchTerm, setGlobalSearchTerm] = useState<string>(props.initialGlobalSearchTerm || '');
 
+  // Sort States
+  const [sortBy, setSortBy] = useState<string>(DEFAULT_SORT_BY);
+  const [sortOrder, setSortOrder] = useState<SortOrder>(DEFAULT_SORT_ORDER);
+  const isDefaultSort = sortBy === DEFAULT_SORT_BY && sortOrder === DEFAULT_SORT_ORDER;
+
+  // Status values offered by the status filter (built-in plus any custom ones in the store)
+  const [statusOptions, setStatusOptions] = useState<string[]>(KNOWN_STATUSES);
+
   // Pagination States
   const [currentPage, setCurrentPage] = useState(props.initialCurrentPage || 1);
   const [limit, setLimit] = useState(props.initialLimit || DEFAULT_LIMIT);
-  const totalPages = Math.ceil(totalCount / limit);
+  // Page math comes from the backend; live updates below only ever add pages.
+  const [totalPages, setTotalPages] = useState(0);
+  const [hasNext, setHasNext] = useState(false);
+  const [hasPrev, setHasPrev] = useState(false);
 
   // Column Visibility State
   const [columnVisibility, setColumnVisibility] = useState<ColumnVisibilityState>({
@@ -54,9 +71,11 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
     if (fetchParams.tool_name) queryParams.append('tool_name', fetchParams.tool_name);
     if (fetchParams.mcp_method) queryParams.append('mcp_method', fetchParams.mcp_method);
     if (fetchParams.search) queryParams.append('search', fetchParams.search);
+    if (fetchParams.sort) queryParams.append('sort', fetchParams.sort);
+    if (fetchParams.order) queryParams.append('order', fetchParams.order);
 
     try {
-      const response = await fetch(`/api/logs?${queryParams.toString()}`);
+      const response = await fetch(apiUrl(`/api/logs?${queryParams.toString()}`));
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({ message: response.statusText }));
         throw new Error(errorData.error || `API Error: ${response.status}`);
@@ -65,6 +84,9 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
       setLogs(data.logs || []);
       console.log("Fetched logs for table:", data.logs); 
       setTotalCount(data.total_count || 0);
+      setTotalPages(data.total_pages || 0);
+      setHasNext(Boolean(data.has_next));
+      setHasPrev(Boolean(data.has_prev));
       setCurrentPage(data.page || 1); 
       if (data.limit) setLimit(data.limit);
 
@@ -73,12 +95,25 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
       setError(err);
       setLogs([]); 
       setTotalCount(0);
+      setTotalPages(0);
+      setHasNext(false);
+      setHasPrev(false);
     } finally {
       setIsLoading(false);
     }
   }, []);
 
 
+  // Effect to load the status filter options once
+  useEffect(() => {
+    fetch(apiUrl('/api/statuses'))
+      .then((response) => (response.ok ? response.json() : Promise.reject(new Error(`API Error: ${response.status}`))))
+      .then((data: StatusesApiResponse) => {
+        if (data.statuses?.length) setStatusOptions(data.statuses);
+      })
+      .catch((err) => console.error("Failed to fetch statuses:", err));
+  }, []);
+
   // Effect to fetch data when page, limit, or filters change
   useEffect(() => {
     fetchData({
@@ -88,12 +123,63 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
       tool_name: toolNameFilter,
       mcp_method: mcpMethodFilter,
       search: globalSearchTerm,
+      sort: sortBy,
+      order: sortOrder,
+    });
+  }, [currentPage, limit, statusFilter, toolNameFilter, mcpMethodFilter, globalSearchTerm, sortBy, sortOrder, fetchData]);
+
+  // Effect to receive newly stored logs live. New logs are prepended on the first page;
+  // on later pages, or when sorted other than newest first, only the total changes, so the
+  // rows being read don't shift.
+  useEffect(() => {
+    const queryParams = new URLSearchParams();
+    if (statusFilter) queryParams.append('status', statusFilter);
+    if (toolNameFilter) queryParams.append('tool_name', toolNameFilter);
+    if (mcpMethodFilter) queryParams.append('mcp_method', mcpMethodFilter);
+    if (globalSearchTerm) queryParams.append('search', globalSearchTerm);
+
+    const source = new EventSource(apiUrl(`/api/logs/stream?${queryParams.toString()}`));
+    source.addEventListener('log', (event) => {
+      try {
+        const entry: LogEntry = JSON.parse((event as MessageEvent).data);
+        setTotalCount((count) => count + 1);
+        if (currentPage === 1 && isDefaultSort) {
+          setLogs((current) => [entry, ...current.filter((log) => log.id !== entry.id)].slice(0, limit));
+        }
+      } catch (err) {
+        console.error("Failed to parse streamed log:", err);
+      }
     });
-  }, [currentPage, limit, statusFilter, toolNameFilter, mcpMethodFilter, globalSearchTerm, fetchData]);
+    return () => source.close();
+  }, [currentPage, limit, statusFilter, toolNameFilter, mcpMethodFilter, globalSearchTerm, isDefaultSort]);
+
+  // Logs streamed in since the last fetch may need another page.
+  useEffect(() => {
+    const pages = Math.ceil(totalCount / limit);
+    if (pages > totalPages) {
+      setTotalPages(pages);
+      setHasNext(currentPage < pages);
+    }
+  }, [totalCount, limit, totalPages, currentPage]);
+
+  // Deletes a single log and drops it from the current page without refetching.
+  const deleteLog = useCallback(async (logId: string) => {
+    try {
+      const response = await fetch(apiUrl(`/api/logs/${encodeURIComponent(logId)}`), { method: 'DELETE' });
+      if (!response.ok && response.status !== 404) {
+        throw new Error(`API Error: ${response.status}`);
+      }
+      setLogs((current) => current.filter((log) => log.id !== logId));
+      if (response.ok) setTotalCount((count) => Math.max(0, count - 1));
+    } catch (err: any) {
+      console.error("Failed to delete log:", err);
+      setError(err);
+    }
+  }, []);
 
   // Handlers
   const handlePageChange = (newPage: number) => {
-    if (newPage > 0 && newPage <= totalPages) {
+    if ((newPage < currentPage && hasPrev) || (newPage > currentPage && hasNext)) {
       setCurrentPage(newPage);
     }
   };
@@ -103,6 +189,17 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
     setCurrentPage(1); // Reset to page 1 when limit changes
   };
   
+  // Sorts by column, newest or largest first; choosing the current column again flips the order.
+  const toggleSort = (column: string) => {
+    if (column === sortBy) {
+      setSortOrder(sortOrder === 'desc' ? 'asc' : 'desc');
+    } else {
+      setSortBy(column);
+      setSortOrder('desc');
+    }
+    setCurrentPage(1);
+  };
+
   const applyFilters = (filters: {
     status?: string,
     toolName?: string,
@@ -124,25 +221,32 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
     totalCount,
     currentPage,
     totalPages,
+    hasNext,
+    hasPrev,
     limit,
     isLoading,
     error,
     
     statusFilter,
+    statusOptions,
     toolNameFilter,
     mcpMethodFilter,
     globalSearchTerm,
     columnVisibility,
+    sortBy,
+    sortOrder,
 
     // Handlers/Setters
     handlePageChange,
     handleLimitChange,
+    deleteLog,
     // Individual filter setters can be exposed if needed, or use applyFilters
     setStatusFilter: (status: string) => { setStatusFilter(status); setCurrentPage(1); },
     setToolNameFilter: (name: string) => { setToolNameFilter(name); setCurrentPage(1); },
     setMcpMethodFilter: (method: string) => { setMcpMethodFilter(method); setCurrentPage(1); },
     setGlobalSearchTerm: (term: string) => { setGlobalSearchTerm(term); setCurrentPage(1); },
     applyFilters, // More comprehensive filter update
+    toggleSort,
     setColumnVisibility,
     refreshData: () => fetchData({ // Exposed refresh function
         page: currentPage,
@@ -151,6 +255,8 @@ export function useLogTableState(props: UseLogTableStateProps = {}) {
         tool_name: toolNameFilter,
         mcp_method: mcpMethodFilter,
         search: globalSearchTerm,
+        sort: sortBy,
+        order: sortOrder,
     }),
   };
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+// Token required by `logs show --auth-token`/`--generate-token`. The printed viewer URL
+// carries it as ?token=...; it is kept for the tab's session so reloads keep working.
+const TOKEN_STORAGE_KEY = 'ithena-ui-token';
+
+function uiToken(): string | null {
+  const fromUrl = new URLSearchParams(window.location.search).get('token');
+  if (fromUrl) {
+    sessionStorage.setItem(TOKEN_STORAGE_KEY, fromUrl);
+    return fromUrl;
+  }
+  return sessionStorage.getItem(TOKEN_STORAGE_KEY);
+}
+
+// Adds the web UI token, if any, to an /api URL. A query param rather than a header, so the
+// same URL works for fetch and EventSource.
+export function apiUrl(path: string): string {
+  const token = uiToken();
+  if (!token) return path;
+  return `${path}${path.includes('?') ? '&' : '?'}token=${encodeURIComponent(token)}`;
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -21,6 +24,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/ithena-one/Ithena/packages/cli/auth"
 	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/types"
 	"github.com/zalando/go-keyring"
 )
 
@@ -80,13 +84,15 @@ func authStatusHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // StartServer initializes and starts the local HTTP server for viewing logs.
-func StartServer(port int, version string) { // Added version parameter
+// With printReady, it skips opening a browser and instead writes "READY <url>" to stdout once
+// the listener is bound, so a parent process can wait for that line. Port 0 picks a free port.
+func StartServer(host string, port int, authToken string, version string, printReady bool) { // Added version parameter
 	cliVersion = version // Store the version
 	if verbose {
-		log.Printf("WebUI: Attempting to start server on port %d, CLI version: %s...", port, cliVersion)
+		log.Printf("WebUI: Attempting to start server on %s port %d, CLI version: %s...", host, port, cliVersion)
 	}
 
-	address := fmt.Sprintf("localhost:%d", port)
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 
 	// Create a sub-filesystem rooted at "frontend/dist" within distFS
 	contentFS, err := fs.Sub(distFS, "frontend/dist")
@@ -98,8 +104,15 @@ func StartServer(port int, version string) { // Added version parameter
 
 	// API routes - These should be defined first
 	apiRouter := router.PathPrefix("/api").Subrouter()
+	if authToken != "" {
+		apiRouter.Use(requireToken(authToken))
+	}
 	apiRouter.HandleFunc("/logs", logsHandler).Methods("GET")
+	apiRouter.HandleFunc("/logs/stream", logStreamHandler).Methods("GET") // Before /logs/{id}, which would match "stream"
 	apiRouter.HandleFunc("/logs/{id}", logDetailHandler).Methods("GET")
+	apiRouter.HandleFunc("/logs/{id}", logDeleteHandler).Methods("DELETE")
+	apiRouter.HandleFunc("/statuses", statusesHandler).Methods("GET")
+	apiRouter.HandleFunc("/stats", statsHandler).Methods("GET")
 	apiRouter.HandleFunc("/auth/status", authStatusHandler).Methods("GET")
 	apiRouter.HandleFunc("/version", versionHandler).Methods("GET") // Added version endpoint
 
@@ -131,21 +144,46 @@ func StartServer(port int, version string) { // Added version parameter
 	// It uses contentFS, and serveIndexHTML will attempt contentFS.Open("index.html")
 	router.PathPrefix("/").Handler(spaHandler(contentFS))
 
+	// Request contexts derive from baseCtx, which is cancelled on shutdown so that open log
+	// streams end instead of holding up srv.Shutdown.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	srv := &http.Server{
-		Addr:    address,
-		Handler: router,
+		Addr:        address,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
+	srv.RegisterOnShutdown(cancelRequests)
 
 	// Channel to listen for OS signals
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
 
+	// Bind before announcing the URL so it is reachable once printed.
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("WebUI Fatal: Could not listen on %s: %v\n", address, err)
+	}
+	if !isLoopbackHost(host) && authToken == "" {
+		log.Printf("WebUI Warning: Listening on %s, not just this machine. Anyone who can reach it on the network can read your logs, which may contain tool arguments and secrets, and delete them. Use --auth-token or --generate-token, and only do this on trusted networks.", listener.Addr())
+	} else if !isLoopbackHost(host) {
+		log.Printf("WebUI Warning: Listening on %s, not just this machine. API requests require the token, but it travels unencrypted over plain HTTP; only do this on trusted networks.", listener.Addr())
+	}
+	viewerURL := fmt.Sprintf("http://%s", net.JoinHostPort(browserHost(host), strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)))
+	if authToken != "" {
+		viewerURL += "/?" + tokenQueryParam + "=" + url.QueryEscape(authToken)
+	}
+	if printReady {
+		fmt.Printf("READY %s\n", viewerURL)
+	} else {
+		log.Printf("WebUI: Starting server. Please open your browser to %s", viewerURL)
+		openBrowser(viewerURL)
+	}
+
 	// Goroutine to start the server
 	go func() {
-		log.Printf("WebUI: Starting server. Please open your browser to http://%s", address)
-		openBrowser(fmt.Sprintf("http://%s", address))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("WebUI Fatal: Could not listen on %s: %v\n", address, err)
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("WebUI Fatal: Server on %s failed: %v\n", address, err)
 		}
 	}()
 
@@ -165,6 +203,27 @@ func StartServer(port int, version string) { // Added version parameter
 	log.Println("WebUI: Server exited gracefully")
 }
 
+// isLoopbackHost reports whether binding host only accepts connections from this machine.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// browserHost returns the host to put in the viewer URL for a server bound to host. A wildcard
+// address accepts local connections too, but can't be opened in a browser.
+func browserHost(host string) string {
+	if host == "" {
+		return "localhost"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		return "localhost"
+	}
+	return host
+}
+
 func versionHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	response := map[string]string{"version": cliVersion}
@@ -213,6 +272,40 @@ func serveIndexHTML(w http.ResponseWriter, r *http.Request, contentFS fs.FS) { /
 	}
 }
 
+// filtersFromQuery reads the log filters shared by the logs endpoints from query params.
+// status, tool_name and mcp_method may be repeated to match any of several values; sort and
+// order only affect /api/logs.
+func filtersFromQuery(query url.Values) (localstore.LogQueryFilters, error) {
+	filters := localstore.LogQueryFilters{
+		Statuses:   query["status"],
+		ToolNames:  query["tool_name"],
+		McpMethods: query["mcp_method"],
+		SearchTerm: query.Get("search"),
+		StartTime:  query.Get("start"),
+		EndTime:    query.Get("end"),
+		SortBy:     query.Get("sort"),
+		SortOrder:  query.Get("order"),
+	}
+	for param, target := range map[string]*int64{"min_ms": &filters.MinDurationMs, "max_ms": &filters.MaxDurationMs} {
+		if value := query.Get(param); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return filters, fmt.Errorf("Invalid %s value '%s'", param, value)
+			}
+			*target = parsed
+		}
+	}
+	for param, values := range query {
+		if name, ok := strings.CutPrefix(param, "field."); ok && len(values) > 0 {
+			if filters.Fields == nil {
+				filters.Fields = make(map[string]string)
+			}
+			filters.Fields[name] = values[0]
+		}
+	}
+	return filters, localstore.ValidateFilters(filters)
+}
+
 func logsHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	pageStr := query.Get("page")
@@ -227,11 +320,10 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 		limit = 20 // Default limit
 	}
 
-	filters := localstore.LogQueryFilters{
-		Status:     query.Get("status"),
-		ToolName:   query.Get("tool_name"),
-		McpMethod:  query.Get("mcp_method"),
-		SearchTerm: query.Get("search"),
+	filters, err := filtersFromQuery(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	result, err := localstore.QueryLogs(filters, page, limit)
@@ -247,6 +339,53 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// statusesHandler lists the status values the UI should offer as filters: the built-in
+// statuses first, followed by any custom statuses found in the local store.
+func statusesHandler(w http.ResponseWriter, r *http.Request) {
+	stored, err := localstore.DistinctStatuses()
+	if err != nil {
+		log.Printf("WebUI API Error: Failed to query statuses: %v", err)
+		http.Error(w, "Failed to retrieve statuses", http.StatusInternalServerError)
+		return
+	}
+
+	statuses := append([]string{}, types.KnownStatuses...)
+	known := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		known[status] = true
+	}
+	for _, status := range stored {
+		if status != "" && !known[status] {
+			statuses = append(statuses, status)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]string{"statuses": statuses}); err != nil {
+		log.Printf("WebUI API Error: Failed to encode statuses response: %v", err)
+	}
+}
+
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	filters, err := filtersFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := localstore.GetStats(filters)
+	if err != nil {
+		log.Printf("WebUI API Error: Failed to compute stats: %v", err)
+		http.Error(w, "Failed to compute stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		log.Printf("WebUI API Error: Failed to encode stats response: %v", err)
+	}
+}
+
 func logDetailHandler(w http.ResponseWriter, r *http.Request) {
 	// Assumes path like /api/logs/some-uuid
 	// The trailing slash in HandleFunc registration means this matches /api/logs/*
@@ -274,6 +413,26 @@ func logDetailHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func logDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/logs/")
+	if id == "" {
+		http.Error(w, "Log ID is required in the path", http.StatusBadRequest)
+		return
+	}
+
+	err := localstore.DeleteLogByID(id)
+	if errors.Is(err, localstore.ErrLogNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("WebUI API Error: Failed to delete log by ID %s: %v", id, err)
+		http.Error(w, "Failed to delete log", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // openBrowser tries to open the URL in the default web browser.
 func openBrowser(url string) {
 	var err error
@@ -0,0 +1,99 @@
+package webui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+)
+
+const (
+	// streamPollInterval is how often the stream checks the store for new logs. Logs are
+	// usually written by a wrapper in another process, so the database is the only shared
+	// channel and it has to be polled.
+	streamPollInterval = 1 * time.Second
+	// streamHeartbeatInterval keeps idle connections from being closed by proxies.
+	streamHeartbeatInterval = 15 * time.Second
+	// streamBatchLimit caps how many logs are read per poll; the rest follow on the next one.
+	streamBatchLimit = 200
+)
+
+// logStreamHandler pushes newly stored logs to the client as Server-Sent Events. It accepts
+// the same filter params as /api/logs. Each event is named "log", carries one AuditRecord as
+// JSON and has an id, so a reconnecting EventSource resumes via Last-Event-ID without gaps.
+func logStreamHandler(w http.ResponseWriter, r *http.Request) {
+	filters, err := filtersFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	cursor, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	if err != nil {
+		// New subscription: only logs stored from now on.
+		cursor, err = localstore.LatestLogCursor()
+		if err != nil {
+			log.Printf("WebUI API Error: Failed to start log stream: %v", err)
+			http.Error(w, "Failed to start log stream", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return // Client disconnected or server shutting down
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-poll.C:
+			logs, next, err := localstore.LogsAfter(cursor, filters, streamBatchLimit)
+			if err != nil {
+				log.Printf("WebUI API Error: Failed to read new logs for stream: %v", err)
+				continue
+			}
+			for i, entry := range logs {
+				data, err := json.Marshal(entry)
+				if err != nil {
+					log.Printf("WebUI API Error: Failed to encode streamed log %s: %v", entry.ID, err)
+					continue
+				}
+				// Only the last event of a batch carries the resume cursor; earlier ones
+				// would make a reconnect skip the rest of the batch otherwise.
+				if i == len(logs)-1 {
+					fmt.Fprintf(w, "id: %d\n", next)
+				}
+				if _, err := fmt.Fprintf(w, "event: log\ndata: %s\n\n", data); err != nil {
+					return
+				}
+			}
+			if len(logs) > 0 {
+				flusher.Flush()
+			}
+			cursor = next
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package webui
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// tokenQueryParam carries the token where a header can't be set, as for EventSource and
+// the viewer URL opened in the browser.
+const tokenQueryParam = "token"
+
+// GenerateToken returns a random token for 'logs show --generate-token'.
+func GenerateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// requireToken rejects requests that present neither "Authorization: Bearer <token>" nor
+// ?token=<token>, with 401.
+func requireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			presented := r.URL.Query().Get(tokenQueryParam)
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				presented = strings.TrimSpace(bearer)
+			}
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="ithena-cli logs"`)
+				writeError(w, "Missing or invalid token; open the URL printed by 'ithena-cli logs show'", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
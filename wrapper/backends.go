@@ -0,0 +1,410 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+)
+
+// Backend is one of the stdio commands RunBackends runs behind a single wrapper.
+type Backend struct {
+	Alias   string // Server alias recorded with this backend's records; defaults to Command
+	Command string
+	Args    []string
+	Env     map[string]string // Resolved; applied over the wrapper's own environment
+	Methods []string          // Methods routed here: exact names, or prefixes when they end in "*"
+
+	MaskedEnvKeys []string // Env keys whose values the --dry-run plan hides, see Options.MaskedEnvKeys
+}
+
+// broadcastMethods are client requests every backend needs. Only the default backend's
+// response is passed on to the client; the others are recorded and dropped.
+var broadcastMethods = map[string]bool{"initialize": true}
+
+// RunBackends wraps several stdio commands as a single server for the client. Each client
+// message goes to the backend whose Methods match its method most specifically; requests no
+// backend matches go to the first one, the default. Notifications no backend matches, and the
+// requests in broadcastMethods, go to every backend. Each backend has its own correlator, so
+// its records carry its alias. The session ends when any backend exits: the others are
+// stopped, unless the client already closed stdin, and the wrapper exits with the status of
+// the first one that failed.
+func RunBackends(backends []Backend, observeUrl string, opts Options) {
+	if len(backends) == 0 {
+		logErrorAndExit("Invalid wrapper configuration: no backends", nil, nil, observeUrl, nil, nil)
+	}
+	for i := range backends {
+		if backends[i].Alias == "" {
+			backends[i].Alias = backends[i].Command
+		}
+	}
+	if dryRun {
+		printBackendsPlan(backends, observeUrl)
+		return
+	}
+	defaultAlias := backends[0].Alias
+	aliasPtr := &defaultAlias
+
+	if opts.Transport != "" && opts.Transport != TransportStdio {
+		logErrorAndExit(fmt.Sprintf("Several backends require transport '%s'", TransportStdio), aliasPtr, nil, observeUrl, nil, nil)
+	}
+	if opts.Restart.enabled() {
+		logErrorAndExit("Restarting is not supported with several backends", aliasPtr, nil, observeUrl, nil, nil)
+	}
+	if err := opts.Streaming.validate(); err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
+	}
+	forward, err := forwarderForFraming(opts.Framing)
+	if err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
+	}
+	captureMode, err := captureStderrMode(opts.CaptureStderr)
+	if err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
+	}
+	catchBrokenPipe()
+
+	timeout := requestTimeout(opts.RequestTimeout)
+	mux := &multiplexer{
+		framing:        opts.Framing,
+		serverRequests: make(map[string]serverRequest),
+		broadcasts:     make(map[string]int),
+	}
+	owners := make(map[string]string)
+	for _, backend := range backends {
+		for _, pattern := range backend.Methods {
+			if owner, ok := owners[pattern]; ok {
+				logErrorAndExit(fmt.Sprintf("Invalid wrapper configuration: method '%s' is routed to both '%s' and '%s'", pattern, owner, backend.Alias), aliasPtr, nil, observeUrl, nil, nil)
+			}
+			owners[pattern] = backend.Alias
+		}
+		alias := backend.Alias
+		b := &muxBackend{
+			Backend:    backend,
+			index:      len(mux.backends),
+			alias:      &alias,
+			mux:        mux,
+			input:      newBackendInput(false),
+			correlator: newRpcCorrelator(&alias, observeUrl, timeout),
+		}
+		b.correlator.streaming = opts.Streaming
+		b.correlator.notifications = opts.notificationsRecorded()
+		mux.backends = append(mux.backends, b)
+	}
+	if verbose { log.Printf("Wrapper: Starting %d backends (default: %s, ObserveURL: %s)", len(backends), defaultAlias, observeUrl) }
+
+	envMap, _ := backendEnvironment(backends[0].Env)
+	// The session metadata describes the default backend.
+	observability.SetSessionMetadata(buildSessionMetadata(backends[0].Command, envMap))
+	maxBytes := maxMessageBytes()
+
+	// Goroutine 1: Route ithena-cli stdin to the backends & Store Request Info with each
+	// backend the request went to. Not waited for, as in Run.
+	go func() {
+		defer func() {
+			if verbose { log.Println("Wrapper: Goroutine 1 (stdin router) closing backend stdin pipes.") }
+			mux.clientDone.Store(true)
+			for _, b := range mux.backends {
+				b.input.Close()
+			}
+		}()
+		readErr, writeErr := forward(os.Stdin, mux, maxBytes, "Client", func(lineBytes []byte, frameBytes int, firstByteAt time.Time) {
+			for _, b := range mux.lastRoute {
+				b.correlator.handleClientMessage(lineBytes, frameBytes, firstByteAt)
+			}
+		})
+		if writeErr != nil {
+			log.Printf("Error writing to backend stdin: %v", writeErr)
+		}
+		if readErr != nil {
+			log.Printf("Wrapper: Error reading from wrapper stdin: %v", readErr)
+		}
+		if verbose { log.Println("Wrapper: Goroutine 1 (stdin router) finished reading.") }
+	}()
+
+	type backendExit struct {
+		backend   *muxBackend
+		started   bool
+		err       error
+		stderrTee *stderrCapture
+	}
+	exits := make(chan backendExit, len(mux.backends))
+	grace := shutdownGracePeriod()
+	for _, b := range mux.backends {
+		_, finalEnv := backendEnvironment(b.Env)
+		cmd := exec.Command(b.Command, b.Args...)
+		cmd.Dir = opts.Dir
+		cmd.Env = finalEnv
+		b.signals = newSignalForwarder(grace)
+		stderrTee := newStderrCapture(captureMode, b.alias, observeUrl)
+		go func() {
+			started, err := runBackend(cmd, b.input, muxOutput{b}, forward, maxBytes, b.correlator, b.signals, stderrTee)
+			// Messages for a backend that is gone are dropped rather than blocking the others.
+			b.input.Close()
+			exits <- backendExit{backend: b, started: started, err: err, stderrTee: stderrTee}
+		}()
+	}
+
+	status := 0
+	stopped := make(map[*muxBackend]bool)
+	for remaining := len(mux.backends); remaining > 0; remaining-- {
+		exit := <-exits
+		b := exit.backend
+		b.signals.stop()
+		if remaining == len(mux.backends) && !mux.clientDone.Load() {
+			// The first backend to exit ends the session. After the client closed stdin, the
+			// others are exiting too and may still be answering, so they are left to finish.
+			for _, other := range mux.backends {
+				if other != b {
+					stopped[other] = true
+					other.signals.requestStop(fmt.Sprintf("Backend '%s' exited", b.Alias))
+				}
+			}
+		}
+		if exit.err == nil {
+			if verbose { log.Printf("Wrapper: Backend '%s' finished successfully (status 0).", b.Alias) }
+			continue
+		}
+
+		backendStatus := 1
+		var errMsg string
+		exitErr, isExit := exit.err.(*exec.ExitError)
+		switch {
+		case !exit.started:
+			errMsg = fmt.Sprintf("Failed to start command '%s' of backend '%s': %v", b.Command, b.Alias, exit.err)
+		case isExit:
+			backendStatus = exitStatus(exitErr)
+			errMsg = fmt.Sprintf("Backend '%s' (command '%s') exited with non-zero status %d", b.Alias, b.Command, backendStatus)
+		default:
+			errMsg = fmt.Sprintf("Error waiting for command '%s' of backend '%s': %v", b.Command, b.Alias, exit.err)
+		}
+		if b.signals.wasInterrupted() {
+			// Stopped on request: by a signal to the wrapper, a closed stdout, or because
+			// another backend exited first. Not a failure to record.
+			if verbose { log.Printf("Wrapper: Backend '%s' stopped (status %d).", b.Alias, backendStatus) }
+			if !stopped[b] && status == 0 {
+				status = backendStatus
+			}
+			continue
+		}
+		log.Printf("Wrapper Error: %s", errMsg)
+		observability.SendLog(exit.stderrTee.attachTo(observability.CreateAuditRecordForError(errMsg, b.alias, nil, nil)), observeUrl)
+		if status == 0 {
+			status = backendStatus
+		}
+	}
+
+	if verbose { log.Println("Wrapper: Shutting down observability and exiting with status", status) }
+	for _, b := range mux.backends {
+		b.correlator.stopSweeping()
+	}
+	observability.ShutdownObservability()
+	exitWithReport(status)
+}
+
+// muxBackend is a running backend of RunBackends.
+type muxBackend struct {
+	Backend
+	index      int // Position in multiplexer.backends
+	alias      *string
+	mux        *multiplexer
+	input      *backendInput
+	correlator *rpcCorrelator
+	signals    *signalForwarder
+
+	dropOnce sync.Once // Guards the warning about messages this backend could not receive
+}
+
+// muxMessage holds the fields of a JSON-RPC message that decide where it goes.
+type muxMessage struct {
+	ID     interface{} `json:"id"`
+	Method string      `json:"method"`
+}
+
+// serverRequest is a request a backend sent the client, under the ID it was given so the
+// IDs of different backends can't collide.
+type serverRequest struct {
+	backend *muxBackend
+	id      json.RawMessage // The ID the backend gave it
+}
+
+// multiplexer is the writer client messages are forwarded to by RunBackends. Each Write is
+// one message (or, for messages too large to inspect, a piece of one, which goes to the
+// default backend) and is passed on to the backends route picks.
+type multiplexer struct {
+	backends   []*muxBackend // The first is the default
+	framing    string
+	clientDone atomic.Bool // The client's stdin reached EOF
+
+	// lastRoute is where the message of the last Write went, for the inspect callback that
+	// follows it on the same goroutine.
+	lastRoute []*muxBackend
+
+	mu             sync.Mutex
+	serverRequests map[string]serverRequest // Rewritten ID of a request a backend sent the client -> the request
+	broadcasts     map[string]int           // ID of a broadcast request -> responses still to drop
+	stdout         sync.Mutex               // Serializes messages from the backends
+}
+
+func (m *multiplexer) Write(p []byte) (int, error) {
+	var frame []byte
+	m.lastRoute, frame = m.route(p)
+	for _, b := range m.lastRoute {
+		if _, err := b.input.Write(frame); err != nil {
+			b.dropOnce.Do(func() {
+				log.Printf("Wrapper Warning: Dropping messages for backend '%s', which can no longer receive them: %v", b.Alias, err)
+			})
+		}
+	}
+	return len(p), nil
+}
+
+// route returns the backends a client message goes to, and the frame to write them: the
+// message itself, or for a response to a request a backend sent the client, the response
+// under the ID that backend gave the request.
+func (m *multiplexer) route(frame []byte) ([]*muxBackend, []byte) {
+	var msg muxMessage
+	if err := json.Unmarshal(m.body(frame), &msg); err != nil {
+		return m.backends[:1], frame
+	}
+	if msg.Method == "" {
+		// A response to a request one of the backends sent the client.
+		if msg.ID != nil {
+			key := idToString(msg.ID)
+			m.mu.Lock()
+			request, ok := m.serverRequests[key]
+			delete(m.serverRequests, key)
+			m.mu.Unlock()
+			if ok {
+				if restored, err := m.withID(frame, request.id); err == nil {
+					frame = restored
+				}
+				return []*muxBackend{request.backend}, frame
+			}
+		}
+		return m.backends[:1], frame
+	}
+	if b := m.backendFor(msg.Method); b != nil {
+		return []*muxBackend{b}, frame
+	}
+	if msg.ID == nil {
+		return m.backends, frame
+	}
+	if broadcastMethods[msg.Method] {
+		m.mu.Lock()
+		m.broadcasts[idToString(msg.ID)] = len(m.backends) - 1
+		m.mu.Unlock()
+		return m.backends, frame
+	}
+	return m.backends[:1], frame
+}
+
+// backendFor returns the backend method is routed to: an exact match, else the longest
+// matching prefix; nil if no backend lists it.
+func (m *multiplexer) backendFor(method string) *muxBackend {
+	var match *muxBackend
+	longest := -1
+	for _, b := range m.backends {
+		for _, pattern := range b.Methods {
+			prefix, isPrefix := strings.CutSuffix(pattern, "*")
+			if !isPrefix {
+				if method == pattern {
+					return b
+				}
+			} else if strings.HasPrefix(method, prefix) && len(prefix) > longest {
+				match, longest = b, len(prefix)
+			}
+		}
+	}
+	return match
+}
+
+// body returns the JSON of a written frame, skipping the header for content-length framing.
+func (m *multiplexer) body(frame []byte) []byte {
+	if m.framing != FramingContentLength {
+		return frame
+	}
+	end := -1
+	for _, separator := range []string{"\r\n\r\n", "\n\n"} {
+		if i := bytes.Index(frame, []byte(separator)); i >= 0 && (end < 0 || i+len(separator) < end) {
+			end = i + len(separator)
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+	return frame[end:]
+}
+
+// withID returns frame with the ID of its message replaced by id.
+func (m *multiplexer) withID(frame []byte, id json.RawMessage) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(m.body(frame), &fields); err != nil {
+		return nil, err
+	}
+	fields["id"] = id
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return frameMessage(m.framing, body), nil
+}
+
+// muxOutput is the writer a backend's messages are forwarded to. It gives the requests the
+// backend sends the client IDs prefixed with the backend's index, since backends number
+// their requests independently, and remembers them so their responses are routed back. It
+// drops the responses of backends other than the default to broadcast requests.
+type muxOutput struct {
+	backend *muxBackend
+}
+
+func (o muxOutput) Write(p []byte) (int, error) {
+	m := o.backend.mux
+	var msg muxMessage
+	if err := json.Unmarshal(m.body(p), &msg); err == nil && msg.ID != nil {
+		key := idToString(msg.ID)
+		if msg.Method != "" {
+			var raw struct {
+				ID json.RawMessage `json:"id"`
+			}
+			_ = json.Unmarshal(m.body(p), &raw)
+			id := fmt.Sprintf("%d:%s", o.backend.index, raw.ID)
+			rewritten, err := m.withID(p, json.RawMessage(strconv.Quote(id)))
+			if err == nil {
+				m.mu.Lock()
+				m.serverRequests[id] = serverRequest{backend: o.backend, id: raw.ID}
+				m.mu.Unlock()
+				m.stdout.Lock()
+				defer m.stdout.Unlock()
+				if _, err := os.Stdout.Write(rewritten); err != nil {
+					return 0, err
+				}
+				return len(p), nil
+			}
+		}
+		m.mu.Lock()
+		if remaining, ok := m.broadcasts[key]; ok && msg.Method == "" && o.backend != m.backends[0] {
+			if remaining <= 1 {
+				delete(m.broadcasts, key)
+			} else {
+				m.broadcasts[key] = remaining - 1
+			}
+			m.mu.Unlock()
+			return len(p), nil
+		}
+		m.mu.Unlock()
+	}
+
+	m.stdout.Lock()
+	defer m.stdout.Unlock()
+	return os.Stdout.Write(p)
+}
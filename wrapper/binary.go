@@ -0,0 +1,61 @@
+package wrapper
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"unicode/utf8"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// invalidUTF8SampleBytes is how many bytes around the first invalid one are hex-dumped in the note.
+const invalidUTF8SampleBytes = 16
+
+// checkBackendUTF8 warns, once per session, when a message from the backend is not valid
+// UTF-8, and records a binary_stream note. MCP over stdio is UTF-8 JSON, so this usually means
+// the command speaks a binary protocol or prints something other than MCP to stdout. The
+// message itself has already been forwarded unchanged.
+func (c *rpcCorrelator) checkBackendUTF8(message []byte) {
+	if utf8.Valid(message) {
+		return
+	}
+	c.invalidUTF8Once.Do(func() {
+		offset := invalidUTF8Offset(message)
+		start := offset - invalidUTF8SampleBytes/2
+		if start < 0 {
+			start = 0
+		}
+		end := start + invalidUTF8SampleBytes
+		if end > len(message) {
+			end = len(message)
+		}
+		sample := hex.EncodeToString(message[start:end])
+
+		log.Printf("Wrapper Warning: Backend wrote bytes that are not valid UTF-8 to stdout (offset %d of a %d-byte message, bytes %s). They are forwarded unchanged, but the command may not be an MCP server speaking JSON-RPC over stdio; check that it doesn't print anything else to stdout. Further occurrences are not reported.", offset, len(message), sample)
+
+		record := observability.CreateAuditRecordForError("Backend wrote invalid UTF-8 to stdout", c.alias, nil, nil)
+		record.Status = types.StatusBinaryStream
+		record.ErrorDetails = map[string]string{
+			"message":      "Backend wrote bytes that are not valid UTF-8 to stdout",
+			"offset":       fmt.Sprint(offset),
+			"message_size": fmt.Sprint(len(message)),
+			"sample_hex":   sample,
+		}
+		observability.SendLog(record, c.observeUrl)
+	})
+}
+
+// invalidUTF8Offset returns the offset of the first byte of message that starts an invalid
+// UTF-8 sequence, or len(message) if there is none.
+func invalidUTF8Offset(message []byte) int {
+	for i := 0; i < len(message); {
+		r, size := utf8.DecodeRune(message[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return i
+		}
+		i += size
+	}
+	return len(message)
+}
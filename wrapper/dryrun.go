@@ -0,0 +1,109 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dryRun is set by --dry-run.
+var dryRun bool
+
+// SetDryRun makes Run and RunBackends print what they would start instead of starting it.
+func SetDryRun(v bool) {
+	dryRun = v
+}
+
+// dryRunMask replaces the values the --dry-run plan doesn't show.
+const dryRunMask = "********"
+
+// printPlan prints what Run would do: the command with its arguments, or the SSE server it
+// would connect to, and the environment the command would get.
+func printPlan(command string, args []string, resolvedEnv map[string]string, alias string, observeUrl string, opts Options) {
+	fmt.Println("Dry run: nothing is started.")
+	if alias != "" {
+		fmt.Printf("Alias:       %s\n", alias)
+	}
+	fmt.Printf("Observe URL: %s\n", observeUrl)
+	if opts.Transport == TransportSSE {
+		fmt.Printf("Transport:   %s\n", TransportSSE)
+		fmt.Printf("URL:         %s\n", opts.URL)
+		return
+	}
+	printCommandPlan("", command, args, opts.Dir)
+	printEnvPlan("", resolvedEnv, opts.MaskedEnvKeys)
+}
+
+// printBackendsPlan prints what RunBackends would start, backend by backend.
+func printBackendsPlan(backends []Backend, observeUrl string) {
+	fmt.Println("Dry run: nothing is started.")
+	fmt.Printf("Observe URL: %s\n", observeUrl)
+	for i, backend := range backends {
+		fmt.Println()
+		if i == 0 {
+			fmt.Printf("Backend '%s' (default):\n", backend.Alias)
+		} else {
+			fmt.Printf("Backend '%s':\n", backend.Alias)
+		}
+		if len(backend.Methods) > 0 {
+			fmt.Printf("  Methods:     %s\n", strings.Join(backend.Methods, ", "))
+		}
+		printCommandPlan("  ", backend.Command, backend.Args, "")
+		printEnvPlan("  ", backend.Env, backend.MaskedEnvKeys)
+	}
+}
+
+// printCommandPlan prints a command line with each argument quoted, the executable it resolves
+// to and its working directory.
+func printCommandPlan(indent string, command string, args []string, dir string) {
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, strconv.Quote(command))
+	for _, arg := range args {
+		quoted = append(quoted, strconv.Quote(arg))
+	}
+	fmt.Printf("%sCommand:     %s\n", indent, strings.Join(quoted, " "))
+	if path, err := exec.LookPath(command); err == nil {
+		fmt.Printf("%sExecutable:  %s\n", indent, path)
+	} else {
+		fmt.Printf("%sExecutable:  not found (%v)\n", indent, err)
+	}
+	if dir != "" {
+		fmt.Printf("%sDirectory:   %s\n", indent, dir)
+	}
+}
+
+// printEnvPlan prints the environment a command would get: the wrapper's own environment with
+// the resolved profile variables applied over it, sorted by name. Values of variables whose
+// name looks like a secret, and of the masked keys (set from placeholders), are hidden.
+func printEnvPlan(indent string, resolvedEnv map[string]string, maskedKeys []string) {
+	envMap, _ := backendEnvironment(resolvedEnv)
+	masked := make(map[string]bool, len(maskedKeys))
+	for _, key := range maskedKeys {
+		masked[key] = true
+	}
+
+	names := make([]string, 0, len(envMap))
+	for name := range envMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%sEnvironment (%d variable(s), %d set by the profile):\n", indent, len(envMap), len(resolvedEnv))
+	for _, name := range names {
+		value := envMap[name]
+		if masked[name] || isSecretName(name) {
+			value = dryRunMask
+		}
+		origin := "inherited"
+		if _, fromProfile := resolvedEnv[name]; fromProfile {
+			origin = "profile"
+			if _, inherited := os.LookupEnv(name); inherited {
+				origin = "profile, overrides inherited"
+			}
+		}
+		fmt.Printf("%s  %s=%s  (%s)\n", indent, name, value, origin)
+	}
+}
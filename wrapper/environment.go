@@ -0,0 +1,82 @@
+package wrapper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// captureEnvironment controls whether a non-secret environment snapshot is recorded
+// in the session metadata. Off by default.
+var captureEnvironment bool
+
+// SetCaptureEnvironment enables or disables the environment snapshot.
+func SetCaptureEnvironment(v bool) {
+	captureEnvironment = v
+}
+
+// extraEnvAllowlistVar names additional variables (comma-separated) to include in the snapshot.
+const extraEnvAllowlistVar = "ITHENA_CAPTURE_ENV_VARS"
+
+// defaultEnvAllowlist lists the variables captured by default. Only names that describe
+// runtimes and locale belong here; values are recorded verbatim.
+var defaultEnvAllowlist = []string{
+	"NODE_VERSION", "NODE_ENV", "NVM_BIN",
+	"PYTHON_VERSION", "VIRTUAL_ENV", "CONDA_DEFAULT_ENV",
+	"GOVERSION", "JAVA_VERSION", "RUBY_VERSION",
+	"LANG", "LC_ALL", "TZ", "SHELL", "TERM",
+}
+
+// secretNameMarkers are substrings that exclude a variable even when it is allowlisted.
+var secretNameMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH", "COOKIE", "SESSION"}
+
+// buildEnvironmentSnapshot returns the OS/arch, a hash of PATH and the allowlisted
+// variables of the backend's environment. PATH itself is not recorded since it often
+// contains user names and private directories.
+func buildEnvironmentSnapshot(env map[string]string) map[string]string {
+	snapshot := map[string]string{
+		"os":   runtime.GOOS,
+		"arch": runtime.GOARCH,
+	}
+	if path, ok := env["PATH"]; ok {
+		sum := sha256.Sum256([]byte(path))
+		snapshot["path_sha256"] = hex.EncodeToString(sum[:])
+	}
+
+	for _, name := range envAllowlist() {
+		if isSecretName(name) {
+			log.Printf("Wrapper Warning: Not capturing '%s' in environment snapshot: name looks like a secret", name)
+			continue
+		}
+		if value, ok := env[name]; ok {
+			snapshot["env."+name] = value
+		}
+	}
+	return snapshot
+}
+
+// envAllowlist returns the default allowlist plus any names from ITHENA_CAPTURE_ENV_VARS.
+func envAllowlist() []string {
+	names := append([]string(nil), defaultEnvAllowlist...)
+	for _, name := range strings.Split(os.Getenv(extraEnvAllowlistVar), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretNameMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
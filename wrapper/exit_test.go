@@ -0,0 +1,56 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ithena-one/Ithena/packages/cli/localstore"
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// runWrapperEnv makes the test binary run the wrapper instead of the test, since Run exits
+// the process.
+const runWrapperEnv = "ITHENA_TEST_RUN_WRAPPER"
+
+// TestNonZeroExitIsRecorded wraps a command that exits with status 3 and checks the wrapper
+// exits with the same status and the failure record reaches the local store before it does.
+func TestNonZeroExitIsRecorded(t *testing.T) {
+	if os.Getenv(runWrapperEnv) == "1" {
+		observability.InitObservability()
+		Run("sh", []string{"-c", "exit 3"}, nil, "failing", "http://localhost", Options{})
+		return
+	}
+
+	dir := t.TempDir()
+	dbPath := dir + "/logs.db"
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNonZeroExitIsRecorded$")
+	cmd.Env = append(os.Environ(), runWrapperEnv+"=1", "HOME="+dir, "XDG_CONFIG_HOME="+dir, "ITHENA_LOG_DB="+dbPath)
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("wrapper exited with %v, want status 3; output:\n%s", err, output)
+	}
+
+	if err := localstore.InitDBReadOnly(dbPath); err != nil {
+		t.Fatalf("InitDBReadOnly: %v", err)
+	}
+	result, err := localstore.QueryLogs(localstore.LogQueryFilters{Status: types.StatusFailure}, 1, 10)
+	if err != nil {
+		t.Fatalf("QueryLogs: %v", err)
+	}
+	if len(result.Logs) != 1 {
+		t.Fatalf("stored %d failure records, want 1; output:\n%s", len(result.Logs), output)
+	}
+	record := result.Logs[0]
+	if record.TargetServerAlias == nil || *record.TargetServerAlias != "failing" {
+		t.Errorf("record alias = %v, want failing", record.TargetServerAlias)
+	}
+	if details, _ := record.ErrorDetails.(map[string]interface{}); !strings.Contains(fmt.Sprint(details["error"]), "non-zero status 3") {
+		t.Errorf("record error details = %v, want the exit status", record.ErrorDetails)
+	}
+}
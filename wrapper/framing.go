@@ -0,0 +1,128 @@
+package wrapper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Framing names accepted in Options.Framing.
+const (
+	FramingNewline       = "newline"
+	FramingContentLength = "content-length"
+)
+
+// forwardFunc copies messages from src to dst, inspecting each one after it is written along
+// with the time its first byte became readable. See forwardLines for the contract shared by all framings.
+type forwardFunc func(src io.Reader, dst io.Writer, maxBytes int, label string, inspect func(message []byte, frameBytes int, firstByteAt time.Time)) (readErr, writeErr error)
+
+// forwarderForFraming returns the forwardFunc for a framing name; "" selects newline framing.
+func forwarderForFraming(framing string) (forwardFunc, error) {
+	switch framing {
+	case "", FramingNewline:
+		return forwardLines, nil
+	case FramingContentLength:
+		return forwardFramed, nil
+	default:
+		return nil, fmt.Errorf("unknown framing '%s' (expected '%s' or '%s')", framing, FramingNewline, FramingContentLength)
+	}
+}
+
+// forwardFramed copies LSP-style messages ("Content-Length: N\r\n\r\n" followed by exactly N
+// bytes of JSON) from src to dst. Headers and bodies are forwarded byte-for-byte; inspect
+// receives only the body, along with the size of the whole frame. Bodies larger than maxBytes are streamed through without inspection.
+func forwardFramed(src io.Reader, dst io.Writer, maxBytes int, label string, inspect func(message []byte, frameBytes int, firstByteAt time.Time)) (readErr, writeErr error) {
+	reader := bufio.NewReaderSize(src, 64*1024)
+	for {
+		firstByteAt := awaitFirstByte(reader)
+		header, contentLength, err := readFrameHeader(reader)
+		if err != nil {
+			if len(header) > 0 {
+				if _, werr := dst.Write(header); werr != nil {
+					return err, werr
+				}
+			}
+			if err == io.EOF {
+				if len(header) == 0 {
+					return nil, nil
+				}
+				err = io.ErrUnexpectedEOF
+			}
+			return err, nil
+		}
+
+		if contentLength > maxBytes {
+			log.Printf("Wrapper Error: %s message exceeds %d bytes; forwarding it without recording. Raise %s to inspect larger messages.",
+				label, maxBytes, maxMessageBytesEnv)
+			if _, werr := dst.Write(header); werr != nil {
+				return nil, werr
+			}
+			if _, err := io.CopyN(dst, reader, int64(contentLength)); err != nil {
+				return err, nil
+			}
+			continue
+		}
+
+		body := make([]byte, contentLength)
+		n, err := io.ReadFull(reader, body)
+		if err != nil {
+			// Forward the partial message before reporting the failure.
+			if _, werr := dst.Write(append(header, body[:n]...)); werr != nil {
+				return err, werr
+			}
+			return err, nil
+		}
+		if _, werr := dst.Write(append(header, body...)); werr != nil {
+			return nil, werr
+		}
+		inspect(body, len(header)+len(body), firstByteAt)
+	}
+}
+
+// readFrameHeader reads one header block up to and including its blank separator line,
+// returning the raw bytes read and the Content-Length value. Blank lines before the first
+// header are tolerated and kept in the returned bytes so they are forwarded unchanged.
+func readFrameHeader(reader *bufio.Reader) ([]byte, int, error) {
+	var header []byte
+	contentLength := -1
+	sawHeader := false
+	for {
+		line, err := reader.ReadSlice('\n')
+		header = append(header, line...)
+		if err != nil {
+			if err == bufio.ErrBufferFull {
+				return header, 0, fmt.Errorf("frame header line too long")
+			}
+			return header, 0, err
+		}
+
+		trimmed := strings.TrimRight(string(line), "\r\n")
+		if trimmed == "" {
+			if !sawHeader {
+				continue
+			}
+			if contentLength < 0 {
+				return header, 0, fmt.Errorf("frame header is missing Content-Length")
+			}
+			return header, contentLength, nil
+		}
+		sawHeader = true
+
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return header, 0, fmt.Errorf("malformed frame header line %q", trimmed)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return header, 0, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			contentLength = n
+		}
+	}
+}
+
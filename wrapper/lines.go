@@ -0,0 +1,125 @@
+package wrapper
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxMessageBytesEnv overrides the largest message the wrapper will buffer for inspection.
+const maxMessageBytesEnv = "ITHENA_MAX_MESSAGE_BYTES"
+
+// defaultMaxMessageBytes comfortably fits large resources/read results and base64 images.
+const defaultMaxMessageBytes = 8 * 1024 * 1024
+
+// maxMessageBytes returns the configured per-message size limit.
+func maxMessageBytes() int {
+	value := strings.TrimSpace(os.Getenv(maxMessageBytesEnv))
+	if value == "" {
+		return defaultMaxMessageBytes
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Wrapper Warning: Ignoring invalid %s value '%s', using %d", maxMessageBytesEnv, value, defaultMaxMessageBytes)
+		return defaultMaxMessageBytes
+	}
+	return n
+}
+
+// forwardLines copies newline-delimited messages from src to dst until src is exhausted,
+// calling inspect with each message (without its line ending), its raw size as read, line
+// ending included, and the time its first byte became readable, after it has been written.
+//
+// Unlike bufio.Scanner, a message longer than maxBytes does not stop the stream: it is logged
+// and streamed through to dst intact, but not inspected. A failed read still forwards whatever
+// part of the current line was already read. readErr is nil when src simply reached EOF.
+func forwardLines(src io.Reader, dst io.Writer, maxBytes int, label string, inspect func(message []byte, frameBytes int, firstByteAt time.Time)) (readErr, writeErr error) {
+	reader := bufio.NewReaderSize(src, 64*1024)
+	var line []byte
+	var firstByteAt time.Time
+	oversized := false
+	for {
+		if len(line) == 0 && !oversized {
+			firstByteAt = awaitFirstByte(reader)
+		}
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			if oversized {
+				if _, werr := dst.Write(chunk); werr != nil {
+					return nil, werr
+				}
+			} else {
+				line = append(line, chunk...)
+				if len(line) > maxBytes {
+					log.Printf("Wrapper Error: %s message exceeds %d bytes; forwarding it without recording. Raise %s to inspect larger messages.",
+						label, maxBytes, maxMessageBytesEnv)
+					if _, werr := dst.Write(line); werr != nil {
+						return nil, werr
+					}
+					line = line[:0]
+					oversized = true
+				}
+			}
+		}
+
+		switch err {
+		case nil:
+			// chunk ended with '\n', so the current message is complete.
+			if oversized {
+				oversized = false
+				continue
+			}
+			if werr := writeMessage(dst, line, firstByteAt, inspect); werr != nil {
+				return nil, werr
+			}
+			line = line[:0]
+		case bufio.ErrBufferFull:
+			continue // Line longer than the read buffer; keep accumulating.
+		case io.EOF:
+			if oversized {
+				_, werr := dst.Write([]byte{'\n'})
+				return nil, werr
+			}
+			if len(line) > 0 {
+				return nil, writeMessage(dst, line, firstByteAt, inspect)
+			}
+			return nil, nil
+		default:
+			if len(line) > 0 {
+				if _, werr := dst.Write(line); werr != nil {
+					return err, werr
+				}
+			}
+			return err, nil
+		}
+	}
+}
+
+// writeMessage writes one complete message, normalized to end in a single '\n', then inspects it.
+// A message that is not valid UTF-8 can't be JSON-RPC, so it is written exactly as read instead.
+func writeMessage(dst io.Writer, line []byte, firstByteAt time.Time, inspect func(message []byte, frameBytes int, firstByteAt time.Time)) error {
+	frameBytes := len(line)
+	message := bytes.TrimRight(line, "\r\n")
+	if !utf8.Valid(message) {
+		if _, err := dst.Write(line); err != nil {
+			return err
+		}
+	} else if _, err := dst.Write(append(message, '\n')); err != nil {
+		return err
+	}
+	inspect(message, frameBytes, firstByteAt)
+	return nil
+}
+
+// awaitFirstByte blocks until reader has data (or fails) and returns when that happened,
+// so callers can tell when a message started arriving rather than when it was complete.
+func awaitFirstByte(reader *bufio.Reader) time.Time {
+	reader.Peek(1)
+	return time.Now()
+}
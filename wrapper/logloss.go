@@ -0,0 +1,34 @@
+package wrapper
+
+import (
+	"log"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+)
+
+// LogLossExitCode is the wrapper's exit status with --fail-on-log-loss when the wrapped
+// command succeeded but some of the session's records were dropped or not delivered.
+const LogLossExitCode = 75
+
+// failOnLogLoss is set by --fail-on-log-loss.
+var failOnLogLoss bool
+
+// SetFailOnLogLoss makes a successful session exit with LogLossExitCode if any record was lost.
+func SetFailOnLogLoss(v bool) {
+	failOnLogLoss = v
+}
+
+// exitStatusForLogLoss returns the status to exit with. A non-zero status from the wrapped
+// command is kept, so its failure isn't masked.
+func exitStatusForLogLoss(status int) int {
+	if !failOnLogLoss || status != 0 {
+		return status
+	}
+	loss := observability.SessionLogLoss()
+	if loss.Total() == 0 {
+		return status
+	}
+	log.Printf("Wrapper: Exiting with status %d because of log loss (%d dropped, %d failed upload(s), %d not stored locally).",
+		LogLossExitCode, loss.Dropped, loss.FailedUploads, loss.Unstored)
+	return LogLossExitCode
+}
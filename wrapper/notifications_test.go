@@ -0,0 +1,54 @@
+package wrapper
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// TestClientNotificationAfterShutdown sends a client notification after the backend exited
+// and the log worker shut down, as the stdin proxy may, and checks it is neither recorded
+// nor panics on the closed log channel.
+func TestClientNotificationAfterShutdown(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("ITHENA_LOG_DB", dir+"/logs.db")
+
+	var mu sync.Mutex
+	var methods []string
+	observability.SetRecordListener(func(record types.AuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		methods = append(methods, *record.McpMethod)
+	})
+	defer observability.SetRecordListener(nil)
+
+	observability.InitObservability()
+	alias := "test"
+	c := newRpcCorrelator(&alias, "http://localhost", time.Minute)
+	c.notifications = true
+
+	before := []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	c.handleClientMessage(before, len(before), time.Now())
+
+	// The backend exited: the wrapper stops the sweep and shuts observability down, while
+	// the client is still writing.
+	c.stopSweeping()
+	observability.ShutdownObservability()
+
+	after := []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	c.handleClientMessage(after, len(after), time.Now())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(methods) != 1 || methods[0] != "notifications/initialized" {
+		t.Fatalf("recorded %v, want only notifications/initialized", methods)
+	}
+	if dropped := observability.DroppedRecords(); dropped != 0 {
+		t.Errorf("DroppedRecords() = %d, want 0", dropped)
+	}
+}
@@ -0,0 +1,24 @@
+package wrapper
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// clientGoneReason is recorded for requests still pending when the client stopped reading.
+const clientGoneReason = "Client closed its end of the connection before the response was delivered"
+
+// catchBrokenPipe keeps SIGPIPE from killing the wrapper when the client closes our stdout.
+// Without a handler, the Go runtime exits on the first write to a broken stdout, losing the
+// records still queued; with one, the write fails with EPIPE and the wrapper can shut down
+// cleanly. Notify rather than Ignore: an ignored signal would stay ignored in the backend.
+func catchBrokenPipe() {
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGPIPE)
+}
+
+// isBrokenPipe reports whether err from a write to our stdout means the client went away.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
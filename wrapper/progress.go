@@ -0,0 +1,50 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// progressNotificationMethod is the MCP notification a server sends while working on a request
+// that asked for progress updates.
+const progressNotificationMethod = "notifications/progress"
+
+// requestProgressToken returns the params._meta.progressToken of a request, which MCP clients
+// set to receive progress notifications for it.
+func requestProgressToken(params interface{}) (string, bool) {
+	paramsMap, ok := params.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	meta, ok := paramsMap["_meta"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	return progressTokenString(meta["progressToken"])
+}
+
+// progressNotificationToken returns the progress token of a notifications/progress message.
+func progressNotificationToken(message []byte) (string, bool) {
+	var notification struct {
+		Method string `json:"method"`
+		Params struct {
+			ProgressToken interface{} `json:"progressToken"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &notification); err != nil || notification.Method != progressNotificationMethod {
+		return "", false
+	}
+	return progressTokenString(notification.Params.ProgressToken)
+}
+
+// progressTokenString normalizes a progress token (a string or number) for use as a map key.
+func progressTokenString(token interface{}) (string, bool) {
+	switch v := token.(type) {
+	case string:
+		return "s:" + v, true
+	case float64:
+		return fmt.Sprintf("n:%v", v), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,252 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// reportFile is where the session summary is written when the wrapper exits. Empty disables it.
+var reportFile string
+
+// SetReportFile enables the session summary report, written as JSON to path on exit.
+func SetReportFile(path string) {
+	reportFile = path
+	collectSessionStats()
+}
+
+// collectSessionStats makes the collector the record listener while the report file or the
+// exit summary needs it.
+func collectSessionStats() {
+	if reportFile == "" && quiet {
+		sessionReport = nil
+		observability.SetRecordListener(nil)
+		return
+	}
+	if sessionReport == nil {
+		sessionReport = newReportCollector()
+		observability.SetRecordListener(sessionReport.add)
+	}
+}
+
+// maxReportErrors caps the errors listed in the report; the per-status counts stay complete.
+const maxReportErrors = 100
+
+// SessionReport is the summary written to the --report-file. It is computed from the records
+// produced by this wrapper process, whether or not they were uploaded.
+type SessionReport struct {
+	SessionID     string          `json:"session_id"`
+	Alias         string          `json:"alias,omitempty"`
+	StartedAt     string          `json:"started_at"` // ISO 8601
+	EndedAt       string          `json:"ended_at"`   // ISO 8601
+	DurationMs    int64           `json:"duration_ms"`
+	ExitCode      int             `json:"exit_code"`
+	TotalCalls    int             `json:"total_calls"` // Records with an MCP method, i.e. client requests
+	ByStatus      map[string]int  `json:"by_status"`   // Calls per status
+	Tools         []ToolReport    `json:"tools"`
+	Errors        []ReportedError `json:"errors"`
+	ErrorsOmitted int             `json:"errors_omitted,omitempty"` // Errors beyond maxReportErrors
+	DroppedLogs   int64           `json:"dropped_logs"`             // Records not stored or uploaded because the log queue was full
+}
+
+// ToolReport summarizes the calls of one tool. Calls without a tool name are grouped by method.
+type ToolReport struct {
+	Name          string `json:"name"`
+	Calls         int    `json:"calls"`
+	Errors        int    `json:"errors"`
+	AvgDurationMs int64  `json:"avg_duration_ms"`
+	MaxDurationMs int64  `json:"max_duration_ms"`
+}
+
+// ReportedError is one record with a status other than success or notification.
+type ReportedError struct {
+	Timestamp string `json:"timestamp"`
+	Method    string `json:"method,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// toolStats accumulates the durations of one ToolReport.
+type toolStats struct {
+	calls, errors  int
+	timed          int
+	totalMs, maxMs int64
+}
+
+// reportCollector gathers the session's records as they are sent.
+type reportCollector struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	sessionID string
+	alias     string
+	calls     int
+	byStatus  map[string]int
+	tools     map[string]*toolStats
+	errors    []ReportedError
+	omitted   int
+}
+
+// sessionReport is the collector of the current process, set by collectSessionStats.
+var sessionReport *reportCollector
+
+func newReportCollector() *reportCollector {
+	return &reportCollector{
+		startedAt: time.Now(),
+		byStatus:  make(map[string]int),
+		tools:     make(map[string]*toolStats),
+	}
+}
+
+// add records one audit record. It is the observability record listener.
+func (c *reportCollector) add(record types.AuditRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sessionID == "" && record.Session != nil {
+		c.sessionID = record.Session.SessionID
+	}
+	if c.alias == "" && record.TargetServerAlias != nil {
+		c.alias = *record.TargetServerAlias
+	}
+
+	method := derefString(record.McpMethod)
+	tool := derefString(record.ToolName)
+	// Notifications are not calls: they have no response and can't fail.
+	if method != "" && record.Status != types.StatusNotification {
+		c.calls++
+		c.byStatus[record.Status]++
+
+		name := tool
+		if name == "" {
+			name = method
+		}
+		stats, ok := c.tools[name]
+		if !ok {
+			stats = &toolStats{}
+			c.tools[name] = stats
+		}
+		stats.calls++
+		if record.Status != types.StatusSuccess {
+			stats.errors++
+		}
+		if record.DurationMs != nil {
+			stats.timed++
+			stats.totalMs += *record.DurationMs
+			if *record.DurationMs > stats.maxMs {
+				stats.maxMs = *record.DurationMs
+			}
+		}
+	}
+
+	if record.Status == types.StatusSuccess || record.Status == types.StatusNotification {
+		return
+	}
+	if len(c.errors) >= maxReportErrors {
+		c.omitted++
+		return
+	}
+	c.errors = append(c.errors, ReportedError{
+		Timestamp: record.Timestamp,
+		Method:    method,
+		Tool:      tool,
+		Status:    record.Status,
+		Message:   errorMessage(record.ErrorDetails),
+	})
+}
+
+// build returns the report for a session ending now with exitCode.
+func (c *reportCollector) build(exitCode int) SessionReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endedAt := time.Now()
+	report := SessionReport{
+		SessionID:     c.sessionID,
+		Alias:         c.alias,
+		StartedAt:     c.startedAt.UTC().Format(time.RFC3339Nano),
+		EndedAt:       endedAt.UTC().Format(time.RFC3339Nano),
+		DurationMs:    endedAt.Sub(c.startedAt).Milliseconds(),
+		ExitCode:      exitCode,
+		TotalCalls:    c.calls,
+		ByStatus:      make(map[string]int, len(c.byStatus)),
+		Tools:         make([]ToolReport, 0, len(c.tools)),
+		Errors:        append([]ReportedError{}, c.errors...),
+		ErrorsOmitted: c.omitted,
+		DroppedLogs:   observability.DroppedRecords(),
+	}
+	for status, count := range c.byStatus {
+		report.ByStatus[status] = count
+	}
+	for name, stats := range c.tools {
+		tool := ToolReport{Name: name, Calls: stats.calls, Errors: stats.errors, MaxDurationMs: stats.maxMs}
+		if stats.timed > 0 {
+			tool.AvgDurationMs = stats.totalMs / int64(stats.timed)
+		}
+		report.Tools = append(report.Tools, tool)
+	}
+	sort.Slice(report.Tools, func(i, j int) bool { return report.Tools[i].Name < report.Tools[j].Name })
+	return report
+}
+
+// writeSessionReport writes the summary to the report file, if one was requested. Failures
+// are logged but don't change the exit code.
+func writeSessionReport(exitCode int) {
+	if reportFile == "" || sessionReport == nil {
+		return
+	}
+	data, err := json.MarshalIndent(sessionReport.build(exitCode), "", "  ")
+	if err != nil {
+		log.Printf("Wrapper Warning: Failed to encode session report: %v", err)
+		return
+	}
+	if err := os.WriteFile(reportFile, append(data, '\n'), 0644); err != nil {
+		log.Printf("Wrapper Warning: Failed to write session report to '%s': %v", reportFile, err)
+		return
+	}
+	if verbose { log.Printf("Wrapper: Wrote session report to '%s'.", reportFile) }
+}
+
+// exitWithReport writes the session report and exits with status, or LogLossExitCode (see
+// SetFailOnLogLoss). Call it only once the session's records have been sent, i.e. after
+// ShutdownObservability or SendFinalLog.
+func exitWithReport(status int) {
+	status = exitStatusForLogLoss(status)
+	printSessionSummary()
+	writeSessionReport(status)
+	os.Exit(status)
+}
+
+// errorMessage returns the most descriptive string in a record's error details.
+func errorMessage(details interface{}) string {
+	if details == nil {
+		return ""
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Sprint(details)
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(data, &fields) == nil {
+		for _, key := range []string{"error", "message"} {
+			if msg, ok := fields[key].(string); ok && msg != "" {
+				return msg
+			}
+		}
+	}
+	return string(data)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,30 @@
+package wrapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// TestReportIgnoresNotifications adds more recorded notifications than the report lists
+// errors, as --record-notifications does for a progress stream, and checks they are neither
+// counted as calls nor listed as errors, so the real failure stays in the report.
+func TestReportIgnoresNotifications(t *testing.T) {
+	c := newReportCollector()
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	progress := "notifications/progress"
+	for i := 0; i < maxReportErrors+1; i++ {
+		c.add(types.AuditRecord{Timestamp: now, McpMethod: &progress, Status: types.StatusNotification})
+	}
+	call := "tools/call"
+	c.add(types.AuditRecord{Timestamp: now, McpMethod: &call, Status: types.StatusFailure, ErrorDetails: map[string]interface{}{"message": "Boom Error"}})
+
+	report := c.build(0)
+	if report.TotalCalls != 1 {
+		t.Errorf("TotalCalls = %d, want 1", report.TotalCalls)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Status != types.StatusFailure || report.ErrorsOmitted != 0 {
+		t.Fatalf("Errors = %+v (%d omitted), want only the failure", report.Errors, report.ErrorsOmitted)
+	}
+}
@@ -0,0 +1,296 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/jsonrpc"
+)
+
+// Restart modes accepted in RestartPolicy.Mode.
+const (
+	RestartNever     = "no"
+	RestartOnFailure = "on-failure"
+)
+
+// Defaults for the zero fields of RestartPolicy.
+const (
+	defaultMaxRestarts    = 5
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// restartedReason is recorded for, and sent to the client for, requests a crashed backend
+// left unanswered.
+const restartedReason = "MCP server restarted before responding"
+
+// RestartPolicy controls whether a stdio backend that exits with a non-zero status is
+// started again instead of ending the wrapper. The zero value never restarts.
+type RestartPolicy struct {
+	Mode           string        // RestartNever ("" too) or RestartOnFailure
+	MaxRestarts    int           // 0 uses the default (5); negative restarts without limit
+	InitialBackoff time.Duration // Delay before the first restart; 0 uses the default (1s)
+	MaxBackoff     time.Duration // Cap for the delay, which doubles after each restart; 0 uses the default (30s)
+}
+
+// validate checks the mode name.
+func (p RestartPolicy) validate() error {
+	switch p.Mode {
+	case "", RestartNever, RestartOnFailure:
+		return nil
+	default:
+		return fmt.Errorf("unknown restart mode '%s' (expected '%s' or '%s')", p.Mode, RestartNever, RestartOnFailure)
+	}
+}
+
+func (p RestartPolicy) enabled() bool {
+	return p.Mode == RestartOnFailure
+}
+
+// allows reports whether another restart is permitted after restarts so far.
+func (p RestartPolicy) allows(restarts int) bool {
+	limit := p.MaxRestarts
+	if limit == 0 {
+		limit = defaultMaxRestarts
+	}
+	return limit < 0 || restarts < limit
+}
+
+// backoff returns the delay before restart number n (starting at 1).
+func (p RestartPolicy) backoff(n int) time.Duration {
+	delay, limit := p.InitialBackoff, p.MaxBackoff
+	if delay <= 0 {
+		delay = defaultInitialBackoff
+	}
+	if limit <= 0 {
+		limit = defaultMaxBackoff
+	}
+	for i := 1; i < n && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// backendInput is the writer the client's stdin is forwarded to. It writes to the current
+// backend's stdin and, while a crashed backend is being restarted, blocks until the next one
+// is attached, so messages sent in between reach the new process instead of being lost.
+type backendInput struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	pipe   io.WriteCloser
+	closed bool // The client's stdin reached EOF
+
+	// restartable makes writes to a backend that just died succeed, so forwarding survives
+	// until the restarted backend is attached, and replays the client's handshake to it.
+	restartable    bool
+	writeErrLogged bool // The failed write to the current backend was logged
+
+	attached     int      // Backends attached so far
+	handshake    [][]byte // Frames of the client's initialize request and initialized notification
+	initializeID string   // idToString of the initialize request's ID
+	replayID     string   // initializeID while the response to the replayed initialize is due
+	replayDone   bool     // That response was just dropped; see takeReplayResponse
+}
+
+func newBackendInput(restartable bool) *backendInput {
+	b := &backendInput{restartable: restartable}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// attach makes pipe the destination of further writes. If the client's stdin already ended,
+// pipe is closed right away so the backend sees EOF. A restarted backend first gets the
+// client's initialize request and initialized notification again, which the client sends
+// only once; the response to that initialize is dropped by replayFilter.
+func (b *backendInput) attach(pipe io.WriteCloser) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		pipe.Close()
+		return
+	}
+	b.attached++
+	b.writeErrLogged = false
+	if b.attached > 1 && len(b.handshake) > 0 {
+		for _, frame := range b.handshake {
+			if _, err := pipe.Write(frame); err != nil {
+				log.Printf("Wrapper Warning: Failed to replay the client's initialize handshake to the restarted backend: %v", err)
+				break
+			}
+		}
+		b.replayID = b.initializeID
+		if verbose { log.Printf("Wrapper: Replayed the client's initialize handshake (%d message(s)) to the restarted backend.", len(b.handshake)) }
+	}
+	b.pipe = pipe
+	b.cond.Broadcast()
+}
+
+// captureHandshake keeps frame if it is the client's initialize request or initialized
+// notification. Called with b.mu held.
+func (b *backendInput) captureHandshake(frame []byte) {
+	if !b.restartable || len(b.handshake) >= 2 {
+		return
+	}
+	var msg struct {
+		Method string      `json:"method"`
+		ID     interface{} `json:"id"`
+	}
+	if json.Unmarshal(frameBody(frame), &msg) != nil {
+		return
+	}
+	switch {
+	case msg.Method == "initialize" && msg.ID != nil && b.initializeID == "":
+		b.initializeID = idToString(msg.ID)
+	case msg.Method == "notifications/initialized" && msg.ID == nil:
+	default:
+		return
+	}
+	b.handshake = append(b.handshake, append([]byte(nil), frame...))
+}
+
+// isReplayResponse reports whether frame is the restarted backend's response to the replayed
+// initialize request, and stops waiting for it if so.
+func (b *backendInput) isReplayResponse(frame []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.replayID == "" {
+		return false
+	}
+	var msg struct {
+		Method string      `json:"method"`
+		ID     interface{} `json:"id"`
+	}
+	if json.Unmarshal(frameBody(frame), &msg) != nil || msg.Method != "" || msg.ID == nil || idToString(msg.ID) != b.replayID {
+		return false
+	}
+	b.replayID = ""
+	b.replayDone = true
+	return true
+}
+
+// takeReplayResponse reports whether the frame just forwarded was the dropped response to
+// the replayed initialize, so it is not correlated either.
+func (b *backendInput) takeReplayResponse() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	done := b.replayDone
+	b.replayDone = false
+	return done
+}
+
+// detach forgets the pipe of a backend that exited; writes wait for the next attach.
+func (b *backendInput) detach() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pipe = nil
+}
+
+// ended reports whether the client's stdin has reached EOF.
+func (b *backendInput) ended() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *backendInput) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	for b.pipe == nil && !b.closed {
+		b.cond.Wait()
+	}
+	if b.closed {
+		b.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	pipe := b.pipe
+	b.captureHandshake(p)
+	b.mu.Unlock()
+
+	// Written without the lock held: a full pipe may block until the backend reads.
+	n, err := pipe.Write(p)
+	if err != nil && b.restartable {
+		b.mu.Lock()
+		if !b.writeErrLogged && b.pipe == pipe {
+			b.writeErrLogged = true
+			log.Printf("Wrapper Warning: Failed to write to the backend's stdin (%v); client messages are dropped until it is restarted.", err)
+		}
+		b.mu.Unlock()
+		return len(p), nil
+	}
+	return n, err
+}
+
+// Close marks the client's stdin as ended and closes the current backend's stdin.
+func (b *backendInput) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+	if b.pipe != nil {
+		return b.pipe.Close()
+	}
+	return nil
+}
+
+// replayFilter passes backend output on to the client, except the restarted backend's
+// response to the replayed initialize request: the client got one from the first backend.
+type replayFilter struct {
+	input *backendInput
+	dst   io.Writer
+}
+
+func (f replayFilter) Write(p []byte) (int, error) {
+	if f.input.isReplayResponse(p) {
+		if verbose { log.Println("Wrapper: Dropped the restarted backend's response to the replayed initialize request.") }
+		return len(p), nil
+	}
+	return f.dst.Write(p)
+}
+
+// frameBody returns the JSON of one message as written by a forwardFunc, without its
+// Content-Length header if it has one.
+func frameBody(frame []byte) []byte {
+	if bytes.HasPrefix(bytes.ToLower(frame), []byte("content-length")) {
+		if i := bytes.Index(frame, []byte("\r\n\r\n")); i >= 0 {
+			return frame[i+4:]
+		}
+	}
+	return bytes.TrimSpace(frame)
+}
+
+// frameMessage frames a JSON message for the client according to framing.
+func frameMessage(framing string, body []byte) []byte {
+	if framing == FramingContentLength {
+		return append([]byte(fmt.Sprintf("Content-Length: %d\r\n\r\n", len(body))), body...)
+	}
+	return append(body, '\n')
+}
+
+// answerAbandoned writes a JSON-RPC error response for each request a crashed backend left
+// unanswered, so the client doesn't wait for them forever.
+func answerAbandoned(dst io.Writer, framing string, abandoned []requestInfo, reason string) {
+	for _, info := range abandoned {
+		body, err := json.Marshal(jsonrpc.Response{
+			Jsonrpc: "2.0",
+			ID:      info.id,
+			Error: &jsonrpc.Error{
+				Code:    -32603, // Internal error
+				Message: "ithena-cli: " + reason,
+			},
+		})
+		if err != nil {
+			continue
+		}
+		if _, err := dst.Write(frameMessage(framing, body)); err != nil {
+			log.Printf("Wrapper: Failed to send the client an error for abandoned request ID %v: %v", info.id, err)
+			return
+		}
+	}
+}
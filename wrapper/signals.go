@@ -0,0 +1,152 @@
+package wrapper
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownGrace is how long the wrapped command gets to exit after the wrapper
+// forwards SIGINT or SIGTERM to it, before it is killed.
+const defaultShutdownGrace = 5 * time.Second
+
+// shutdownGraceEnv overrides defaultShutdownGrace with a Go duration (e.g. "10s").
+const shutdownGraceEnv = "ITHENA_SHUTDOWN_GRACE"
+
+// shutdownGrace is the grace period set via --shutdown-grace; 0 means use the env var or default.
+var shutdownGrace time.Duration
+
+// SetShutdownGrace sets how long the wrapped command may take to exit after a forwarded
+// signal. 0 falls back to ITHENA_SHUTDOWN_GRACE, then to 5s.
+func SetShutdownGrace(d time.Duration) {
+	shutdownGrace = d
+}
+
+func shutdownGracePeriod() time.Duration {
+	if shutdownGrace > 0 {
+		return shutdownGrace
+	}
+	if v := strings.TrimSpace(os.Getenv(shutdownGraceEnv)); v != "" {
+		d, err := time.ParseDuration(v)
+		if err == nil && d >= 0 {
+			return d
+		}
+		log.Printf("Wrapper Warning: Ignoring invalid %s value '%s'", shutdownGraceEnv, v)
+	}
+	return defaultShutdownGrace
+}
+
+// signalForwarder relays SIGINT and SIGTERM received by the wrapper to the backend process,
+// so the wrapped server can clean up, and kills it if it is still running grace after the
+// first signal. It outlives individual processes, so a restarted backend is covered too.
+type signalForwarder struct {
+	sigs        chan os.Signal
+	done        chan struct{}
+	interrupted chan struct{} // Closed when the first signal arrives
+	stopReq     chan string   // Reasons to stop the backend without a signal to the wrapper, see requestStop
+	grace       time.Duration
+
+	mu        sync.Mutex
+	process   *os.Process
+	forwarded atomic.Bool
+}
+
+func newSignalForwarder(grace time.Duration) *signalForwarder {
+	f := &signalForwarder{
+		sigs:        make(chan os.Signal, 2),
+		done:        make(chan struct{}),
+		interrupted: make(chan struct{}),
+		stopReq:     make(chan string, 1),
+		grace:       grace,
+	}
+	signal.Notify(f.sigs, os.Interrupt, syscall.SIGTERM)
+	go f.run()
+	return f
+}
+
+func (f *signalForwarder) run() {
+	var killTimer <-chan time.Time
+	for {
+		var sig os.Signal
+		var reason string
+		select {
+		case sig = <-f.sigs:
+			reason = fmt.Sprintf("Received %s, forwarding it", sig)
+		case reason = <-f.stopReq:
+			sig = syscall.SIGTERM
+			reason = fmt.Sprintf("%s, sending %s", reason, sig)
+		case <-killTimer:
+			if process := f.current(); process != nil {
+				log.Printf("Wrapper: Backend did not exit within %s, killing it", f.grace)
+				if err := process.Kill(); err != nil && verbose { log.Printf("Wrapper: Failed to kill backend: %v", err) }
+			}
+			continue
+		case <-f.done:
+			return
+		}
+
+		if !f.forwarded.Swap(true) {
+			close(f.interrupted)
+		}
+		if process := f.current(); process != nil {
+			log.Printf("Wrapper: %s to the backend (PID: %d)", reason, process.Pid)
+			if err := process.Signal(sig); err != nil && verbose { log.Printf("Wrapper: Failed to forward %s: %v", sig, err) }
+		}
+		if killTimer == nil {
+			killTimer = time.After(f.grace)
+		}
+	}
+}
+
+// requestStop stops the backend as if the wrapper had received SIGTERM: it gets the signal,
+// is killed after the grace period, and is not restarted. reason is logged.
+func (f *signalForwarder) requestStop(reason string) {
+	select {
+	case f.stopReq <- reason:
+	default: // A stop is already pending.
+	}
+}
+
+func (f *signalForwarder) current() *os.Process {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.process
+}
+
+// attach makes process the target of forwarded signals; nil detaches the exited one.
+// A process attached after a signal already arrived is asked to stop right away.
+func (f *signalForwarder) attach(process *os.Process) {
+	f.mu.Lock()
+	f.process = process
+	f.mu.Unlock()
+	if process != nil && f.forwarded.Load() {
+		process.Signal(syscall.SIGTERM)
+	}
+}
+
+// wasInterrupted reports whether any signal was received.
+func (f *signalForwarder) wasInterrupted() bool {
+	return f.forwarded.Load()
+}
+
+// stop ends forwarding; signals then get their default behavior again.
+func (f *signalForwarder) stop() {
+	signal.Stop(f.sigs)
+	close(f.done)
+}
+
+// exitStatus returns the wrapper exit code for a backend that exited unsuccessfully,
+// using the shell convention 128+N when the backend was terminated by signal N.
+func exitStatus(exitErr *exec.ExitError) int {
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return exitErr.ExitCode()
+}
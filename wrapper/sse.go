@@ -0,0 +1,298 @@
+package wrapper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ithena-one/Ithena/packages/cli/jsonrpc"
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+const (
+	// sseEndpointTimeout bounds how long client messages wait for the server's "endpoint" event.
+	sseEndpointTimeout = 30 * time.Second
+	// ssePostTimeout bounds each POST of a client message to the server.
+	ssePostTimeout = 60 * time.Second
+)
+
+// sseProxy forwards newline-delimited JSON-RPC between our stdin/stdout and an MCP server
+// speaking the HTTP+SSE transport: server messages arrive as SSE "message" events, and client
+// messages are POSTed to the URL announced by the server's initial "endpoint" event.
+type sseProxy struct {
+	baseURL    *url.URL
+	correlator *rpcCorrelator
+	endpointCh chan string
+	stdoutMu   sync.Mutex
+	httpClient *http.Client
+	maxBytes   int // Messages larger than this are forwarded but not inspected
+
+	clientGone     chan struct{} // Closed when a write to our stdout finds the client gone
+	clientGoneOnce sync.Once
+}
+
+// runSSE connects to an MCP server over HTTP+SSE and proxies until either side closes.
+func runSSE(serverURL string, aliasPtr *string, observeUrl string, timeout time.Duration, streaming StreamingPolicy, notifications bool) {
+	baseURL, err := url.Parse(serverURL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		logErrorAndExit(fmt.Sprintf("Invalid SSE server URL '%s'", serverURL), aliasPtr, nil, observeUrl, nil, err)
+	}
+
+	observability.SetSessionMetadata(&types.SessionMetadata{
+		SessionID:   uuid.New().String(),
+		WrapperPID:  os.Getpid(),
+		CommandPath: serverURL,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if verbose { log.Printf("Wrapper: Connecting to SSE server %s...", serverURL) }
+	req, err := http.NewRequestWithContext(ctx, "GET", serverURL, nil)
+	if err != nil {
+		logErrorAndExit(fmt.Sprintf("Failed to create SSE request for '%s'", serverURL), aliasPtr, nil, observeUrl, nil, err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	// No client timeout: the event stream stays open for the whole session.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logErrorAndExit(fmt.Sprintf("Failed to connect to SSE server '%s'", serverURL), aliasPtr, nil, observeUrl, nil, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		logErrorAndExit(fmt.Sprintf("SSE server '%s' responded with status %s", serverURL, resp.Status), aliasPtr, nil, observeUrl, nil, nil)
+	}
+	if verbose { log.Printf("Wrapper: Connected to SSE server (Status: %s)", resp.Status) }
+
+	correlator := newRpcCorrelator(aliasPtr, observeUrl, timeout)
+	correlator.streaming = streaming
+	correlator.notifications = notifications
+	proxy := &sseProxy{
+		baseURL:    baseURL,
+		correlator: correlator,
+		endpointCh: make(chan string, 1),
+		httpClient: &http.Client{Timeout: ssePostTimeout},
+		maxBytes:   maxMessageBytes(),
+		clientGone: make(chan struct{}),
+	}
+
+	streamDone := make(chan error, 1)
+	go func() {
+		streamDone <- proxy.readEvents(resp.Body)
+	}()
+
+	stdinDone := make(chan struct{})
+	go func() {
+		defer close(stdinDone)
+		proxy.forwardClientMessages(ctx)
+	}()
+
+	select {
+	case <-stdinDone:
+		if verbose { log.Println("Wrapper: Client closed stdin, disconnecting from SSE server.") }
+		cancel()
+		resp.Body.Close()
+	case <-proxy.clientGone:
+		log.Println("Wrapper: Client closed its stdout, disconnecting from SSE server.")
+		cancel()
+		resp.Body.Close()
+		correlator.abandonPending(types.StatusCancelled, clientGoneReason)
+	case err := <-streamDone:
+		resp.Body.Close()
+		logErrorAndExit(fmt.Sprintf("SSE stream from '%s' closed unexpectedly", serverURL), aliasPtr, nil, observeUrl, nil, err)
+	}
+
+	if verbose { log.Println("Wrapper: Shutting down observability and exiting with status 0") }
+	correlator.stopSweeping()
+	observability.ShutdownObservability()
+	exitWithReport(0)
+}
+
+// readEvents parses the SSE stream and dispatches each complete event. It returns when the
+// stream ends, with the read error if there was one. Lines have no length limit, so a large
+// message is still forwarded (see writeToClient).
+func (p *sseProxy) readEvents(body io.Reader) error {
+	reader := bufio.NewReaderSize(body, 64*1024)
+
+	eventType := ""
+	var data []string
+	for {
+		raw, err := reader.ReadString('\n')
+		if err != nil {
+			// An unterminated last line is not a complete event.
+			return err
+		}
+		line := strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r")
+		if line == "" {
+			if len(data) > 0 {
+				p.dispatchEvent(eventType, strings.Join(data, "\n"))
+			}
+			eventType = ""
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // Comment / keep-alive
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+// dispatchEvent handles one SSE event from the server.
+func (p *sseProxy) dispatchEvent(eventType, data string) {
+	switch eventType {
+	case "endpoint":
+		ref, err := url.Parse(strings.TrimSpace(data))
+		if err != nil {
+			log.Printf("Wrapper: Ignoring invalid endpoint event from SSE server: %q", data)
+			return
+		}
+		endpoint := p.baseURL.ResolveReference(ref).String()
+		if verbose { log.Printf("Wrapper: SSE server announced message endpoint %s", endpoint) }
+		select {
+		case p.endpointCh <- endpoint:
+		default: // Endpoint already announced; keep the first one.
+		}
+	case "", "message":
+		p.writeToClient([]byte(data))
+	default:
+		if verbose { log.Printf("Wrapper: Ignoring SSE event of type '%s'", eventType) }
+	}
+}
+
+// writeToClient forwards one server message to our stdout and correlates it.
+func (p *sseProxy) writeToClient(message []byte) {
+	receivedAt := time.Now()
+	select {
+	case <-p.clientGone:
+		return // Shutting down; the pending requests are recorded as cancelled.
+	default:
+	}
+	p.stdoutMu.Lock()
+	_, err := os.Stdout.Write(append(message, '\n'))
+	p.stdoutMu.Unlock()
+	if isBrokenPipe(err) {
+		p.clientGoneOnce.Do(func() { close(p.clientGone) })
+		return
+	}
+	if err != nil {
+		log.Printf("Error writing to wrapper stdout: %v", err)
+	}
+	if len(message) > p.maxBytes {
+		log.Printf("Wrapper Error: Backend message exceeds %d bytes; forwarding it without recording. Raise %s to inspect larger messages.", p.maxBytes, maxMessageBytesEnv)
+		return
+	}
+	p.correlator.handleBackendMessage(message, len(message), receivedAt)
+}
+
+// forwardClientMessages POSTs each stdin line to the server's message endpoint.
+func (p *sseProxy) forwardClientMessages(ctx context.Context) {
+	var endpoint string
+	select {
+	case endpoint = <-p.endpointCh:
+	case <-time.After(sseEndpointTimeout):
+		log.Printf("Wrapper: SSE server did not announce a message endpoint within %s", sseEndpointTimeout)
+		return
+	case <-ctx.Done():
+		return
+	}
+
+	// Read whole lines without a length limit, so a message too large to inspect is still
+	// delivered rather than ending the session.
+	reader := bufio.NewReaderSize(os.Stdin, 64*1024)
+	for {
+		startTime := awaitFirstByte(reader)
+		line, readErr := reader.ReadBytes('\n')
+		lineBytes := bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(lineBytes)) > 0 {
+			if len(lineBytes) > p.maxBytes {
+				log.Printf("Wrapper Error: Client message exceeds %d bytes; forwarding it without recording. Raise %s to inspect larger messages.", p.maxBytes, maxMessageBytesEnv)
+			} else {
+				// Store the request before POSTing: the response may arrive on the event stream
+				// before the POST itself returns.
+				p.correlator.handleClientMessage(lineBytes, len(line), startTime)
+			}
+
+			if err := p.postMessage(ctx, endpoint, lineBytes); err != nil {
+				log.Printf("Wrapper: Failed to deliver message to SSE server: %v", err)
+				p.failRequest(lineBytes, err)
+			}
+		}
+		if readErr != nil {
+			if readErr != io.EOF {
+				log.Printf("Wrapper: Error reading from wrapper stdin: %v", readErr)
+			}
+			return
+		}
+	}
+}
+
+// postMessage sends one client message. Servers normally reply 202 Accepted and deliver the
+// response on the event stream; a JSON body in the reply is forwarded as a server message too.
+func (p *sseProxy) postMessage(ctx context.Context, endpoint string, message []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("server responded with status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/json" && len(bytes.TrimSpace(body)) > 0 {
+		p.writeToClient(bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// failRequest answers an undeliverable request with a JSON-RPC error so the client does
+// not wait forever, and so the failure is recorded like any other response.
+func (p *sseProxy) failRequest(message []byte, deliveryErr error) {
+	var req jsonrpc.Request
+	if err := json.Unmarshal(message, &req); err != nil || req.ID == nil {
+		return // Notifications and non-JSON lines have nobody waiting on them.
+	}
+	errorResponse, err := json.Marshal(jsonrpc.Response{
+		Jsonrpc: "2.0",
+		ID:      req.ID,
+		Error: &jsonrpc.Error{
+			Code:    -32603, // Internal error
+			Message: fmt.Sprintf("ithena-cli: failed to deliver request to SSE server: %v", deliveryErr),
+		},
+	})
+	if err != nil {
+		return
+	}
+	p.writeToClient(errorResponse)
+}
@@ -0,0 +1,142 @@
+package wrapper
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// Stderr capture modes accepted in Options.CaptureStderr.
+const (
+	CaptureStderrOff   = "off"
+	CaptureStderrTail  = "tail"  // Attach the last lines to the record of a backend crash
+	CaptureStderrLines = "lines" // Also record every line as its own failure record
+)
+
+// captureStderrEnv selects the capture mode when Options.CaptureStderr is empty, e.g. when
+// wrapping a command directly.
+const captureStderrEnv = "ITHENA_CAPTURE_STDERR"
+
+const (
+	stderrTailLines    = 20   // Lines kept for a crash record
+	stderrMaxLineBytes = 4096 // Longer lines are truncated in records (not on the terminal)
+)
+
+// captureStderrMode returns the effective capture mode for configured.
+func captureStderrMode(configured string) (string, error) {
+	mode := configured
+	if mode == "" {
+		mode = strings.TrimSpace(os.Getenv(captureStderrEnv))
+	}
+	switch mode {
+	case "", CaptureStderrOff:
+		return CaptureStderrOff, nil
+	case CaptureStderrTail, CaptureStderrLines:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown stderr capture mode '%s' (expected '%s', '%s' or '%s')", mode, CaptureStderrOff, CaptureStderrTail, CaptureStderrLines)
+	}
+}
+
+// stderrCapture is teed the backend's stderr. It remembers the last lines for crash records
+// and, in CaptureStderrLines mode, records each line as it completes.
+type stderrCapture struct {
+	mode       string
+	alias      *string
+	observeUrl string
+
+	mu      sync.Mutex
+	partial  []byte
+	skipping bool // Dropping the rest of an overlong line
+	tail     []string
+}
+
+func newStderrCapture(mode string, alias *string, observeUrl string) *stderrCapture {
+	return &stderrCapture{mode: mode, alias: alias, observeUrl: observeUrl}
+}
+
+// Write never fails, so teeing through it can't disturb the pass-through to the terminal.
+func (c *stderrCapture) Write(p []byte) (int, error) {
+	if c.mode == CaptureStderrOff {
+		return len(p), nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.partial = append(c.partial, p...)
+	for {
+		i := bytes.IndexByte(c.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if c.skipping {
+			c.skipping = false
+		} else {
+			c.addLine(c.partial[:i])
+		}
+		c.partial = c.partial[i+1:]
+	}
+	if len(c.partial) > stderrMaxLineBytes {
+		// A line this long is recorded truncated; the rest is dropped up to its newline.
+		if !c.skipping {
+			c.addLine(c.partial)
+			c.skipping = true
+		}
+		c.partial = c.partial[:0]
+	}
+	return len(p), nil
+}
+
+// flush records an unterminated last line once the backend's stderr is closed.
+func (c *stderrCapture) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.partial) > 0 && !c.skipping {
+		c.addLine(c.partial)
+	}
+	c.partial = nil
+}
+
+// addLine must be called with mu held.
+func (c *stderrCapture) addLine(raw []byte) {
+	line := strings.TrimRight(string(raw), "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(line) > stderrMaxLineBytes {
+		line = line[:stderrMaxLineBytes] + "…"
+	}
+	if len(c.tail) == stderrTailLines {
+		c.tail = c.tail[1:]
+	}
+	c.tail = append(c.tail, line)
+
+	if c.mode == CaptureStderrLines {
+		record := observability.CreateAuditRecordForError("Backend wrote to stderr", c.alias, nil, nil)
+		record.ErrorDetails = map[string]string{"stderr": line, "message": "Backend wrote to stderr"}
+		observability.SendLog(record, c.observeUrl)
+		if verbose { log.Printf("Wrapper: Recorded backend stderr line (%d bytes)", len(line)) }
+	}
+}
+
+// attachTo adds the captured tail to a crash record's error details.
+func (c *stderrCapture) attachTo(record types.AuditRecord) types.AuditRecord {
+	if c == nil || c.mode == CaptureStderrOff {
+		return record
+	}
+	c.mu.Lock()
+	tail := strings.Join(c.tail, "\n")
+	c.mu.Unlock()
+	if tail == "" {
+		return record
+	}
+	if details, ok := record.ErrorDetails.(map[string]string); ok {
+		details["stderr"] = tail
+	}
+	return record
+}
@@ -0,0 +1,103 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultStreamDonePointer locates the completion flag when StreamingPolicy.DonePointer is empty.
+const defaultStreamDonePointer = "/result/done"
+
+// StreamingPolicy describes methods whose servers answer one request with several response
+// frames sharing its ID. Such a request stays correlated until a terminal frame arrives: one
+// with an error, or whose value at DonePointer is true. The results of all frames are
+// recorded together as the call's response preview.
+type StreamingPolicy struct {
+	Methods     []string // Exact method names, or prefixes when they end in "*"; empty disables streaming
+	DonePointer string   // JSON pointer into the frame; defaults to "/result/done"
+}
+
+func (p StreamingPolicy) validate() error {
+	if p.DonePointer != "" && !strings.HasPrefix(p.DonePointer, "/") {
+		return fmt.Errorf("invalid streaming done_pointer '%s': it must be a JSON pointer starting with '/'", p.DonePointer)
+	}
+	return nil
+}
+
+// streams reports whether responses to method may arrive in several frames.
+func (p StreamingPolicy) streams(method string) bool {
+	for _, pattern := range p.Methods {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(method, prefix) {
+				return true
+			}
+		} else if method == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinal reports whether a response frame completes its request.
+func (p StreamingPolicy) isFinal(frame []byte) bool {
+	var value interface{}
+	if err := json.Unmarshal(frame, &value); err != nil {
+		return true
+	}
+	if obj, ok := value.(map[string]interface{}); ok && obj["error"] != nil {
+		return true
+	}
+	pointer := p.DonePointer
+	if pointer == "" {
+		pointer = defaultStreamDonePointer
+	}
+	done, _ := valueAtPointer(value, pointer).(bool)
+	return done
+}
+
+// valueAtPointer resolves a JSON pointer in a decoded JSON value, returning nil if any
+// step is missing.
+func valueAtPointer(value interface{}, pointer string) interface{} {
+	if pointer == "" || pointer == "/" {
+		return value
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// AppendChunk adds a non-terminal response frame to the request with id, if it is still
+// pending and its method streams under policy. It returns false when the frame should be
+// handled as a regular response instead.
+func (rs *requestStore) AppendChunk(id interface{}, policy StreamingPolicy, result interface{}, frameBytes int64, at time.Time) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	key := idToString(id)
+	info, found := rs.store[key]
+	if !found || !policy.streams(info.method) {
+		return false
+	}
+	info.chunks = append(info.chunks, result)
+	info.chunkBytes += frameBytes
+	if info.firstFrameAt.IsZero() {
+		info.firstFrameAt = at
+	}
+	rs.store[key] = info
+	return true
+}
@@ -0,0 +1,47 @@
+package wrapper
+
+import (
+	"log"
+
+	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// quiet is set by --quiet.
+var quiet bool
+
+// SetQuiet suppresses the one-line session summary printed when the wrapper exits.
+func SetQuiet(v bool) {
+	quiet = v
+	collectSessionStats()
+}
+
+// printSessionSummary prints the session's call counts, average call duration and where its
+// records went, as one line of key=value pairs on stderr. Like the report, it must run after
+// the session's records have been sent.
+func printSessionSummary() {
+	if quiet || sessionReport == nil {
+		return
+	}
+	requests, succeeded, avgMs := sessionReport.callTotals()
+	delivery := observability.SessionLogDelivery()
+	log.Printf("Wrapper: Session summary: requests=%d succeeded=%d failed=%d avg_duration_ms=%d uploaded=%d stored_locally=%d lost=%d",
+		requests, succeeded, requests-succeeded, avgMs, delivery.Uploaded, delivery.StoredLocally, observability.SessionLogLoss().Total())
+}
+
+// callTotals returns the number of calls, how many succeeded and their average duration.
+func (c *reportCollector) callTotals() (calls, succeeded int, avgMs int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var timed int
+	var totalMs int64
+	for _, stats := range c.tools {
+		timed += stats.timed
+		totalMs += stats.totalMs
+	}
+	if timed > 0 {
+		avgMs = totalMs / int64(timed)
+	}
+	return c.calls, c.byStatus[types.StatusSuccess], avgMs
+}
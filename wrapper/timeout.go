@@ -0,0 +1,75 @@
+package wrapper
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ithena-one/Ithena/packages/cli/types"
+)
+
+// defaultRequestTimeout is how long a request may wait for its response before it is
+// recorded as timed out and forgotten. Generous, since tool calls can legitimately be slow.
+const defaultRequestTimeout = 10 * time.Minute
+
+// requestTimeoutEnv overrides defaultRequestTimeout with a Go duration (e.g. "2m").
+const requestTimeoutEnv = "ITHENA_REQUEST_TIMEOUT"
+
+// Bounds for how often pending requests are checked for expiry.
+const (
+	minSweepInterval = 1 * time.Second
+	maxSweepInterval = 1 * time.Minute
+)
+
+// requestTimeout returns the effective timeout for configured; 0 falls back to
+// ITHENA_REQUEST_TIMEOUT, then to defaultRequestTimeout.
+func requestTimeout(configured time.Duration) time.Duration {
+	if configured > 0 {
+		return configured
+	}
+	if v := strings.TrimSpace(os.Getenv(requestTimeoutEnv)); v != "" {
+		d, err := time.ParseDuration(v)
+		if err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Wrapper Warning: Ignoring invalid %s value '%s', using %s", requestTimeoutEnv, v, defaultRequestTimeout)
+	}
+	return defaultRequestTimeout
+}
+
+// sweepExpired periodically records requests that got no response within timeout as
+// timed out and removes them from the store, so a backend that never answers some requests
+// doesn't grow it forever. It runs until stopSweeping is called.
+func (c *rpcCorrelator) sweepExpired(timeout time.Duration) {
+	interval := timeout / 4
+	if interval < minSweepInterval {
+		interval = minSweepInterval
+	}
+	if interval > maxSweepInterval {
+		interval = maxSweepInterval
+	}
+	reason := fmt.Sprintf("No response within %s", timeout)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopSweep:
+			return
+		case now := <-ticker.C:
+			for _, info := range c.requests.EvictOlderThan(now.Add(-timeout)) {
+				method := info.method
+				if verbose { log.Printf("Wrapper: Request (Method: %s) got no response within %s; recording it as timed out.", method, timeout) }
+				c.recordInterrupted(info, types.StatusTimeout, reason, &method)
+			}
+		}
+	}
+}
+
+// stopSweeping ends sweepExpired. Call it before shutting observability down, so no timeout
+// is recorded after the log worker stopped.
+func (c *rpcCorrelator) stopSweeping() {
+	c.stopSweepOnce.Do(func() { close(c.stopSweep) })
+}
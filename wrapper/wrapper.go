@@ -1,17 +1,19 @@
 package wrapper
 
 import (
-	"bufio"
 	// "bytes" // Unused
+	"context"
 	"encoding/json"
 	"fmt"
-	// "github.com/google/uuid" // Unused
+	"github.com/google/uuid"
 	"github.com/ithena-one/Ithena/packages/cli/jsonrpc"
 	"github.com/ithena-one/Ithena/packages/cli/observability"
+	"github.com/ithena-one/Ithena/packages/cli/types"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,8 +29,68 @@ func SetVerbose(v bool) {
 	verbose = v
 }
 
+// recordNotifications makes every wrapper record JSON-RPC notifications, as if its profile
+// set record_notifications. It is set from main.go (--record-notifications).
+var recordNotifications bool
+
+// SetRecordNotifications enables recording of notifications for every profile.
+func SetRecordNotifications(v bool) {
+	recordNotifications = v
+}
+
+// probeVersion controls whether the wrapped command is run once with --version
+// to record its version in the session metadata. Off by default since it executes the binary.
+var probeVersion bool
+
+// SetProbeVersion enables or disables version probing of the wrapped command.
+func SetProbeVersion(v bool) {
+	probeVersion = v
+}
+
+const versionProbeTimeout = 5 * time.Second
+
+// Transport names accepted in Options.Transport.
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+)
+
+// Options holds per-profile wrapper settings beyond the command, args and environment.
+// The zero value wraps the command over stdio.
+type Options struct {
+	Transport string // TransportStdio (default) spawns the command; TransportSSE connects to URL instead
+	URL       string // MCP server SSE endpoint, used when Transport is TransportSSE
+	Framing   string // FramingNewline (default) or FramingContentLength, for the stdio transport
+	Dir       string // Working directory for the spawned command; empty means the current directory
+	Restart   RestartPolicy // Whether to restart the spawned command after it exits non-zero
+
+	// RequestTimeout is how long a request may go unanswered before it is recorded as timed
+	// out and forgotten; 0 uses ITHENA_REQUEST_TIMEOUT or 10 minutes.
+	RequestTimeout time.Duration
+
+	// CaptureStderr records the spawned command's stderr as well as passing it through:
+	// CaptureStderrOff, CaptureStderrTail or CaptureStderrLines. "" uses ITHENA_CAPTURE_STDERR.
+	CaptureStderr string
+
+	// Streaming lists methods whose responses may arrive in several frames per request ID.
+	Streaming StreamingPolicy
+
+	// RecordNotifications records notifications in both directions with status "notification",
+	// instead of only logging them with --verbose. SetRecordNotifications enables it for all.
+	RecordNotifications bool
+
+	// MaskedEnvKeys lists env keys whose values the --dry-run plan hides, e.g. the ones set from
+	// placeholders. Variables whose name looks like a secret are always hidden.
+	MaskedEnvKeys []string
+}
+
+// notificationsRecorded reports whether notifications are recorded with these options.
+func (o Options) notificationsRecorded() bool {
+	return o.RecordNotifications || recordNotifications
+}
+
 // Run executes the wrapper logic based on resolved profile config.
-func Run(command string, args []string, resolvedEnv map[string]string, alias string, observeUrl string) {
+func Run(command string, args []string, resolvedEnv map[string]string, alias string, observeUrl string, opts Options) {
 	// Use profile alias if provided, otherwise default logging
 	var aliasPtr *string
 	if alias != "" {
@@ -36,155 +98,220 @@ func Run(command string, args []string, resolvedEnv map[string]string, alias str
 	} else {
 		aliasPtr = nil // Or set a default alias?
 	}
+	if dryRun {
+		printPlan(command, args, resolvedEnv, alias, observeUrl, opts)
+		return
+	}
+
+	if err := opts.Streaming.validate(); err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
+	}
+	catchBrokenPipe()
+
+	switch opts.Transport {
+	case "", TransportStdio:
+	case TransportSSE:
+		if verbose { log.Printf("Wrapper: Starting for SSE server: %s (Alias: %s, ObserveURL: %s)", opts.URL, alias, observeUrl) }
+		runSSE(opts.URL, aliasPtr, observeUrl, requestTimeout(opts.RequestTimeout), opts.Streaming, opts.notificationsRecorded())
+		return
+	default:
+		logErrorAndExit(fmt.Sprintf("Unknown wrapper transport '%s' (expected '%s' or '%s')", opts.Transport, TransportStdio, TransportSSE), aliasPtr, nil, observeUrl, nil, nil)
+	}
 
 	if verbose { log.Printf("Wrapper: Starting for command: %s %v (Alias: %s, ObserveURL: %s)", command, args, alias, observeUrl) }
 
-	cmd := exec.Command(command, args...)
+	forward, err := forwarderForFraming(opts.Framing)
+	if err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
+	}
 
-	// Set environment variables: start with current process env,
-	// then override/add with resolvedEnv from profile.
-	currentEnv := os.Environ()
-	envMap := make(map[string]string)
-	for _, envVar := range currentEnv {
-		parts := strings.SplitN(envVar, "=", 2)
-		if len(parts) == 2 {
-			envMap[parts[0]] = parts[1]
-		}
+	if err := opts.Restart.validate(); err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
 	}
-	if verbose { log.Printf("Wrapper: Initial environment contains %d variables.", len(envMap)) }
-	// Apply resolved environment variables from profile, overriding existing ones
-	for key, value := range resolvedEnv {
-		envMap[key] = value
+	captureMode, err := captureStderrMode(opts.CaptureStderr)
+	if err != nil {
+		logErrorAndExit("Invalid wrapper configuration", aliasPtr, nil, observeUrl, nil, err)
 	}
-	// Convert back to slice format required by exec.Command
-	var finalEnv []string
-	for key, value := range envMap {
-		finalEnv = append(finalEnv, key+"="+value)
+
+	envMap, finalEnv := backendEnvironment(resolvedEnv)
+
+	observability.SetSessionMetadata(buildSessionMetadata(command, envMap))
+
+	correlator := newRpcCorrelator(aliasPtr, observeUrl, requestTimeout(opts.RequestTimeout))
+	correlator.streaming = opts.Streaming
+	correlator.notifications = opts.notificationsRecorded()
+	maxBytes := maxMessageBytes()
+	input := newBackendInput(opts.Restart.enabled())
+	if verbose { log.Printf("Wrapper: Initialized request store (max message size: %d bytes).", maxBytes) }
+
+	// Goroutine 1: Proxy ithena-cli stdin -> backend stdin & Store Request Info
+	// It lives as long as the wrapper, feeding whichever backend process is current. It is not
+	// waited for: the client may keep stdin open after the backend exits (e.g. when it was
+	// stopped by a forwarded signal), and the wrapper must still exit.
+	go func() {
+		defer func() {
+			if verbose { log.Println("Wrapper: Goroutine 1 (stdin proxy) closing backend stdin pipe.") }
+			input.Close() // Close stdin when copying finishes
+		}()
+		if verbose { log.Println("Wrapper: Goroutine 1 (stdin proxy) started.") }
+		readErr, writeErr := forward(os.Stdin, input, maxBytes, "Client", func(lineBytes []byte, frameBytes int, firstByteAt time.Time) {
+			// Forwarded first, then parsed for logging/correlation. The request started when
+			// its first byte arrived, not after it was forwarded.
+			correlator.handleClientMessage(lineBytes, frameBytes, firstByteAt)
+		})
+		if writeErr != nil {
+			log.Printf("Error writing to backend stdin: %v", writeErr)
+		}
+		if readErr != nil {
+			log.Printf("Wrapper: Error reading from wrapper stdin: %v", readErr)
+		}
+		if verbose { log.Println("Wrapper: Goroutine 1 (stdin proxy) finished reading.") }
+	}()
+
+	signals := newSignalForwarder(shutdownGracePeriod())
+	restarts := 0
+	for {
+		cmd := exec.Command(command, args...)
+		cmd.Dir = opts.Dir
+		cmd.Env = finalEnv
+		stderrTee := newStderrCapture(captureMode, aliasPtr, observeUrl)
+		started, err := runBackend(cmd, input, replayFilter{input: input, dst: os.Stdout}, forward, maxBytes, correlator, signals, stderrTee)
+		if err == nil {
+			if verbose { log.Printf("Wrapper: Backend command '%s' finished successfully (status 0).", command) }
+			break
+		}
+
+		exitErr, isExit := err.(*exec.ExitError)
+		if !isExit && started {
+			// Error not related to exit code (e.g., Wait failed)
+			logErrorAndExit(fmt.Sprintf("Error waiting for backend command '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+		}
+		if !isExit && restarts == 0 {
+			logErrorAndExit(fmt.Sprintf("Failed to start command '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+		}
+		// Past here the backend either exited non-zero or, after a crash, failed to start again.
+
+		status := 1
+		errMsg := fmt.Sprintf("Failed to restart command '%s': %v", command, err)
+		if isExit {
+			status = exitStatus(exitErr)
+			errMsg = fmt.Sprintf("Backend command '%s' exited with non-zero status %d", command, status)
+		}
+		if signals.wasInterrupted() {
+			// The backend was stopped on request, so its exit status is not a failure to record.
+			if verbose { log.Printf("Wrapper: Backend command '%s' stopped by signal (status %d).", command, status) }
+			correlator.stopSweeping()
+			observability.ShutdownObservability()
+			exitWithReport(status)
+		}
+		if !opts.Restart.enabled() || input.ended() || !opts.Restart.allows(restarts) {
+			if opts.Restart.enabled() && !input.ended() {
+				errMsg = fmt.Sprintf("%s; giving up after %d restarts", errMsg, restarts)
+			}
+			log.Printf("Wrapper Error: %s", errMsg)
+			// Record the non-zero exit; returns once the record is flushed
+			correlator.stopSweeping()
+			observability.SendFinalLog(stderrTee.attachTo(observability.CreateAuditRecordForError(errMsg, aliasPtr, nil, nil)), observeUrl)
+			exitWithReport(status) // Exit wrapper with same code
+		}
+
+		restarts++
+		delay := opts.Restart.backoff(restarts)
+		errMsg = fmt.Sprintf("%s; restarting in %s (restart %d)", errMsg, delay, restarts)
+		log.Printf("Wrapper Error: %s", errMsg)
+		observability.SendLog(stderrTee.attachTo(observability.CreateAuditRecordForError(errMsg, aliasPtr, nil, nil)), observeUrl)
+		abandoned := correlator.abandonPending(types.StatusRestarted, restartedReason)
+		answerAbandoned(os.Stdout, opts.Framing, abandoned, restartedReason)
+
+		select {
+		case <-time.After(delay):
+		case <-signals.interrupted:
+			if verbose { log.Println("Wrapper: Interrupted while waiting to restart the backend.") }
+			correlator.stopSweeping()
+			observability.ShutdownObservability()
+			exitWithReport(status)
+		}
 	}
-	cmd.Env = finalEnv
-	if verbose { log.Printf("Wrapper: Final environment for backend has %d variables (profile overrides applied).", len(finalEnv)) }
+	signals.stop()
+
+	// Exit with backend's status code (0 if successful)
+	if verbose { log.Println("Wrapper: Shutting down observability and exiting with status", 0) }
+	correlator.stopSweeping()
+	observability.ShutdownObservability()
+	exitWithReport(0)
+}
 
+// runBackend starts cmd with its stdin fed from input, its stdout proxied to output and its
+// stderr to the wrapper's stderr (teed into stderrTee), then waits for the output to drain and the
+// process to exit. started reports whether the process was started; err is an
+// *exec.ExitError for a non-zero exit.
+func runBackend(cmd *exec.Cmd, input *backendInput, output io.Writer, forward forwardFunc, maxBytes int, correlator *rpcCorrelator, signals *signalForwarder, stderrTee *stderrCapture) (started bool, err error) {
 	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
-		logErrorAndExit(fmt.Sprintf("Failed to create stdin pipe for '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+		return false, fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
-		logErrorAndExit(fmt.Sprintf("Failed to create stdout pipe for '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+		return false, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
-		logErrorAndExit(fmt.Sprintf("Failed to create stderr pipe for '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+		return false, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	// Start the command
-	if verbose { log.Printf("Wrapper: Starting backend command '%s'...", command) }
+	if verbose { log.Printf("Wrapper: Starting backend command '%s'...", cmd.Path) }
 	if err := cmd.Start(); err != nil {
-		logErrorAndExit(fmt.Sprintf("Failed to start command '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+		return false, err
 	}
 	if verbose { log.Printf("Wrapper: Backend command started (PID: %d)", cmd.Process.Pid) }
+	signals.attach(cmd.Process)
+	input.attach(stdinPipe)
 
 	var wg sync.WaitGroup
-	requestStore := newRequestStore()
-	if verbose { log.Printf("Wrapper: Initialized request store and wait group.") }
-
-	// Goroutine 1: Proxy ithena-cli stdin -> backend stdin & Store Request Info
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer func() {
-			if verbose { log.Println("Wrapper: Goroutine 1 (stdin proxy) closing backend stdin pipe.") }
-			stdinPipe.Close() // Close stdin when copying finishes
-		}()
-		if verbose { log.Println("Wrapper: Goroutine 1 (stdin proxy) started.") }
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			lineBytes := scanner.Bytes()
-			startTime := time.Now() // Record start time BEFORE writing/parsing
-
-			// Write to backend stdin FIRST
-			if _, err := stdinPipe.Write(append(lineBytes, '\n')); err != nil {
-				log.Printf("Error writing to backend stdin: %v", err)
-				return // Stop proxying if write fails
-			}
-
-			// Attempt to parse for logging/correlation
-			var req jsonrpc.Request
-			if err := json.Unmarshal(lineBytes, &req); err == nil {
-				if req.ID != nil {
-					// Store request info for later correlation in the response handler
-					requestStore.Store(req.ID, req.Method, startTime, req.Params)
-					if verbose { log.Printf("Wrapper: Stored request ID %v (Method: %s)", req.ID, req.Method) }
-					// DO NOT send request log here anymore
-				} else {
-					if verbose { log.Printf("Wrapper: Received notification on stdin: Method=%s", req.Method) }
-				}
-			} else {
-				if verbose { log.Printf("Wrapper: Received non-JSON line on stdin: %s", string(lineBytes)) }
-			}
-		}
-		if scanner.Err() != nil {
-			log.Printf("Wrapper: Error reading from wrapper stdin: %v", scanner.Err())
-		}
-		if verbose { log.Println("Wrapper: Goroutine 1 (stdin proxy) finished reading.") }
-	}()
 
 	// Goroutine 2: Proxy backend stdout -> ithena-cli stdout & Log Completion
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		if verbose { log.Println("Wrapper: Goroutine 2 (stdout proxy) started.") }
-		scanner := bufio.NewScanner(stdoutPipe)
-		for scanner.Scan() {
-			lineBytes := scanner.Bytes()
-			// Write to wrapper stdout FIRST
-			if _, err := os.Stdout.Write(append(lineBytes, '\n')); err != nil {
-				log.Printf("Error writing to wrapper stdout: %v", err)
-			}
-
-			// Attempt to parse for logging
-			var resp jsonrpc.Response
-			if err := json.Unmarshal(lineBytes, &resp); err == nil {
-				if resp.ID != nil {
-					methodPtr, startTime, requestParams, found := requestStore.Retrieve(resp.ID)
-					var duration time.Duration = 0
-
-					if found {
-						duration = time.Since(startTime)
-						// Call the new function to handle consolidated logging
-						observability.RecordRpcCompletion(resp, duration, aliasPtr, methodPtr, requestParams, startTime, observeUrl)
-						if verbose { log.Printf("Wrapper: Recorded completion for ID %v (Method: %s, Duration: %s)", resp.ID, *methodPtr, duration) }
-						// DO NOT send response log here anymore
-					} else {
-						log.Printf("Wrapper: Received RPC response with unknown/duplicate ID: %v. Cannot correlate.", resp.ID)
-						// Optionally log an error record if correlation fails?
-						// observability.SendLog(observability.CreateAuditRecordForError(...), observeUrl)
-					}
-				} else {
-					if verbose { log.Printf("Wrapper: Received notification on backend stdout: %s", string(lineBytes)) }
-				}
-			} else {
-				if verbose { log.Printf("Wrapper: Received non-JSON line on backend stdout: %s", string(lineBytes)) }
+		readErr, writeErr := forward(stdoutPipe, output, maxBytes, "Backend", func(lineBytes []byte, frameBytes int, firstByteAt time.Time) {
+			// Forwarded first, then parsed for logging
+			if input.takeReplayResponse() {
+				return
 			}
+			correlator.handleBackendMessage(lineBytes, frameBytes, firstByteAt)
+		})
+		if isBrokenPipe(writeErr) {
+			// The client is gone, so nothing the backend still produces can be delivered.
+			correlator.abandonPending(types.StatusCancelled, clientGoneReason)
+			signals.requestStop("Client closed its stdout")
+		} else if writeErr != nil {
+			log.Printf("Error writing to wrapper stdout: %v", writeErr)
 		}
-		if scanner.Err() != nil {
-			log.Printf("Wrapper: Error reading from backend stdout: %v", scanner.Err())
+		if writeErr != nil {
+			// Keep draining so the backend does not block on a full pipe.
+			io.Copy(io.Discard, stdoutPipe)
+		}
+		if readErr != nil {
+			log.Printf("Wrapper: Error reading from backend stdout: %v", readErr)
 		}
 		if verbose { log.Println("Wrapper: Goroutine 2 (stdout proxy) finished reading.") }
 	}()
 
-	// Goroutine 3: Proxy backend stderr -> ithena-cli stderr
+	// Goroutine 3: Proxy backend stderr -> ithena-cli stderr, teed into stderrTee
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		if verbose { log.Println("Wrapper: Goroutine 3 (stderr proxy) started.") }
-		if _, err := io.Copy(os.Stderr, stderrPipe); err != nil {
+		if _, err := io.Copy(io.MultiWriter(os.Stderr, stderrTee), stderrPipe); err != nil {
 			log.Printf("Wrapper: Error copying backend stderr: %v", err)
 		}
+		stderrTee.flush()
 		if verbose { log.Println("Wrapper: Goroutine 3 (stderr proxy) finished copying.") }
 	}()
 
-	// Wait for all proxying goroutines to finish (indicates streams closed)
+	// Wait for the output proxying goroutines to finish (indicates the backend closed its streams)
 	if verbose { log.Println("Wrapper: Waiting for IO goroutines to complete...") }
 	wg.Wait()
 	if verbose { log.Println("Wrapper: IO goroutines finished.") }
@@ -192,31 +319,86 @@ func Run(command string, args []string, resolvedEnv map[string]string, alias str
 	// Wait for the command to exit and capture exit code
 	if verbose { log.Println("Wrapper: Waiting for backend command to exit...") }
 	err = cmd.Wait()
-	status := 0
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			status = exitErr.ExitCode()
-			errMsg := fmt.Sprintf("Backend command '%s' exited with non-zero status %d", command, status)
-			log.Printf("Wrapper Error: %s", errMsg)
-			// Log observability for non-zero exit (async)
-			observability.SendLog(observability.CreateAuditRecordForError(errMsg, aliasPtr, nil, nil), observeUrl)
-			observability.ShutdownObservability() // Ensure logs are flushed before exit
-			os.Exit(status) // Exit wrapper with same code
+	input.detach()
+	signals.attach(nil)
+	return true, err
+}
+
+// backendEnvironment returns the environment for a spawned command, as a map and in the form
+// exec.Cmd takes: the wrapper's own environment with resolvedEnv applied over it.
+func backendEnvironment(resolvedEnv map[string]string) (map[string]string, []string) {
+	// Set environment variables: start with current process env,
+	// then override/add with resolvedEnv from profile.
+	currentEnv := os.Environ()
+	envMap := make(map[string]string)
+	for _, envVar := range currentEnv {
+		parts := strings.SplitN(envVar, "=", 2)
+		if len(parts) == 2 {
+			envMap[parts[0]] = parts[1]
+		}
+	}
+	if verbose { log.Printf("Wrapper: Initial environment contains %d variables.", len(envMap)) }
+	// Apply resolved environment variables from profile, overriding existing ones
+	for key, value := range resolvedEnv {
+		envMap[key] = value
+	}
+	// Convert back to slice format required by exec.Command
+	var finalEnv []string
+	for key, value := range envMap {
+		finalEnv = append(finalEnv, key+"="+value)
+	}
+	if verbose { log.Printf("Wrapper: Final environment for backend has %d variables (profile overrides applied).", len(finalEnv)) }
+	return envMap, finalEnv
+}
+
+// buildSessionMetadata collects the process information recorded with every record of this session.
+func buildSessionMetadata(command string, env map[string]string) *types.SessionMetadata {
+	meta := &types.SessionMetadata{
+		SessionID:  uuid.New().String(),
+		WrapperPID: os.Getpid(),
+	}
+
+	if resolvedPath, err := exec.LookPath(command); err == nil {
+		if absPath, err := filepath.Abs(resolvedPath); err == nil {
+			meta.CommandPath = absPath
 		} else {
-			// Error not related to exit code (e.g., Wait failed, command not found)
-			logErrorAndExit(fmt.Sprintf("Error waiting for backend command '%s'", command), aliasPtr, nil, observeUrl, nil, err)
+			meta.CommandPath = resolvedPath
 		}
-	} else {
-		if verbose { log.Printf("Wrapper: Backend command '%s' finished successfully (status 0).", command) }
+	} else if verbose {
+		log.Printf("Wrapper: Could not resolve path of command '%s': %v", command, err)
 	}
-	// Exit with backend's status code (0 if successful)
-	if verbose { log.Println("Wrapper: Shutting down observability and exiting with status", status) }
-	observability.ShutdownObservability()
-	os.Exit(status)
+
+	if probeVersion && meta.CommandPath != "" {
+		meta.CommandVersion = probeCommandVersion(meta.CommandPath)
+	}
+	if captureEnvironment {
+		meta.Environment = buildEnvironmentSnapshot(env)
+	}
+	if verbose { log.Printf("Wrapper: Session %s (PID: %d, Command: %s, Version: %q)", meta.SessionID, meta.WrapperPID, meta.CommandPath, meta.CommandVersion) }
+	return meta
+}
+
+// probeCommandVersion runs `<path> --version` and returns the first non-empty output line.
+// Failures are logged and yield an empty string.
+func probeCommandVersion(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), versionProbeTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil && len(output) == 0 {
+		log.Printf("Wrapper: Version probe of '%s' failed: %v", path, err)
+		return ""
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
 }
 
 // logErrorAndExit logs a fatal wrapper error and exits.
-// It attempts to send an observability log and ensures shutdown before exiting.
+// It records an observability log and waits for it to be flushed before exiting.
 // The original error `origErr` is included for more context.
 func logErrorAndExit(baseMsg string, alias *string, method *string, observeUrl string, correlationID *string, origErr error) {
 	errMsg := baseMsg
@@ -224,47 +406,220 @@ func logErrorAndExit(baseMsg string, alias *string, method *string, observeUrl s
 		errMsg = fmt.Sprintf("%s: %v", baseMsg, origErr)
 	}
 	log.Printf("Fatal Wrapper Error: %s", errMsg) // Log the detailed error
-	// Attempt to log observability using the base message for brevity in observability system
-	observability.SendLog(observability.CreateAuditRecordForError(baseMsg, alias, method, correlationID), observeUrl)
-	// Ensure logs are flushed before exiting
-	observability.ShutdownObservability()
-	os.Exit(1) // Exit with status 1 for fatal wrapper errors
+	// Record the base message for brevity; SendFinalLog returns once the record is flushed
+	observability.SendFinalLog(observability.CreateAuditRecordForError(baseMsg, alias, method, correlationID), observeUrl)
+	exitWithReport(1) // Exit with status 1 for fatal wrapper errors
+}
+
+// --- Correlation of client requests with backend responses ---
+
+// rpcCorrelator tracks in-flight client requests and records an audit log when the
+// matching backend response arrives. It is shared by all transports.
+type rpcCorrelator struct {
+	requests      *requestStore
+	alias         *string
+	observeUrl    string
+	streaming     StreamingPolicy // Methods whose responses may span several frames
+	notifications bool            // Record notifications in both directions
+
+	invalidUTF8Once sync.Once // Guards the one-time warning about non-UTF-8 backend output
+
+	stopSweep     chan struct{} // Closed by stopSweeping to end sweepExpired
+	stopSweepOnce sync.Once
+}
+
+// newRpcCorrelator returns a correlator that records requests still unanswered after
+// timeout as timed out.
+func newRpcCorrelator(alias *string, observeUrl string, timeout time.Duration) *rpcCorrelator {
+	c := &rpcCorrelator{
+		requests:   newRequestStore(),
+		alias:      alias,
+		observeUrl: observeUrl,
+		stopSweep:  make(chan struct{}),
+	}
+	go c.sweepExpired(timeout)
+	return c
+}
+
+// handleClientMessage inspects a message the client sent to the backend and remembers
+// requests so their responses can be correlated. frameBytes is the message's raw size on the wire.
+func (c *rpcCorrelator) handleClientMessage(lineBytes []byte, frameBytes int, startTime time.Time) {
+	var req jsonrpc.Request
+	if err := json.Unmarshal(lineBytes, &req); err == nil {
+		if req.Method == "" {
+			// A response to a request the backend sent the client; nothing to correlate.
+			if verbose { log.Printf("Wrapper: Received response from client: ID=%v", req.ID) }
+		} else if req.ID != nil {
+			// Store request info for later correlation in the response handler
+			c.requests.Store(req.ID, req.Method, startTime, req.Params, int64(frameBytes))
+			if verbose { log.Printf("Wrapper: Stored request ID %v (Method: %s)", req.ID, req.Method) }
+		} else {
+			if verbose { log.Printf("Wrapper: Received notification from client: Method=%s", req.Method) }
+			if c.notifications {
+				observability.RecordNotification(true, c.alias, req.Method, req.Params, startTime, c.observeUrl, int64(frameBytes))
+			}
+		}
+	} else {
+		if verbose { log.Printf("Wrapper: Received non-JSON message from client: %s", string(lineBytes)) }
+	}
+}
+
+// handleBackendMessage inspects a message the backend sent to the client and records
+// the completed call if it answers a stored request. firstByteAt is when the message started
+// arriving. Call it after forwarding the message.
+func (c *rpcCorrelator) handleBackendMessage(lineBytes []byte, frameBytes int, firstByteAt time.Time) {
+	c.checkBackendUTF8(lineBytes)
+	var msg backendMessage
+	if err := json.Unmarshal(lineBytes, &msg); err == nil {
+		resp := msg.Response
+		if msg.Method != "" && resp.ID != nil {
+			// A request the backend sent the client (e.g. sampling); its ID is not one of ours.
+			if verbose { log.Printf("Wrapper: Received request from backend: ID=%v Method=%s", resp.ID, msg.Method) }
+		} else if resp.ID != nil {
+			if len(c.streaming.Methods) > 0 && !c.streaming.isFinal(lineBytes) && c.requests.AppendChunk(resp.ID, c.streaming, resp.Result, int64(frameBytes), firstByteAt) {
+				if verbose { log.Printf("Wrapper: Received stream chunk for ID %v", resp.ID) }
+				return
+			}
+			info, found := c.requests.Retrieve(resp.ID)
+			if found {
+				duration := time.Since(info.startTime)
+				firstFrameAt := info.firstFrameAt
+				if firstFrameAt.IsZero() || firstByteAt.Before(firstFrameAt) {
+					firstFrameAt = firstByteAt
+				}
+				firstByte := firstFrameAt.Sub(info.startTime)
+				if firstByte < 0 {
+					firstByte = 0
+				}
+				responseBytes := int64(frameBytes)
+				if len(info.chunks) > 0 {
+					// A streamed call: record the results of all its frames, in order
+					responseBytes += info.chunkBytes
+					if resp.Error == nil {
+						resp.Result = append(info.chunks, resp.Result)
+					}
+				}
+				method := info.method
+				if info.paused {
+					observability.SkipPausedCall()
+					if verbose { log.Printf("Wrapper: Not recording ID %v (Method: %s); capture was paused when the request arrived.", resp.ID, method) }
+					return
+				}
+				observability.RecordRpcCompletion(resp, duration, c.alias, &method, info.params, info.startTime, c.observeUrl, info.bytes, responseBytes, firstByte)
+				if verbose { log.Printf("Wrapper: Recorded completion for ID %v (Method: %s, Duration: %s, First byte: %s, Frames: %d)", resp.ID, method, duration, firstByte, len(info.chunks)+1) }
+			} else {
+				log.Printf("Wrapper: Received RPC response with unknown/duplicate ID: %v. Cannot correlate (it may have been recorded as timed out already).", resp.ID)
+			}
+		} else {
+			if token, ok := progressNotificationToken(lineBytes); ok {
+				c.requests.MarkProgress(token, firstByteAt)
+			}
+			if verbose { log.Printf("Wrapper: Received notification from backend: %s", string(lineBytes)) }
+			if c.notifications && msg.Method != "" {
+				observability.RecordNotification(false, c.alias, msg.Method, msg.Params, firstByteAt, c.observeUrl, int64(frameBytes))
+			}
+		}
+	} else {
+		if verbose { log.Printf("Wrapper: Received non-JSON message from backend: %s", string(lineBytes)) }
+	}
+}
+
+// backendMessage is a message from the backend: a response, or a request or notification
+// when Method is set.
+type backendMessage struct {
+	jsonrpc.Response
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// abandonPending records every request still awaiting a response with the given status and
+// reason, and forgets them, e.g. when the backend that received them has restarted. It
+// returns the abandoned requests.
+func (c *rpcCorrelator) abandonPending(status string, reason string) []requestInfo {
+	abandoned := c.requests.Drain()
+	for _, info := range abandoned {
+		method := info.method
+		c.recordInterrupted(info, status, reason, &method)
+	}
+	return abandoned
+}
+
+// recordInterrupted records a request that will never get its response.
+func (c *rpcCorrelator) recordInterrupted(info requestInfo, status string, reason string, method *string) {
+	if info.paused {
+		observability.SkipPausedCall()
+		return
+	}
+	observability.RecordRpcInterrupted(status, reason, c.alias, method, info.params, info.startTime, c.observeUrl, info.bytes)
 }
 
 // --- Request Store for correlating requests/responses ---
 
 type requestInfo struct {
-	method    string
-	startTime time.Time
-	params    interface{} // Store the request params
+	id            interface{} // The JSON-RPC request ID, as decoded
+	method        string
+	startTime     time.Time
+	params        interface{} // Store the request params
+	bytes         int64       // Raw size of the request message
+	progressToken string      // params._meta.progressToken, if the client asked for progress
+	firstFrameAt  time.Time   // When the first progress notification or stream chunk for the request arrived
+	chunks        []interface{} // Results of the non-terminal frames of a streamed response
+	chunkBytes    int64         // Raw size of those frames
+	paused        bool          // Capture was paused when the request arrived
 }
 
 type requestStore struct {
-	mu    sync.Mutex
-	store map[interface{}]requestInfo // Key is the JSON-RPC request ID
+	mu       sync.Mutex
+	store    map[interface{}]requestInfo // Key is the JSON-RPC request ID
+	progress map[string]string           // Progress token -> request ID key
 }
 
 func newRequestStore() *requestStore {
 	return &requestStore{
-		store: make(map[interface{}]requestInfo),
+		store:    make(map[interface{}]requestInfo),
+		progress: make(map[string]string),
 	}
 }
 
 // Store saves the request details needed for response correlation.
-func (rs *requestStore) Store(id interface{}, method string, startTime time.Time, params interface{}) {
+func (rs *requestStore) Store(id interface{}, method string, startTime time.Time, params interface{}, requestBytes int64) {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 	// Convert ID to string for reliable map key if it's a number
 	key := idToString(id)
-	rs.store[key] = requestInfo{
+	info := requestInfo{
+		id:        id,
 		method:    method,
 		startTime: startTime,
 		params:    params,
+		bytes:     requestBytes,
+		paused:    observability.CapturePaused(),
+	}
+	if token, ok := requestProgressToken(params); ok {
+		info.progressToken = token
+		rs.progress[token] = key
+	}
+	rs.store[key] = info
+}
+
+// MarkProgress notes that a progress notification arrived at for the request that asked
+// for progress with token, unless an earlier one was already seen.
+func (rs *requestStore) MarkProgress(token string, at time.Time) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	key, ok := rs.progress[token]
+	if !ok {
+		return
+	}
+	if info, found := rs.store[key]; found && info.firstFrameAt.IsZero() {
+		info.firstFrameAt = at
+		rs.store[key] = info
 	}
 }
 
 // Retrieve fetches and removes the request info using the JSON-RPC request ID.
-func (rs *requestStore) Retrieve(id interface{}) (method *string, startTime time.Time, params interface{}, found bool) {
+// Its firstFrameAt is when its first progress notification or stream chunk arrived, zero if none did.
+func (rs *requestStore) Retrieve(id interface{}) (requestInfo, bool) {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 	// Convert ID to string for lookup
@@ -272,12 +627,37 @@ func (rs *requestStore) Retrieve(id interface{}) (method *string, startTime time
 	info, found := rs.store[key]
 	if found {
 		delete(rs.store, key) // Remove after retrieval
-		// Return a pointer to the method string
-		methodCopy := info.method
-		return &methodCopy, info.startTime, info.params, true
+		delete(rs.progress, info.progressToken)
+	}
+	return info, found
+}
+
+// Drain removes and returns all stored requests.
+func (rs *requestStore) Drain() []requestInfo {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	drained := make([]requestInfo, 0, len(rs.store))
+	for _, info := range rs.store {
+		drained = append(drained, info)
+	}
+	rs.store = make(map[interface{}]requestInfo)
+	rs.progress = make(map[string]string)
+	return drained
+}
+
+// EvictOlderThan removes and returns the requests started before cutoff.
+func (rs *requestStore) EvictOlderThan(cutoff time.Time) []requestInfo {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	var evicted []requestInfo
+	for key, info := range rs.store {
+		if info.startTime.Before(cutoff) {
+			evicted = append(evicted, info)
+			delete(rs.store, key)
+			delete(rs.progress, info.progressToken)
+		}
 	}
-	// Return zero values if not found
-	return nil, time.Time{}, nil, false
+	return evicted
 }
 
 // idToString converts JSON-RPC ID (number or string) to a string for map keys.